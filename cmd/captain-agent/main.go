@@ -0,0 +1,11 @@
+package main
+
+import "log"
+
+func main() {
+	cmd := NewAgentCommand()
+
+	if err := cmd.Execute(); err != nil {
+		log.Fatalln(err)
+	}
+}
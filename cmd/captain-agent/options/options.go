@@ -0,0 +1,50 @@
+package options
+
+import (
+	"flag"
+	"strings"
+
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/klog"
+
+	"captain/pkg/simple/client/k8s"
+	"captain/pkg/tunnel"
+)
+
+type AgentRunOptions struct {
+	KubernetesOptions *k8s.KubernetesOptions
+	AgentOptions      *tunnel.AgentOptions
+}
+
+func NewAgentRunOptions() *AgentRunOptions {
+	return &AgentRunOptions{
+		KubernetesOptions: k8s.NewKubernetesOptions(),
+		AgentOptions:      tunnel.NewAgentOptions(),
+	}
+}
+
+func (s *AgentRunOptions) Flags() (fss cliflag.NamedFlagSets) {
+	s.KubernetesOptions.AddFlags(fss.FlagSet("kubernetes"), s.KubernetesOptions)
+	s.AgentOptions.AddFlags(fss.FlagSet("agent"), s.AgentOptions)
+
+	fs := fss.FlagSet("klog")
+	local := flag.NewFlagSet("klog", flag.ExitOnError)
+	klog.InitFlags(local)
+	local.VisitAll(func(fl *flag.Flag) {
+		fl.Name = strings.Replace(fl.Name, "_", "-", -1)
+		fs.AddGoFlag(fl)
+	})
+
+	return fss
+}
+
+// Validate validates agent run options, to find
+// options' misconfiguration
+func (s *AgentRunOptions) Validate() []error {
+	var errors []error
+
+	errors = append(errors, s.KubernetesOptions.Validate()...)
+	errors = append(errors, s.AgentOptions.Validate()...)
+
+	return errors
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/term"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"captain/cmd/captain-agent/options"
+	"captain/pkg/tunnel"
+	"captain/pkg/version"
+)
+
+func NewAgentCommand() *cobra.Command {
+	s := options.NewAgentRunOptions()
+
+	cmd := &cobra.Command{
+		Use: "captain-agent",
+		Long: `The Captain agent runs in member clusters which can't be reached by host cluster directly.
+It connects to cluster proxy in host cluster and serves requests for kube-apiserver and
+captain-server of the member cluster through the tunnel.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if errs := s.Validate(); len(errs) != 0 {
+				return utilerrors.NewAggregate(errs)
+			}
+			return Run(s, signals.SetupSignalHandler())
+		},
+		SilenceUsage: true,
+	}
+
+	fs := cmd.Flags()
+	namedFlagSets := s.Flags()
+	for _, f := range namedFlagSets.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	usageFmt := "Usage:\n  %s\n"
+	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
+	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n"+usageFmt, cmd.Long, cmd.UseLine())
+		cliflag.PrintSections(cmd.OutOrStdout(), namedFlagSets, cols)
+	})
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of captain-agent",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Println(version.Get())
+		},
+	}
+
+	cmd.AddCommand(versionCmd)
+
+	return cmd
+}
+
+func Run(s *options.AgentRunOptions, ctx context.Context) error {
+	config, err := clientcmd.BuildConfigFromFlags("", s.KubernetesOptions.KubeConfig)
+	if err != nil {
+		return err
+	}
+
+	agent, err := tunnel.NewAgent(s.AgentOptions, config)
+	if err != nil {
+		return err
+	}
+
+	return agent.Run(ctx)
+}
@@ -1,5 +1,11 @@
 package options
 
+import (
+	"fmt"
+
+	"captain/pkg/server/authorization"
+)
+
 // Validate validates server run options, to find
 // options' misconfiguration
 func (s *ServerRunOptions) Validate() []error {
@@ -9,6 +15,15 @@ func (s *ServerRunOptions) Validate() []error {
 
 	errors = append(errors, s.KubernetesOptions.Validate()...)
 	errors = append(errors, s.MonitoringOptions.Validate()...)
+	errors = append(errors, s.AuthenticationOptions.Validate()...)
+	errors = append(errors, s.AuthorizationOptions.Validate()...)
+	errors = append(errors, s.AuditingOptions.Validate()...)
+	errors = append(errors, s.TerminalOptions.Validate()...)
+
+	// users are resolved by authenticators, all requests look the same without authentication
+	if s.AuthorizationOptions.Mode == authorization.ModeRBAC && !s.AuthenticationOptions.Enable {
+		errors = append(errors, fmt.Errorf("authentication must be enabled in %s authorization mode", authorization.ModeRBAC))
+	}
 
 	return errors
 }
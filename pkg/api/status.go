@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// Status is the error envelope of all captain APIs. It's compatible with Status of Kubernetes, so that
+// clients of Kubernetes decode it as is, details are extended with region, cluster and resource which
+// the error is about.
+type Status struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Status is always Failure
+	Status string `json:"status" description:"always Failure"`
+	// Message is a human-readable description of the error
+	Message string `json:"message,omitempty" description:"human-readable description of the error"`
+	// Reason is a machine-readable description of the error, clients should branch on reason rather than
+	// message, e.g. NotFound, Conflict, Forbidden and ServiceUnavailable
+	Reason metav1.StatusReason `json:"reason,omitempty" description:"machine-readable description of the error, e.g. NotFound"`
+	// Details are extended data associated with the reason
+	Details *StatusDetails `json:"details,omitempty" description:"extended data associated with the reason"`
+	// Code is the HTTP status code
+	Code int32 `json:"code" description:"HTTP status code"`
+}
+
+// StatusDetails are StatusDetails of Kubernetes extended with region, cluster, namespace and resource
+type StatusDetails struct {
+	Name              string               `json:"name,omitempty" description:"name of the resource"`
+	Group             string               `json:"group,omitempty" description:"group of the resource"`
+	Kind              string               `json:"kind,omitempty" description:"kind of the resource"`
+	Causes            []metav1.StatusCause `json:"causes,omitempty" description:"causes of the error"`
+	RetryAfterSeconds int32                `json:"retryAfterSeconds,omitempty" description:"seconds to wait before retrying"`
+
+	Region    string `json:"region,omitempty" description:"region of the cluster"`
+	Cluster   string `json:"cluster,omitempty" description:"cluster of the resource"`
+	Namespace string `json:"namespace,omitempty" description:"namespace of the resource"`
+	Resource  string `json:"resource,omitempty" description:"resource type, e.g. pods"`
+}
+
+func (s *Status) Error() string {
+	return s.Message
+}
+
+// NewStatus returns Status of err responded with code. Reason and details of errors of Kubernetes
+// are kept, reasons of other errors are derived from code.
+func NewStatus(code int, err error) *Status {
+	status := &Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  err.Error(),
+		Reason:   reasonForCode(code),
+		Code:     int32(code),
+	}
+
+	var captainStatus *Status
+	var apiStatus errors.APIStatus
+	switch {
+	case goerrors.As(err, &captainStatus):
+		if captainStatus.Code == status.Code && len(captainStatus.Reason) != 0 {
+			status.Reason = captainStatus.Reason
+		}
+		if captainStatus.Details != nil {
+			details := *captainStatus.Details
+			status.Details = &details
+		}
+	case goerrors.As(err, &apiStatus):
+		s := apiStatus.Status()
+		if s.Code == status.Code && len(s.Reason) != 0 {
+			status.Reason = s.Reason
+		}
+		if s.Details != nil {
+			status.Details = &StatusDetails{
+				Name:              s.Details.Name,
+				Group:             s.Details.Group,
+				Kind:              s.Details.Kind,
+				Causes:            s.Details.Causes,
+				RetryAfterSeconds: s.Details.RetryAfterSeconds,
+			}
+		}
+	}
+	return status
+}
+
+// WithResource fills region, cluster, namespace, resource and name in details if they are not set
+func (s *Status) WithResource(region, cluster, namespace, resource, name string) *Status {
+	if len(region) == 0 && len(cluster) == 0 && len(namespace) == 0 && len(resource) == 0 && len(name) == 0 {
+		return s
+	}
+	if s.Details == nil {
+		s.Details = &StatusDetails{}
+	}
+	setIfEmpty(&s.Details.Region, region)
+	setIfEmpty(&s.Details.Cluster, cluster)
+	setIfEmpty(&s.Details.Namespace, namespace)
+	setIfEmpty(&s.Details.Resource, resource)
+	setIfEmpty(&s.Details.Name, name)
+	return s
+}
+
+func setIfEmpty(field *string, value string) {
+	if len(*field) == 0 {
+		*field = value
+	}
+}
+
+// ErrorCode returns HTTP status code of err, which is code of Status of Kubernetes and captain and
+// restful.ServiceError, or http.StatusInternalServerError for other errors
+func ErrorCode(err error) int {
+	var captainStatus *Status
+	var apiStatus errors.APIStatus
+	var serviceError restful.ServiceError
+	switch {
+	case goerrors.As(err, &captainStatus) && captainStatus.Code != 0:
+		return int(captainStatus.Code)
+	case goerrors.As(err, &apiStatus) && apiStatus.Status().Code != 0:
+		return int(apiStatus.Status().Code)
+	case goerrors.As(err, &serviceError):
+		return serviceError.Code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteStatus writes status as JSON with its code, Retry-After is set if details tell when to retry
+func WriteStatus(w http.ResponseWriter, status *Status) {
+	if status.Details != nil && status.Details.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(status.Details.RetryAfterSeconds)))
+	}
+	w.Header().Set("Content-Type", restful.MIME_JSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(int(status.Code))
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		klog.Errorf("write status %d %s failed, %v", status.Code, status.Reason, err)
+	}
+}
+
+// reasonForCode returns reason of Kubernetes corresponding to HTTP status code
+func reasonForCode(code int) metav1.StatusReason {
+	switch code {
+	case http.StatusBadRequest:
+		return metav1.StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return metav1.StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return metav1.StatusReasonForbidden
+	case http.StatusNotFound:
+		return metav1.StatusReasonNotFound
+	case http.StatusMethodNotAllowed:
+		return metav1.StatusReasonMethodNotAllowed
+	case http.StatusNotAcceptable:
+		return metav1.StatusReasonNotAcceptable
+	case http.StatusConflict:
+		return metav1.StatusReasonConflict
+	case http.StatusGone:
+		return metav1.StatusReasonGone
+	case http.StatusRequestEntityTooLarge:
+		return metav1.StatusReasonRequestEntityTooLarge
+	case http.StatusUnsupportedMediaType:
+		return metav1.StatusReasonUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return metav1.StatusReasonInvalid
+	case http.StatusTooManyRequests:
+		return metav1.StatusReasonTooManyRequests
+	case http.StatusInternalServerError:
+		return metav1.StatusReasonInternalError
+	case http.StatusServiceUnavailable:
+		return metav1.StatusReasonServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return metav1.StatusReasonTimeout
+	default:
+		return metav1.StatusReasonUnknown
+	}
+}
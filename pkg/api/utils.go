@@ -5,16 +5,11 @@ package api
 import (
 	"net/http"
 	"runtime"
-	"strings"
 
 	"github.com/emicklei/go-restful"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog"
 )
 
-// Avoid emitting errors that look like valid HTML. Quotes are okay.
-var sanitizer = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;")
-
 func HandleInternalError(response *restful.Response, req *restful.Request, err error) {
 	handle(http.StatusInternalServerError, response, req, err)
 }
@@ -45,20 +40,18 @@ func HandleConflict(response *restful.Response, req *restful.Request, err error)
 }
 
 func HandleError(response *restful.Response, req *restful.Request, err error) {
-	var statusCode int
-	switch t := err.(type) {
-	case errors.APIStatus:
-		statusCode = int(t.Status().Code)
-	case restful.ServiceError:
-		statusCode = t.Code
-	default:
-		statusCode = http.StatusInternalServerError
-	}
-	handle(statusCode, response, req, err)
+	handle(ErrorCode(err), response, req, err)
 }
 
+// handle logs err and writes it as Status with code, region, cluster, namespace, resource and name in path
+// are filled in details
 func handle(statusCode int, response *restful.Response, req *restful.Request, err error) {
 	_, fn, line, _ := runtime.Caller(2)
 	klog.Errorf("%s:%d %v", fn, line, err)
-	http.Error(response, sanitizer.Replace(err.Error()), statusCode)
+	status := NewStatus(statusCode, err)
+	if req != nil {
+		status.WithResource(req.PathParameter("region"), req.PathParameter("cluster"), req.PathParameter("namespace"),
+			req.PathParameter("resources"), req.PathParameter("name"))
+	}
+	WriteStatus(response, status)
 }
@@ -19,6 +19,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// TableColumns are default columns of clusters in table output and csv exports, the same as printer
+// columns of the CRD with region and readiness
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Region", ".metadata.labels.cluster\\.captain\\.io/region", 0),
+	alpha1.Column("Provider", ".spec.provider", 0),
+	alpha1.Column("Active", ".spec.enable", 0),
+	alpha1.Column("Ready", ".status.conditions[?(@.type==\"Ready\")].status", 0),
+	alpha1.Column("Version", ".status.kubernetesVersion", 0),
+	alpha1.Column("Federated", ".spec.joinFederation", 1),
+	alpha1.Column("Connection", ".spec.connection.type", 1),
+	alpha1.Column("Nodes", ".status.nodeCount", 1),
+	alpha1.AgeColumn,
+}
+
 type clusterProvider struct {
 	sharedInformers externalversions.SharedInformerFactory
 	client          crd.CrdInterface
@@ -46,7 +61,7 @@ func (cp clusterProvider) List(namespace string, query *query.QueryInfo) (*respo
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
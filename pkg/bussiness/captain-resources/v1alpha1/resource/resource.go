@@ -5,6 +5,7 @@ import (
 
 	"captain/pkg/bussiness/captain-resources/v1alpha1"
 	"captain/pkg/bussiness/captain-resources/v1alpha1/cluster"
+	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/crd"
 	"captain/pkg/informers"
 	"captain/pkg/unify/query"
@@ -16,6 +17,11 @@ import (
 )
 
 var (
+	// tableColumns are default columns of resources in csv exports
+	tableColumns = map[schema.GroupVersionResource][]alpha1.TableColumn{
+		ClusterGVR: cluster.TableColumns,
+	}
+
 	ClusterGVR              = schema.GroupVersionResource{Group: "captain.io", Version: "v1alpah1", Resource: "clusters"}
 	ErrResourceNotSupported = errors.New("resource is not supported")
 )
@@ -57,6 +63,16 @@ func (r *ResourceProcessor) TryResource(clusterScope bool, resource string) v1al
 	return nil
 }
 
+// TableColumns returns columns of resource, or columns of name and age if resource has no columns
+func (r *ResourceProcessor) TableColumns(resource string) []alpha1.TableColumn {
+	for gvr, columns := range tableColumns {
+		if gvr.Resource == resource {
+			return columns
+		}
+	}
+	return alpha1.DefaultTableColumns
+}
+
 func (r *ResourceProcessor) Get(resource, namespace, name string) (runtime.Object, error) {
 	clusterScope := namespace == ""
 	getter := r.TryResource(clusterScope, resource)
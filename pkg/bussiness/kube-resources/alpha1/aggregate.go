@@ -0,0 +1,179 @@
+package alpha1
+
+import (
+	"sort"
+	"strings"
+
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+const (
+	// GroupByLabelPrefix and GroupByAnnotationPrefix group by values of label or annotation, e.g. label:app
+	GroupByLabelPrefix      = "label:"
+	GroupByAnnotationPrefix = "annotation:"
+)
+
+// FieldFunc returns value of field of object to group objects by, fields are the same as filters
+// of the provider, e.g. status, false if the provider has no such field
+type FieldFunc func(runtime.Object, query.Field) (string, bool)
+
+// MatchFieldValue returns true if value of field of object equals to value of filter ignoring case.
+// Providers filter by the FieldFunc they aggregate by, so that filtering by keys of a group returns
+// exactly the objects counted in the group.
+func MatchFieldValue(value FieldFunc, object runtime.Object, filter query.Filter) bool {
+	v, ok := value(object, filter.Field)
+	return ok && strings.EqualFold(v, string(filter.Value))
+}
+
+// RequestsFunc returns resource requests of all pods of object, false if object has no pods
+type RequestsFunc func(runtime.Object) (corev1.ResourceList, bool)
+
+// Aggregator tells how objects of a resource are aggregated, both funcs are optional
+type Aggregator struct {
+	Field    FieldFunc
+	Requests RequestsFunc
+}
+
+// ObjectFieldValue returns value of field of any object, fields are namespace, name, ownerKind,
+// label:<key>, annotation:<key> or paths of scalar fields such as status.phase
+func ObjectFieldValue(object runtime.Object, field query.Field) (string, bool) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case field == query.FieldNamespace:
+		return accessor.GetNamespace(), true
+	case field == query.FieldName:
+		return accessor.GetName(), true
+	case field == query.FieldOwnerKind:
+		for _, owner := range accessor.GetOwnerReferences() {
+			return owner.Kind, true
+		}
+		return "", true
+	case strings.HasPrefix(string(field), GroupByLabelPrefix):
+		return accessor.GetLabels()[strings.TrimPrefix(string(field), GroupByLabelPrefix)], true
+	case strings.HasPrefix(string(field), GroupByAnnotationPrefix):
+		return accessor.GetAnnotations()[strings.TrimPrefix(string(field), GroupByAnnotationPrefix)], true
+	}
+
+	var fields objectFields
+	if obj, ok := object.(runtime.Unstructured); ok {
+		fields = obj.UnstructuredContent()
+	} else if content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object); err == nil {
+		fields = content
+	}
+	return fields.Get(string(field)), true
+}
+
+// Aggregate counts items by values of groupBy fields, resource requests of items are summed too if
+// sumRequests is true. Groups are sorted by count in descending order.
+func Aggregate(items []interface{}, groupBy []query.Field, aggregator Aggregator, sumRequests bool) *response.AggregateResult {
+	result := &response.AggregateResult{Groups: []response.AggregateGroup{}}
+	for _, field := range groupBy {
+		result.GroupBy = append(result.GroupBy, string(field))
+	}
+	indexes := make(map[string]int)
+	for _, item := range items {
+		object, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		result.Total++
+
+		keys := make(map[string]string, len(groupBy))
+		values := make([]string, 0, len(groupBy))
+		for _, field := range groupBy {
+			value, ok := "", false
+			if aggregator.Field != nil {
+				value, ok = aggregator.Field(object, field)
+			}
+			if !ok {
+				value, _ = ObjectFieldValue(object, field)
+			}
+			keys[string(field)] = value
+			values = append(values, value)
+		}
+
+		key := strings.Join(values, "\x00")
+		index, ok := indexes[key]
+		if !ok {
+			index = len(result.Groups)
+			indexes[key] = index
+			group := response.AggregateGroup{Keys: keys}
+			if sumRequests && aggregator.Requests != nil {
+				group.Requests = corev1.ResourceList{}
+			}
+			result.Groups = append(result.Groups, group)
+		}
+
+		group := &result.Groups[index]
+		group.Count++
+		if group.Requests != nil {
+			if requests, ok := aggregator.Requests(object); ok {
+				addResources(group.Requests, requests, corev1.ResourceCPU, corev1.ResourceMemory)
+			}
+		}
+	}
+
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Count != result.Groups[j].Count {
+			return result.Groups[i].Count > result.Groups[j].Count
+		}
+		for _, field := range groupBy {
+			if left, right := result.Groups[i].Keys[string(field)], result.Groups[j].Keys[string(field)]; left != right {
+				return left < right
+			}
+		}
+		return false
+	})
+	return result
+}
+
+func addResources(total, list corev1.ResourceList, names ...corev1.ResourceName) {
+	for _, name := range names {
+		quantity, ok := list[name]
+		if !ok {
+			continue
+		}
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
+// PodRequests returns resource requests of pod spec, which are the larger of sum of containers
+// and any init container, plus pod overhead, the same as the scheduler does
+func PodRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResources(requests, container.Resources.Requests, corev1.ResourceCPU, corev1.ResourceMemory)
+	}
+	for _, container := range spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	addResources(requests, spec.Overhead, corev1.ResourceCPU, corev1.ResourceMemory)
+	return requests
+}
+
+// ReplicasRequests returns resource requests of replicas of pod spec, multiplied in decimals which
+// never overflow
+func ReplicasRequests(spec *corev1.PodSpec, replicas int32) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for name, quantity := range PodRequests(spec) {
+		product := new(inf.Dec).Mul(quantity.AsDec(), inf.NewDec(int64(replicas), 0))
+		requests[name] = *resource.NewDecimalQuantity(*product, quantity.Format)
+	}
+	return requests
+}
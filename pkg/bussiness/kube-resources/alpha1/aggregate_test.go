@@ -0,0 +1,90 @@
+package alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+func TestAggregate(t *testing.T) {
+	newPod := func(namespace string, phase corev1.PodPhase, cpu string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: namespace, Labels: map[string]string{"app": namespace}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				}}}},
+				InitContainers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("200m"),
+				}}}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	aggregator := Aggregator{
+		Field: func(object runtime.Object, field query.Field) (string, bool) {
+			if field != query.FieldStatus {
+				return "", false
+			}
+			return string(object.(*corev1.Pod).Status.Phase), true
+		},
+		Requests: func(object runtime.Object) (corev1.ResourceList, bool) {
+			return PodRequests(&object.(*corev1.Pod).Spec), true
+		},
+	}
+
+	items := []interface{}{
+		newPod("default", corev1.PodRunning, "100m"),
+		newPod("default", corev1.PodRunning, "500m"),
+		newPod("default", corev1.PodPending, "100m"),
+		newPod("kube-system", corev1.PodRunning, "1"),
+	}
+	result := Aggregate(items, []query.Field{query.FieldStatus, "label:app"}, aggregator, true)
+	if result.Total != 4 || len(result.Groups) != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	expected := []struct {
+		status, app string
+		count       int
+		cpu, memory string
+	}{
+		{status: "Running", app: "default", count: 2, cpu: "700m", memory: "128Mi"},
+		{status: "Pending", app: "default", count: 1, cpu: "200m", memory: "64Mi"},
+		{status: "Running", app: "kube-system", count: 1, cpu: "1", memory: "64Mi"},
+	}
+	for i, group := range result.Groups {
+		e := expected[i]
+		if group.Keys["status"] != e.status || group.Keys["label:app"] != e.app || group.Count != e.count {
+			t.Errorf("expected group %+v, got %+v", e, group)
+			continue
+		}
+		if cpu, memory := group.Requests[corev1.ResourceCPU], group.Requests[corev1.ResourceMemory]; cpu.String() != e.cpu || memory.String() != e.memory {
+			t.Errorf("expected requests %s %s, got %s %s", e.cpu, e.memory, cpu.String(), memory.String())
+		}
+	}
+
+	// requests are not summed unless requested, fields are paths if not known by aggregator
+	result = Aggregate(items, []query.Field{"status.phase"}, aggregator, false)
+	if len(result.Groups) != 2 || result.Groups[0].Keys["status.phase"] != "Running" || result.Groups[0].Requests != nil {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// replicas requests are multiplied
+	requests := ReplicasRequests(&newPod("default", "", "300m").Spec, 3)
+	if cpu, memory := requests[corev1.ResourceCPU], requests[corev1.ResourceMemory]; cpu.String() != "900m" || memory.String() != "192Mi" {
+		t.Errorf("unexpected replicas requests %s %s", cpu.String(), memory.String())
+	}
+
+	// replicas requests don't overflow even if their milli values do
+	requests = ReplicasRequests(&newPod("default", "", "5P").Spec, 3)
+	if cpu := requests[corev1.ResourceCPU]; cpu.String() != "15P" {
+		t.Errorf("unexpected replicas requests %s", cpu.String())
+	}
+}
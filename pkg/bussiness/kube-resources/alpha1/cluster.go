@@ -0,0 +1,54 @@
+package alpha1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clusterclient"
+)
+
+// ClusterLister returns lister of resource in member cluster, which reads from informer cache of the
+// cluster. ok is false until the informer has synced, providers read from apiserver of the cluster
+// meanwhile. Objects listed are shared with the informer, they must be copied before mutated.
+func ClusterLister(clients clusterclient.ClusterInformers, region, cluster string, gvr schema.GroupVersionResource) (lister cache.GenericLister, ok bool) {
+	factory, err := clients.InformerFactory(region, cluster)
+	if err != nil {
+		return nil, false
+	}
+	informer, err := factory.ForResource(gvr)
+	if err != nil {
+		return nil, false
+	}
+	// resources are named as kubectl does, e.g. cronjobs.v1beta1.batch, versions of a resource are
+	// cached separately
+	resource := strings.TrimSuffix(strings.Join([]string{gvr.Resource, gvr.Version, gvr.Group}, "."), ".")
+	return informer.Lister(), clients.CacheSynced(region, cluster, resource, informer.Informer())
+}
+
+// ListFromCluster lists resources in namespace, or in all namespaces if it's empty, from informer cache
+// of member cluster by DefaultList. ok is false until the informer has synced, providers list from
+// apiserver of the cluster meanwhile.
+func ListFromCluster(clients clusterclient.ClusterInformers, region, cluster string, gvr schema.GroupVersionResource, namespace string,
+	q *query.QueryInfo, compare CompareFunc, filter FilterFunc, transforms ...TransformFunc) (result *response.ListResult, ok bool, err error) {
+	lister, ok := ClusterLister(clients, region, cluster, gvr)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var objects []runtime.Object
+	if len(namespace) == 0 {
+		objects, err = lister.List(q.GetSelector())
+	} else {
+		objects, err = lister.ByNamespace(namespace).List(q.GetSelector())
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	result, err = DefaultList(objects, q, compare, filter, transforms...)
+	return result, true, err
+}
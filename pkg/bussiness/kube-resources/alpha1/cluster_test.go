@@ -0,0 +1,98 @@
+package alpha1
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/utils/clusterclient"
+)
+
+// fakeClusterInformers serves informers of a single cluster
+type fakeClusterInformers struct {
+	factory   informers.SharedInformerFactory
+	stopCh    chan struct{}
+	resources []string
+}
+
+func (f *fakeClusterInformers) InformerFactory(region, cluster string) (informers.SharedInformerFactory, error) {
+	return f.factory, nil
+}
+
+func (f *fakeClusterInformers) CacheSynced(region, cluster, resource string, informer cache.SharedIndexInformer) bool {
+	f.resources = append(f.resources, resource)
+	f.factory.Start(f.stopCh)
+	return informer.HasSynced()
+}
+
+func (f *fakeClusterInformers) CacheStatus() []clusterclient.ClusterCacheStatus {
+	return nil
+}
+
+func TestClusterLister(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	clients := &fakeClusterInformers{factory: informers.NewSharedInformerFactory(client, 0), stopCh: make(chan struct{})}
+	defer close(clients.stopCh)
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	var lister cache.GenericLister
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		var ok bool
+		lister, ok = ClusterLister(clients, "wx", "c1", gvr)
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatal("expected informer synced")
+	}
+	if clients.resources[0] != "pods.v1" {
+		t.Errorf("unexpected resource %s", clients.resources[0])
+	}
+	objects, err := lister.ByNamespace("default").List(labels.Everything())
+	if err != nil || len(objects) != 1 || objects[0].(*corev1.Pod).Name != "web" {
+		t.Errorf("unexpected objects %v, %v", objects, err)
+	}
+
+	// versions of a resource are cached separately
+	ClusterLister(clients, "wx", "c1", schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"})
+	if resource := clients.resources[len(clients.resources)-1]; resource != "cronjobs.v1beta1.batch" {
+		t.Errorf("unexpected resource %s", resource)
+	}
+}
+
+func TestListFromCluster(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"}},
+	)
+	clients := &fakeClusterInformers{factory: informers.NewSharedInformerFactory(client, 0), stopCh: make(chan struct{})}
+	defer close(clients.stopCh)
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	compare := func(runtime.Object, runtime.Object, query.Field) bool { return false }
+	filter := func(runtime.Object, query.Filter) bool { return true }
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, ok, err := ListFromCluster(clients, "wx", "c1", gvr, "default", query.New(), compare, filter)
+		return ok, err
+	})
+	if err != nil {
+		t.Fatal("expected informer synced")
+	}
+
+	// resources are listed in namespace, or in all namespaces if it's empty
+	for namespace, total := range map[string]int{"default": 1, "": 2} {
+		result, _, err := ListFromCluster(clients, "wx", "c1", gvr, namespace, query.New(), compare, filter)
+		if err != nil || result.Total != total {
+			t.Errorf("expected %d pods listed in namespace %q, got %+v, %v", total, namespace, result, err)
+		}
+	}
+}
@@ -8,8 +8,15 @@ import (
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of cluster roles in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type clusterRoleProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -34,7 +41,7 @@ func (cr clusterRoleProvider) List(namespace string, query *query.QueryInfo) (*r
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -73,3 +80,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		return alpha1.DefaultObjectMetaCompare(leftNS.ObjectMeta, rightNS.ObjectMeta, field)
 	}
 }
+
+func (cr clusterRoleProvider) Informer() cache.SharedIndexInformer {
+	return cr.informers.Rbac().V1().ClusterRoles().Informer()
+}
+
+func (cr clusterRoleProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
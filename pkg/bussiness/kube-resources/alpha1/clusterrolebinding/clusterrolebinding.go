@@ -7,8 +7,16 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of cluster role bindings in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Role", ".roleRef.name", 0),
+	alpha1.AgeColumn,
+}
+
 type clusterRoleBingdingProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -33,7 +41,7 @@ func (cr clusterRoleBingdingProvider) List(namespace string, query *query.QueryI
 		result = append(result, roleBinding)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -60,3 +68,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftRoleBinding.ObjectMeta, rightRoleBinding.ObjectMeta, field)
 }
+
+func (cr clusterRoleBingdingProvider) Informer() cache.SharedIndexInformer {
+	return cr.informers.Rbac().V1().ClusterRoleBindings().Informer()
+}
+
+func (cr clusterRoleBingdingProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
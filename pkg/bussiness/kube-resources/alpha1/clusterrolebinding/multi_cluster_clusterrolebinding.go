@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcClusterroleBindingProvider struct {
 	clusterclient.ClusterClients
 }
 
+var clusterrolebindingsGVR = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcClusterroleBindingProvider {
 	return mcClusterroleBindingProvider{ClusterClients: clients}
 }
 
 func (pd mcClusterroleBindingProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, clusterrolebindingsGVR); ok {
+		return lister.Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcClusterroleBindingProvider) Get(region, cluster, namespace, name stri
 }
 
 func (pd mcClusterroleBindingProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, clusterrolebindingsGVR, "", query, compareFunc, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcClusterroleBindingProvider) List(region, cluster, namespace string, q
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
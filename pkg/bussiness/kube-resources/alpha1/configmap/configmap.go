@@ -7,8 +7,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of config maps in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type configmapProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -32,7 +39,7 @@ func (cm configmapProvider) List(namespace string, query *query.QueryInfo) (*res
 		result = append(result, configMap)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -58,3 +65,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftCM.ObjectMeta, rightCM.ObjectMeta, field)
 }
+
+func (cm configmapProvider) Informer() cache.SharedIndexInformer {
+	return cm.sharedInformers.Core().V1().ConfigMaps().Informer()
+}
+
+func (cm configmapProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
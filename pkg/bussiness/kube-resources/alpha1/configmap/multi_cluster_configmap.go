@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcConfigmapProvider struct {
 	clusterclient.ClusterClients
 }
 
+var configmapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcConfigmapProvider {
 	return mcConfigmapProvider{ClusterClients: clients}
 }
 
 func (pd mcConfigmapProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, configmapsGVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcConfigmapProvider) Get(region, cluster, namespace, name string) (runt
 }
 
 func (pd mcConfigmapProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, configmapsGVR, namespace, query, compareFunc, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcConfigmapProvider) List(region, cluster, namespace string, query *que
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
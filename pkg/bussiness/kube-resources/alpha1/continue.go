@@ -0,0 +1,176 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	"captain/pkg/unify/query"
+)
+
+// lessFunc returns true if left is before right in list
+type lessFunc func(left, right runtime.Object) bool
+
+// newLessFunc orders objects by compareFunc, ties are broken by namespace, name and uid, so that
+// the order is the same across requests and continue tokens can be located in it
+func newLessFunc(compareFunc CompareFunc, sortBy query.Field, ascending bool) lessFunc {
+	return func(left, right runtime.Object) bool {
+		lt, gt := compareFunc(left, right, sortBy), compareFunc(right, left, sortBy)
+		if lt != gt {
+			if ascending {
+				return lt
+			}
+			return gt
+		}
+		return identity(left) < identity(right)
+	}
+}
+
+func identity(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetNamespace() + "/" + accessor.GetName() + "/" + string(accessor.GetUID())
+}
+
+// sortKeyPaths are JSONPaths of sort keys of metadata, values of sort keys kept in continue tokens
+// are evaluated by JSONPaths. Objects created at the same time are ordered by names.
+var sortKeyPaths = map[query.Field][]string{
+	query.FieldName:              {"{.metadata.name}"},
+	query.FieldCreationTimeStamp: {"{.metadata.creationTimestamp}", "{.metadata.name}"},
+	query.FieldCreateTime:        {"{.metadata.creationTimestamp}", "{.metadata.name}"},
+}
+
+// cursorSortKey returns sort key of objects kept in continue tokens, which has no paths if objects
+// are sorted by keys computed by providers
+func cursorSortKey(sortBy query.Field, objects []runtime.Object) *sortKey {
+	paths := sortKeyPaths[sortBy]
+	if sortBy.IsJSONPath() {
+		paths = []string{sortBy.JSONPathTemplate()}
+	}
+	key, err := newSortKey(paths, objects)
+	if err != nil {
+		// invalid JSONPaths aren't sorted by either
+		key, _ = newSortKey(nil, objects)
+	}
+	return key
+}
+
+// cursorPage returns a page of sorted objects starting from continue token of query, and token
+// of the next page, which is empty if there are no more objects
+func cursorPage(objects []runtime.Object, q *query.QueryInfo, resourceVersion string) ([]runtime.Object, string, error) {
+	key := cursorSortKey(q.SortBy, objects)
+	begin := 0
+	if q.Continue != nil {
+		var err error
+		if begin, err = resumeIndex(objects, q.Continue, key, q.Ascending); err != nil {
+			return nil, "", err
+		}
+	}
+	end := begin + cursorLimit(q)
+	if end >= len(objects) {
+		return objects[begin:], "", nil
+	}
+
+	token := &query.ContinueToken{
+		ResourceVersion: resourceVersion,
+		Issued:          time.Now().Unix(),
+		SortBy:          q.SortBy,
+		Ascending:       q.Ascending,
+	}
+	last := objects[end-1]
+	for _, value := range key.valuesOf(last) {
+		data, _ := json.Marshal(value)
+		token.Values = append(token.Values, data)
+	}
+	if accessor, err := meta.Accessor(last); err == nil {
+		token.Namespace, token.Name, token.UID = accessor.GetNamespace(), accessor.GetName(), string(accessor.GetUID())
+	}
+	return objects[begin:end], token.Encode(), nil
+}
+
+func cursorLimit(q *query.QueryInfo) int {
+	if q.Limit > 0 {
+		return q.Limit
+	}
+	return query.DefaultPagination.PageSize
+}
+
+// resumeIndex returns index of the first object after the last seen object of token. The last seen
+// object is resumed after where it is if it hasn't changed since the token was issued, which is
+// told by resource versions. Otherwise it is placed in the list by values of sort keys in token,
+// ties are broken by namespace, name and uid, so objects created, changed or deleted since the
+// last page don't shift the next one. Objects sorted by keys computed by providers can't be placed
+// by values, the token is expired then.
+func resumeIndex(objects []runtime.Object, token *query.ContinueToken, key *sortKey, ascending bool) (int, error) {
+	issued, err := parseResourceVersion(token.ResourceVersion)
+	if err != nil {
+		return 0, apierrors.NewBadRequest("malformed continue token")
+	}
+	if len(token.Values) != len(key.parsers) {
+		return 0, apierrors.NewBadRequest("continue token was issued for another list")
+	}
+	values := make([]interface{}, len(token.Values))
+	for i, data := range token.Values {
+		// numbers are decoded as int64 or float64 the same as unstructured content
+		if err := utiljson.Unmarshal(data, &values[i]); err != nil {
+			return 0, apierrors.NewBadRequest("malformed continue token")
+		}
+	}
+	last := token.Namespace + "/" + token.Name + "/" + token.UID
+	for i, obj := range objects {
+		if identity(obj) != last {
+			continue
+		}
+		if accessor, err := meta.Accessor(obj); err == nil {
+			if version, err := parseResourceVersion(accessor.GetResourceVersion()); err == nil && version <= issued {
+				return i + 1, nil
+			}
+		}
+		break
+	}
+
+	if len(token.Values) == 0 {
+		return 0, apierrors.NewResourceExpired("the last seen object is changed or deleted, list from the first page again")
+	}
+	return sort.Search(len(objects), func(i int) bool {
+		if c := key.compare(values, key.valuesOf(objects[i])); c != 0 {
+			return (c < 0) == ascending
+		}
+		return last < identity(objects[i])
+	}), nil
+}
+
+// parseResourceVersion parses resource version as a number, empty resource versions are 0
+func parseResourceVersion(resourceVersion string) (uint64, error) {
+	if len(resourceVersion) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(resourceVersion, 10, 64)
+}
+
+// newestResourceVersion returns the largest resource version of objects, resource versions are
+// compared as numbers as etcd revisions are
+func newestResourceVersion(objects []runtime.Object) string {
+	var newest uint64
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if version, err := strconv.ParseUint(accessor.GetResourceVersion(), 10, 64); err == nil && version > newest {
+			newest = version
+		}
+	}
+	if newest == 0 {
+		return ""
+	}
+	return strconv.FormatUint(newest, 10)
+}
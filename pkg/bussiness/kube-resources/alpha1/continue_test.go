@@ -0,0 +1,140 @@
+package alpha1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+func TestCursorList(t *testing.T) {
+	now := time.Now()
+	newPod := func(i int) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("pod-%d", i),
+			Namespace:         "default",
+			UID:               types.UID(fmt.Sprint(i)),
+			ResourceVersion:   fmt.Sprint(100 + i),
+			CreationTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Minute)),
+		}}
+	}
+	compare := func(left, right runtime.Object, field query.Field) bool {
+		return DefaultObjectMetaCompare(left.(*corev1.Pod).ObjectMeta, right.(*corev1.Pod).ObjectMeta, field)
+	}
+	filter := func(runtime.Object, query.Filter) bool { return true }
+
+	// pods are listed from the newest
+	var objects []runtime.Object
+	for i := 0; i < 6; i++ {
+		objects = append(objects, newPod(i))
+	}
+	q := query.New()
+	q.SortBy = query.FieldCreationTimeStamp
+	q.Limit = 2
+
+	names := func(result []interface{}) []string {
+		var names []string
+		for _, item := range result {
+			names = append(names, item.(*corev1.Pod).Name)
+		}
+		return names
+	}
+	expect := func(result []interface{}, expected ...string) {
+		t.Helper()
+		if got := names(result); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+	list := func() *response.ListResult {
+		t.Helper()
+		result, err := DefaultList(objects, q, compare, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := list()
+	expect(result.Items, "pod-5", "pod-4")
+	if result.Total != 6 || len(result.Continue) == 0 || result.ResourceVersion != "105" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// a newer pod and deleting a listed one shift pages but not cursors
+	objects = append(objects[1:], newPod(6))
+	token, err := query.DecodeContinueToken(result.Continue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Continue = token
+	result = list()
+	expect(result.Items, "pod-3", "pod-2")
+
+	// the last seen pod is deleted, next page starts after where it was
+	objects = []runtime.Object{newPod(1), newPod(3)}
+	q.Continue, _ = query.DecodeContinueToken(result.Continue)
+	result = list()
+	expect(result.Items, "pod-1")
+	if len(result.Continue) != 0 {
+		t.Fatalf("expected no more pages, got continue %s", result.Continue)
+	}
+
+	// pods sorted by other keys are resumed by the sort key the last seen pod had when listed
+	objects = nil
+	for i := 0; i < 4; i++ {
+		pod := newPod(i)
+		pod.Labels = map[string]string{"rank": fmt.Sprint(i)}
+		objects = append(objects, pod)
+	}
+	q = query.New()
+	q.SortBy = "{.metadata.labels.rank}"
+	q.Ascending = true
+	q.Limit = 2
+	result = list()
+	expect(result.Items, "pod-0", "pod-1")
+	objects[1].(*corev1.Pod).Labels["rank"] = "9"
+	objects[1].(*corev1.Pod).ResourceVersion = "200"
+	q.Continue, _ = query.DecodeContinueToken(result.Continue)
+	result = list()
+	expect(result.Items, "pod-2", "pod-3")
+
+	// tokens carry sort keys and identity of the last seen pod but not the pod
+	q.Continue = nil
+	token, _ = query.DecodeContinueToken(list().Continue)
+	if len(token.Values) != 1 || string(token.Values[0]) != `"2"` || token.Name != "pod-2" || token.UID != "2" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+
+	// tokens of other lists are rejected instead of listing from the first page
+	malformed := *token
+	malformed.Values = append(malformed.Values, malformed.Values[0])
+	q.Continue = &malformed
+	if _, err := DefaultList(objects, q, compare, filter); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of token with more sort keys, got %v", err)
+	}
+	malformed.Values, malformed.ResourceVersion = malformed.Values[:1], "latest"
+	if _, err := DefaultList(objects, q, compare, filter); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of token with malformed resource version, got %v", err)
+	}
+
+	// pods sorted by keys computed by providers can't be placed once the last seen pod is gone
+	q = query.New()
+	q.SortBy = query.FieldStatus
+	q.Limit = 2
+	q.Continue, _ = query.DecodeContinueToken(list().Continue)
+	if len(q.Continue.Values) != 0 {
+		t.Fatalf("expected no sort keys in token, got %+v", q.Continue)
+	}
+	objects = objects[:2]
+	if _, err := DefaultList(objects, q, compare, filter); !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected expired token, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+	"captain/pkg/unify/response"
+)
+
+// cronJob is a cron job of batch/v1 or batch/v1beta1, whichever is served by the cluster
+type cronJob struct {
+	metav1.Object
+	gvk      schema.GroupVersionKind
+	template batchv1.JobTemplateSpec
+}
+
+// getCronJob gets cron job of batch/v1 if it's served, or batch/v1beta1 otherwise
+func getCronJob(ctx context.Context, client kubernetes.Interface, namespace, name string) (*cronJob, error) {
+	v1, err := servesV1(client)
+	if err != nil {
+		return nil, err
+	}
+	if v1 {
+		object, err := client.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &cronJob{Object: object, gvk: batchv1.SchemeGroupVersion.WithKind("CronJob"), template: object.Spec.JobTemplate}, nil
+	}
+	object, err := client.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	template := batchv1.JobTemplateSpec{ObjectMeta: object.Spec.JobTemplate.ObjectMeta, Spec: object.Spec.JobTemplate.Spec}
+	return &cronJob{Object: object, gvk: batchv1beta1.SchemeGroupVersion.WithKind("CronJob"), template: template}, nil
+}
+
+// servesV1 returns true if cron jobs of batch/v1 are served, they are served since kubernetes 1.21
+func servesV1(client kubernetes.Interface) (bool, error) {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(batchv1.SchemeGroupVersion.String())
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "cronjobs" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Trigger creates a job from the job template of cron job, the same as kubectl create job --from does
+func Trigger(ctx context.Context, client kubernetes.Interface, namespace, name string) (*batchv1.Job, error) {
+	owner, err := getCronJob(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := map[string]string{job.InstantiateAnnotation: "manual"}
+	for k, v := range owner.template.Annotations {
+		annotations[k] = v
+	}
+	created := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            job.GenerateName(name, "manual"),
+			Namespace:       namespace,
+			Labels:          owner.template.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, owner.gvk)},
+		},
+		Spec: owner.template.Spec,
+	}
+	return client.BatchV1().Jobs(namespace).Create(ctx, created, metav1.CreateOptions{})
+}
+
+// Suspend suspends cron job, or resumes it if suspend is false, jobs already started are not affected
+func Suspend(ctx context.Context, client kubernetes.Interface, namespace, name string, suspend bool) (runtime.Object, error) {
+	v1, err := servesV1(client)
+	if err != nil {
+		return nil, err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	if v1 {
+		return client.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return client.BatchV1beta1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// History returns runs of cron job newest first, jobs and pods are listed by lister
+func History(ctx context.Context, client kubernetes.Interface, lister job.Lister, namespace, name string) (*response.JobRunHistory, error) {
+	owner, err := getCronJob(ctx, client, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return job.Runs(lister, namespace, owner.GetUID())
+}
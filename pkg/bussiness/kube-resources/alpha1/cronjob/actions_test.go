@@ -0,0 +1,116 @@
+package cronjob
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+)
+
+func TestActions(t *testing.T) {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: "backup-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "backup"}},
+				Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "busybox"}}},
+				}},
+			},
+		},
+	}
+	start := metav1.NewTime(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC))
+	completion := metav1.NewTime(start.Add(90 * time.Second))
+	controller := true
+	scheduled := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "backup-27654600",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup", UID: "backup-uid", Controller: &controller}},
+		},
+		Spec: batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "job-uid"}}},
+		Status: batchv1.JobStatus{
+			StartTime:      &start,
+			CompletionTime: &completion,
+			Succeeded:      1,
+			Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-27654600-x7k2p", Namespace: "default", Labels: map[string]string{"controller-uid": "job-uid"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	client := fake.NewSimpleClientset(cronJob, scheduled, pod)
+	client.Resources = []*metav1.APIResourceList{{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "cronjobs"}, {Name: "jobs"}}}}
+	ctx := context.Background()
+
+	triggered, err := Trigger(ctx, client, "default", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(triggered.Name, "backup-manual-") || triggered.Annotations[job.InstantiateAnnotation] != "manual" ||
+		triggered.Labels["app"] != "backup" || metav1.GetControllerOf(triggered).UID != "backup-uid" {
+		t.Errorf("unexpected job triggered %+v", triggered.ObjectMeta)
+	}
+
+	history, err := History(ctx, client, clientLister{client}, "default", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the job triggered is not started yet, it's the newest
+	if len(history.Items) != 2 || history.Items[0].Name != triggered.Name || !history.Items[0].Manual || len(history.Items[0].Pods) != 0 {
+		t.Fatalf("unexpected history %+v", history.Items)
+	}
+	run := history.Items[1]
+	if run.Outcome != job.OutcomeSucceeded || run.Duration == nil || run.Duration.Duration != 90*time.Second ||
+		len(run.Pods) != 1 || run.Pods[0].Name != pod.Name {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	object, err := Suspend(ctx, client, "default", "backup", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suspend := object.(*batchv1.CronJob).Spec.Suspend; suspend == nil || !*suspend {
+		t.Errorf("expected cron job suspended")
+	}
+}
+
+// clientLister lists jobs and pods from apiserver instead of informers
+type clientLister struct {
+	client kubernetes.Interface
+}
+
+func (l clientLister) ListJobs(namespace string) ([]*batchv1.Job, error) {
+	list, err := l.client.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*batchv1.Job
+	for i := range list.Items {
+		jobs = append(jobs, &list.Items[i])
+	}
+	return jobs, nil
+}
+
+func (l clientLister) ListPods(namespace string, selector labels.Selector) ([]*corev1.Pod, error) {
+	list, err := l.client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var pods []*corev1.Pod
+	for i := range list.Items {
+		pods = append(pods, &list.Items[i])
+	}
+	return pods, nil
+}
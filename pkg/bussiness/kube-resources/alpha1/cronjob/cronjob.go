@@ -4,11 +4,11 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
-	"strings"
 
 	v1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
@@ -17,6 +17,19 @@ const (
 	StatusRunning    = "running"
 )
 
+// TableColumns are default columns of cron jobs of both batch/v1 and batch/v1beta1 in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Schedule", ".spec.schedule", 0),
+	alpha1.Column("Suspend", ".spec.suspend", 0),
+	alpha1.Column("Active", ".status.active[*].name", 0),
+	alpha1.DateColumn("Last Schedule", ".status.lastScheduleTime", 0),
+	alpha1.AgeColumn,
+}
+
+// SortKeys are keys cron jobs of both versions are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(LastScheduleTime)
+
 type cronjobProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -41,7 +54,7 @@ func (cj cronjobProvider) List(namespace string, query *query.QueryInfo) (*respo
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -52,13 +65,23 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(cronJobStatus(cronJob), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(cronJob.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of cron job to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	cronJob, ok := object.(*v1.CronJob)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return cronJobStatus(cronJob), true
+}
+
+// Compare returns true if cron job left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftcj, ok := left.(*v1.CronJob)
 	if !ok {
@@ -88,3 +111,11 @@ func cronJobStatus(item *v1.CronJob) string {
 	}
 	return StatusRunning
 }
+
+func (cj cronjobProvider) Informer() cache.SharedIndexInformer {
+	return cj.informers.Batch().V1().CronJobs().Informer()
+}
+
+func (cj cronjobProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
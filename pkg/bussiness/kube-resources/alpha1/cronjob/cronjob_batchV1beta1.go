@@ -4,11 +4,11 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
-	"strings"
 
 	"k8s.io/api/batch/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 type cronjobV1beta1Provider struct {
@@ -35,7 +35,7 @@ func (cj cronjobV1beta1Provider) List(namespace string, query *query.QueryInfo)
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, v1Beta1CompareFunc, v1Beta1Filter), nil
+	return alpha1.DefaultList(result, query, V1beta1Compare, v1Beta1Filter)
 }
 
 func v1Beta1Filter(object runtime.Object, filter query.Filter) bool {
@@ -46,13 +46,23 @@ func v1Beta1Filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(v1Beta1CronJobStatus(cronJob), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(V1beta1FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(cronJob.ObjectMeta, filter)
 	}
 }
 
-func v1Beta1CompareFunc(left, right runtime.Object, field query.Field) bool {
+// V1beta1FieldValue returns value of field of batch/v1beta1 cron job to group by
+func V1beta1FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	cronJob, ok := object.(*v1beta1.CronJob)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return v1Beta1CronJobStatus(cronJob), true
+}
+
+// V1beta1Compare returns true if batch/v1beta1 cron job left is less than right by field
+func V1beta1Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftcj, ok := left.(*v1beta1.CronJob)
 	if !ok {
@@ -82,3 +92,11 @@ func v1Beta1CronJobStatus(item *v1beta1.CronJob) string {
 	}
 	return StatusRunning
 }
+
+func (cj cronjobV1beta1Provider) Informer() cache.SharedIndexInformer {
+	return cj.informers.Batch().V1beta1().CronJobs().Informer()
+}
+
+func (cj cronjobV1beta1Provider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, v1Beta1Filter)
+}
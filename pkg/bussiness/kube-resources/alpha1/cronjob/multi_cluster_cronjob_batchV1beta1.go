@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcCronJobBatchV1beta1Provider struct {
 	clusterclient.ClusterClients
 }
 
+var cronjobsV1beta1GVR = schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}
+
 func NewMCBatchV1beta1ResProvider(clients clusterclient.ClusterClients) mcCronJobBatchV1beta1Provider {
 	return mcCronJobBatchV1beta1Provider{ClusterClients: clients}
 }
 
 func (pd mcCronJobBatchV1beta1Provider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, cronjobsV1beta1GVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcCronJobBatchV1beta1Provider) Get(region, cluster, namespace, name str
 }
 
 func (pd mcCronJobBatchV1beta1Provider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, cronjobsV1beta1GVR, namespace, query, V1beta1Compare, v1Beta1Filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcCronJobBatchV1beta1Provider) List(region, cluster, namespace string,
 		}
 	}
 
-	return alpha1.DefaultList(result, query, v1Beta1CompareFunc, v1Beta1Filter), nil
+	return alpha1.DefaultList(result, query, V1beta1Compare, v1Beta1Filter)
 }
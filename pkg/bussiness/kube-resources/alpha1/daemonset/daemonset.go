@@ -5,8 +5,10 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 	"strings"
 )
 
@@ -16,6 +18,21 @@ const (
 	statusUpdating = "updating"
 )
 
+// TableColumns are default columns of daemon sets in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Desired", ".status.desiredNumberScheduled", 0),
+	alpha1.Column("Current", ".status.currentNumberScheduled", 0),
+	alpha1.Column("Ready", ".status.numberReady", 0),
+	alpha1.Column("Up-to-date", ".status.updatedNumberScheduled", 0),
+	alpha1.Column("Available", ".status.numberAvailable", 0),
+	alpha1.AgeColumn,
+	alpha1.Column("Images", ".spec.template.spec.containers[*].image", 1),
+}
+
+// SortKeys are keys daemon sets are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldStatus)
+
 type daemonsetProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -40,7 +57,7 @@ func (dms daemonsetProvider) List(namespace string, query *query.QueryInfo) (*re
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -50,13 +67,32 @@ func filter(object runtime.Object, filter query.Filter) bool {
 	}
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(daemonsetStatus(&daemonSet.Status), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(daemonSet.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of daemon set to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	daemonSet, ok := object.(*appsv1.DaemonSet)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return daemonsetStatus(&daemonSet.Status), true
+}
+
+// Requests returns resource requests of pods of daemon set scheduled to nodes
+func Requests(object runtime.Object) (corev1.ResourceList, bool) {
+	daemonSet, ok := object.(*appsv1.DaemonSet)
+	if !ok {
+		return nil, false
+	}
+	return alpha1.ReplicasRequests(&daemonSet.Spec.Template.Spec, daemonSet.Status.DesiredNumberScheduled), true
+}
+
+// Compare returns true if daemon set left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftDaemonSet, ok := left.(*appsv1.DaemonSet)
 	if !ok {
@@ -68,7 +104,12 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		return false
 	}
 
-	return alpha1.DefaultObjectMetaCompare(leftDaemonSet.ObjectMeta, rightDaemonSet.ObjectMeta, field)
+	switch field {
+	case query.FieldStatus:
+		return strings.Compare(daemonsetStatus(&leftDaemonSet.Status), daemonsetStatus(&rightDaemonSet.Status)) < 0
+	default:
+		return alpha1.DefaultObjectMetaCompare(leftDaemonSet.ObjectMeta, rightDaemonSet.ObjectMeta, field)
+	}
 }
 
 func daemonsetStatus(status *appsv1.DaemonSetStatus) string {
@@ -80,3 +121,11 @@ func daemonsetStatus(status *appsv1.DaemonSetStatus) string {
 		return statusUpdating
 	}
 }
+
+func (dms daemonsetProvider) Informer() cache.SharedIndexInformer {
+	return dms.informers.Apps().V1().DaemonSets().Informer()
+}
+
+func (dms daemonsetProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
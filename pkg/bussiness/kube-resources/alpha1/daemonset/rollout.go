@@ -0,0 +1,91 @@
+package daemonset
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/response"
+)
+
+type daemonsetRollouter struct{}
+
+func NewRollouter() daemonsetRollouter {
+	return daemonsetRollouter{}
+}
+
+func (dr daemonsetRollouter) Restart(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return client.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, alpha1.RestartPatch(), metav1.PatchOptions{})
+}
+
+func (dr daemonsetRollouter) Scale(ctx context.Context, client kubernetes.Interface, namespace, name string, replicas int32) (runtime.Object, error) {
+	return nil, alpha1.NewActionNotSupported("daemonsets", "scale")
+}
+
+func (dr daemonsetRollouter) Pause(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return nil, alpha1.NewActionNotSupported("daemonsets", "pause")
+}
+
+func (dr daemonsetRollouter) Resume(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return nil, alpha1.NewActionNotSupported("daemonsets", "resume")
+}
+
+// Rollback applies ControllerRevision of revision to daemonset, the same as kubectl rollout undo
+func (dr daemonsetRollouter) Rollback(ctx context.Context, client kubernetes.Interface, namespace, name string, revision int64) (runtime.Object, error) {
+	ds, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := alpha1.ControllerRevisions(ctx, client, ds, ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	target, err := alpha1.FindControllerRevision(revisions, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := &appsv1.DaemonSet{}
+	if err := alpha1.ApplyControllerRevision(ds, target, applied); err != nil {
+		return nil, err
+	}
+	if alpha1.TemplateEqual(&applied.Spec.Template, &ds.Spec.Template) {
+		return ds, nil
+	}
+	return client.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, target.Data.Raw, metav1.PatchOptions{})
+}
+
+// History returns revisions of daemonset built from ControllerRevisions owned by it, the latest
+// revision is the current one
+func (dr daemonsetRollouter) History(ctx context.Context, client kubernetes.Interface, namespace, name string) (*response.RevisionHistory, error) {
+	ds, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	controllerRevisions, err := alpha1.ControllerRevisions(ctx, client, ds, ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []response.Revision
+	for i, revision := range controllerRevisions {
+		applied := &appsv1.DaemonSet{}
+		if err := alpha1.ApplyControllerRevision(ds, revision, applied); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, response.Revision{
+			Revision:          revision.Revision,
+			Name:              revision.Name,
+			CreationTimestamp: revision.CreationTimestamp,
+			ChangeCause:       revision.Annotations[alpha1.ChangeCauseAnnotation],
+			Current:           i == len(controllerRevisions)-1,
+			Template:          applied.Spec.Template,
+		})
+	}
+	return alpha1.NewRevisionHistory(revisions)
+}
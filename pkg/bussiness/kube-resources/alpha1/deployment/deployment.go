@@ -8,16 +8,34 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
 	statusStopped  = "stopped"
 	statusRunning  = "running"
 	statusUpdating = "updating"
+
+	fieldReplicas = "replicas"
 )
 
+// TableColumns are default columns of deployments in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Replicas", ".spec.replicas", 0),
+	alpha1.Column("Ready", ".status.readyReplicas", 0),
+	alpha1.Column("Up-to-date", ".status.updatedReplicas", 0),
+	alpha1.Column("Available", ".status.availableReplicas", 0),
+	alpha1.AgeColumn,
+	alpha1.Column("Images", ".spec.template.spec.containers[*].image", 1),
+}
+
+// SortKeys are keys deployments are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldUpdateTime, query.FieldStatus, fieldReplicas)
+
 type deployProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -41,7 +59,7 @@ func (dp deployProvider) List(namespace string, query *query.QueryInfo) (*respon
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -52,13 +70,32 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(deploymentStatus(deployment.Status), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(deployment.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of deployment to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	deployment, ok := object.(*v1.Deployment)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return deploymentStatus(deployment.Status), true
+}
+
+// Requests returns resource requests of desired replicas of deployment
+func Requests(object runtime.Object) (corev1.ResourceList, bool) {
+	deployment, ok := object.(*v1.Deployment)
+	if !ok {
+		return nil, false
+	}
+	return alpha1.ReplicasRequests(&deployment.Spec.Template.Spec, desiredReplicas(deployment)), true
+}
+
+// Compare returns true if deployment left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftDeploy, ok := left.(*v1.Deployment)
 	if !ok {
@@ -73,11 +110,23 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		fallthrough
 	case query.FieldLastUpdateTimestamp:
 		return lastUpdateTime(leftDeploy).After(lastUpdateTime(rightDeploy))
+	case query.FieldStatus:
+		return strings.Compare(deploymentStatus(leftDeploy.Status), deploymentStatus(rightDeploy.Status)) < 0
+	case fieldReplicas:
+		return desiredReplicas(leftDeploy) < desiredReplicas(rightDeploy)
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftDeploy.ObjectMeta, rightDeploy.ObjectMeta, field)
 	}
 }
 
+// desiredReplicas returns replicas of deployment, which defaults to 1
+func desiredReplicas(deploy *v1.Deployment) int32 {
+	if deploy.Spec.Replicas == nil {
+		return 1
+	}
+	return *deploy.Spec.Replicas
+}
+
 func lastUpdateTime(deploy *v1.Deployment) time.Time {
 	recent := deploy.CreationTimestamp.Time
 
@@ -98,3 +147,11 @@ func deploymentStatus(status v1.DeploymentStatus) string {
 		return statusUpdating
 	}
 }
+
+func (dp deployProvider) Informer() cache.SharedIndexInformer {
+	return dp.sharedInformers.Apps().V1().Deployments().Informer()
+}
+
+func (dp deployProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
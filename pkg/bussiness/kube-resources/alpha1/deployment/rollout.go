@@ -0,0 +1,216 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/response"
+)
+
+const (
+	// revisionAnnotation is the revision of deployments and their ReplicaSets
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// annotationsNotRolledBack are annotations of ReplicaSets not copied to deployments on rollback, the
+// same as kubectl rollout undo
+var annotationsNotRolledBack = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:          true,
+	revisionAnnotation:                          true,
+	"deployment.kubernetes.io/revision-history": true,
+	"deployment.kubernetes.io/desired-replicas": true,
+	"deployment.kubernetes.io/max-replicas":     true,
+	appsv1.DeprecatedRollbackTo:                 true,
+}
+
+type deployRollouter struct{}
+
+func NewRollouter() deployRollouter {
+	return deployRollouter{}
+}
+
+func (dr deployRollouter) Restart(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	deploy, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deploy.Spec.Paused {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("deployment %s is paused, resume it before restart", name))
+	}
+	return client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, alpha1.RestartPatch(), metav1.PatchOptions{})
+}
+
+func (dr deployRollouter) Scale(ctx context.Context, client kubernetes.Interface, namespace, name string, replicas int32) (runtime.Object, error) {
+	return client.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, alpha1.ReplicasPatch(replicas), metav1.PatchOptions{})
+}
+
+func (dr deployRollouter) Pause(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return dr.setPaused(ctx, client, namespace, name, true)
+}
+
+func (dr deployRollouter) Resume(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return dr.setPaused(ctx, client, namespace, name, false)
+}
+
+func (dr deployRollouter) setPaused(ctx context.Context, client kubernetes.Interface, namespace, name string, paused bool) (runtime.Object, error) {
+	deploy, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deploy.Spec.Paused == paused {
+		state := "paused"
+		if !paused {
+			state = "not paused"
+		}
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("deployment %s is already %s", name, state))
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+	return client.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// Rollback replaces pod template of deployment with the template of ReplicaSet of revision, nothing
+// changes if the template is the same
+func (dr deployRollouter) Rollback(ctx context.Context, client kubernetes.Interface, namespace, name string, revision int64) (runtime.Object, error) {
+	deploy, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deploy.Spec.Paused {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("deployment %s is paused, resume it before rollback", name))
+	}
+	replicaSets, err := ownedReplicaSets(ctx, client, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *appsv1.ReplicaSet
+	if revision == 0 {
+		// the previous revision is the largest one other than the current
+		current := revisionOf(deploy)
+		for _, rs := range replicaSets {
+			if r := revisionOf(rs); r != current && (target == nil || r > revisionOf(target)) {
+				target = rs
+			}
+		}
+		if target == nil {
+			return nil, apierrors.NewBadRequest("no previous revision to roll back to")
+		}
+	} else {
+		for _, rs := range replicaSets {
+			if revisionOf(rs) == revision {
+				target = rs
+			}
+		}
+		if target == nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("revision %d is not found", revision))
+		}
+	}
+
+	template := templateOf(target)
+	current := templateOf(deploy)
+	if alpha1.TemplateEqual(&template, &current) {
+		return deploy, nil
+	}
+
+	// annotations of deployment are copied to its ReplicaSets, they are replaced by those of the revision
+	annotations := map[string]string{}
+	for k, v := range deploy.Annotations {
+		if annotationsNotRolledBack[k] {
+			annotations[k] = v
+		}
+	}
+	for k, v := range target.Annotations {
+		if !annotationsNotRolledBack[k] {
+			annotations[k] = v
+		}
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		// add replaces annotations if there are any
+		{"op": "add", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+}
+
+// History returns revisions of deployment built from ReplicaSets owned by it
+func (dr deployRollouter) History(ctx context.Context, client kubernetes.Interface, namespace, name string) (*response.RevisionHistory, error) {
+	deploy, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := ownedReplicaSets(ctx, client, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	current := revisionOf(deploy)
+	var revisions []response.Revision
+	for _, rs := range replicaSets {
+		revisions = append(revisions, response.Revision{
+			Revision:          revisionOf(rs),
+			Name:              rs.Name,
+			CreationTimestamp: rs.CreationTimestamp,
+			ChangeCause:       rs.Annotations[alpha1.ChangeCauseAnnotation],
+			Current:           revisionOf(rs) == current,
+			Template:          templateOf(rs),
+		})
+	}
+	return alpha1.NewRevisionHistory(revisions)
+}
+
+// ownedReplicaSets returns ReplicaSets controlled by deploy
+func ownedReplicaSets(ctx context.Context, client kubernetes.Interface, deploy *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.AppsV1().ReplicaSets(deploy.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var replicaSets []*appsv1.ReplicaSet
+	for i := range list.Items {
+		if ref := metav1.GetControllerOf(&list.Items[i]); ref != nil && ref.UID == deploy.UID {
+			replicaSets = append(replicaSets, &list.Items[i])
+		}
+	}
+	return replicaSets, nil
+}
+
+// revisionOf returns revision of deployment or ReplicaSet, 0 if it's unknown
+func revisionOf(object metav1.Object) int64 {
+	revision, _ := strconv.ParseInt(object.GetAnnotations()[revisionAnnotation], 10, 64)
+	return revision
+}
+
+// templateOf returns pod template of deployment or ReplicaSet without pod-template-hash label added
+// to ReplicaSets
+func templateOf(object runtime.Object) corev1.PodTemplateSpec {
+	var template corev1.PodTemplateSpec
+	switch o := object.(type) {
+	case *appsv1.Deployment:
+		template = *o.Spec.Template.DeepCopy()
+	case *appsv1.ReplicaSet:
+		template = *o.Spec.Template.DeepCopy()
+	}
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	if len(template.Labels) == 0 {
+		template.Labels = nil
+	}
+	return template
+}
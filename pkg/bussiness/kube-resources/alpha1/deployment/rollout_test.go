@@ -0,0 +1,115 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+)
+
+func TestRollout(t *testing.T) {
+	template := func(image, hash string) corev1.PodTemplateSpec {
+		labels := map[string]string{"app": "web"}
+		if len(hash) != 0 {
+			labels[appsv1.DefaultDeploymentUniqueLabelKey] = hash
+		}
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+		}
+	}
+	controller := true
+	replicaSet := func(name, revision, image string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: name},
+				Annotations: map[string]string{
+					revisionAnnotation:           revision,
+					alpha1.ChangeCauseAnnotation: "set image " + image,
+				},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &controller}},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: template(image, name)},
+		}
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         "web-uid",
+			Annotations: map[string]string{revisionAnnotation: "2", alpha1.ChangeCauseAnnotation: "set image nginx:1.21"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: template("nginx:1.21", ""),
+		},
+	}
+	client := fake.NewSimpleClientset(deploy, replicaSet("web-1", "1", "nginx:1.20"), replicaSet("web-2", "2", "nginx:1.21"))
+	rollouter := NewRollouter()
+	ctx := context.Background()
+
+	history, err := rollouter.History(ctx, client, "default", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Items) != 2 || history.Items[0].Name != "web-1" || history.Items[0].Current || !history.Items[1].Current ||
+		history.Items[1].ChangeCause != "set image nginx:1.21" || len(history.Items[1].Diff) == 0 {
+		t.Errorf("unexpected history %+v", history.Items)
+	}
+	if _, ok := history.Items[0].Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		t.Errorf("pod-template-hash is expected to be removed from templates, got %v", history.Items[0].Template.Labels)
+	}
+
+	// the previous revision is rolled back to
+	object, err := rollouter.Rollback(ctx, client, "default", "web", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolledBack := object.(*appsv1.Deployment)
+	if image := rolledBack.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.20" {
+		t.Errorf("expected image nginx:1.20 after rollback, got %s", image)
+	}
+	if cause := rolledBack.Annotations[alpha1.ChangeCauseAnnotation]; cause != "set image nginx:1.20" || rolledBack.Annotations[revisionAnnotation] != "2" {
+		t.Errorf("unexpected annotations after rollback %v", rolledBack.Annotations)
+	}
+	if _, err := rollouter.Rollback(ctx, client, "default", "web", 5); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of unknown revision, got %v", err)
+	}
+
+	if _, err := rollouter.Pause(ctx, client, "default", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rollouter.Pause(ctx, client, "default", "web"); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of pausing paused deployment, got %v", err)
+	}
+	if _, err := rollouter.Restart(ctx, client, "default", "web"); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of restarting paused deployment, got %v", err)
+	}
+	if _, err := rollouter.Resume(ctx, client, "default", "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err = rollouter.Restart(ctx, client, "default", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := object.(*appsv1.Deployment).Spec.Template.Annotations[alpha1.RestartedAtAnnotation]; !ok {
+		t.Errorf("expected %s annotation of pod template after restart", alpha1.RestartedAtAnnotation)
+	}
+
+	object, err = rollouter.Scale(ctx, client, "default", "web", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas := object.(*appsv1.Deployment).Spec.Replicas; replicas == nil || *replicas != 3 {
+		t.Errorf("expected 3 replicas after scale, got %v", replicas)
+	}
+}
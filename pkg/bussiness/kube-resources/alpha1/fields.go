@@ -0,0 +1,60 @@
+package alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+// objectFields exposes fields of object to field selectors by paths such as status.phase,
+// unlike apiserver any field of scalar value can be selected
+type objectFields map[string]interface{}
+
+func (f objectFields) Has(field string) bool {
+	_, found, err := unstructured.NestedFieldNoCopy(f, strings.Split(field, ".")...)
+	return found && err == nil
+}
+
+func (f objectFields) Get(field string) string {
+	value, found, err := unstructured.NestedFieldNoCopy(f, strings.Split(field, ".")...)
+	if !found || err != nil {
+		return ""
+	}
+	switch value.(type) {
+	case string, bool, int64, float64:
+		return fmt.Sprint(value)
+	default:
+		return ""
+	}
+}
+
+// fieldSelector returns field selector of query, queries are validated once parsed from requests so
+// a malformed selector selects nothing rather than everything
+func fieldSelector(q *query.QueryInfo) fields.Selector {
+	selector, err := q.GetFieldSelector()
+	if err != nil {
+		return fields.Nothing()
+	}
+	return selector
+}
+
+// MatchFields returns true if object is selected by field selector
+func MatchFields(object runtime.Object, selector fields.Selector) bool {
+	if selector.Empty() {
+		return true
+	}
+
+	if obj, ok := object.(runtime.Unstructured); ok {
+		return selector.Matches(objectFields(obj.UnstructuredContent()))
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(objectFields(content))
+}
@@ -0,0 +1,51 @@
+package alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMatchFields(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "node-1", HostNetwork: true},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{selector: "", expected: true},
+		{selector: "status.phase=Running", expected: true},
+		{selector: "status.phase==Running,spec.nodeName=node-1", expected: true},
+		{selector: "status.phase=Running,spec.nodeName!=node-1", expected: false},
+		{selector: "metadata.name=nginx,metadata.namespace!=kube-system", expected: true},
+		{selector: "spec.hostNetwork=true", expected: true},
+		{selector: "spec.nodeName!=", expected: true},
+		{selector: "status.podIP!=", expected: false},
+		{selector: "spec.containers=", expected: true},
+	}
+
+	for _, test := range tests {
+		selector, err := fields.ParseSelector(test.selector)
+		if err != nil {
+			t.Fatalf("%s: %v", test.selector, err)
+		}
+		if got := MatchFields(pod, selector); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.selector, test.expected, got)
+		}
+		if got := MatchFields(&unstructured.Unstructured{Object: content}, selector); got != test.expected {
+			t.Errorf("%s of unstructured: expected %v, got %v", test.selector, test.expected, got)
+		}
+	}
+}
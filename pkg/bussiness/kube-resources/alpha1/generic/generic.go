@@ -0,0 +1,306 @@
+package generic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clusterclient"
+)
+
+const (
+	// informerIdleTimeout is how long informers run without requests, informers being watched keep running
+	informerIdleTimeout = 30 * time.Minute
+	// evictionPeriod is how often idle informers are looked for
+	evictionPeriod = time.Minute
+)
+
+// Providers creates providers of resources without dedicated providers, e.g. CRDs, on demand.
+// Resources are discovered from the cluster, and listed from dynamic informers started on first
+// request, informers are stopped once they are idle for informerIdleTimeout.
+type Providers struct {
+	sync.Mutex
+
+	client    dynamic.Interface
+	discovery discovery.DiscoveryInterface
+	mapper    meta.RESTMapper
+
+	providers map[schema.GroupVersionResource]alpha1.KubeResProvider
+	informers map[schema.GroupVersionResource]*dynamicInformer
+	// evicted is called with informers stopped
+	evicted func(informer cache.SharedIndexInformer)
+}
+
+// dynamicInformer is an informer of a resource started on demand
+type dynamicInformer struct {
+	informers.GenericInformer
+
+	stopCh   chan struct{}
+	lastUsed time.Time
+	watchers int
+}
+
+func NewProviders(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface, stopCh <-chan struct{}) *Providers {
+	p := &Providers{
+		client:    client,
+		discovery: discoveryClient,
+		mapper:    clusterclient.NewRESTMapper(discoveryClient),
+		providers: make(map[schema.GroupVersionResource]alpha1.KubeResProvider),
+		informers: make(map[schema.GroupVersionResource]*dynamicInformer),
+		evicted:   func(cache.SharedIndexInformer) {},
+	}
+	go func() {
+		wait.Until(func() { p.evict(informerIdleTimeout) }, evictionPeriod, stopCh)
+		p.evict(0)
+	}()
+	return p
+}
+
+// OnEvict registers handler called with informers stopped, e.g. to release resources bound to them
+func (p *Providers) OnEvict(handler func(informer cache.SharedIndexInformer)) {
+	p.Lock()
+	defer p.Unlock()
+	p.evicted = handler
+}
+
+// Provider returns provider of resource in the form of resource[.version][.group], and whether
+// resource is namespaced. A meta.NoResourceMatchError is returned if resource is not served.
+// Providers of resources can be watched implement alpha1.WatchableKubeResProvider.
+func (p *Providers) Provider(resource string) (alpha1.KubeResProvider, bool, error) {
+	gvr, namespaced, err := Resolve(p.mapper, resource)
+	if err != nil {
+		return nil, false, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if pd, ok := p.providers[gvr]; ok {
+		if informer, ok := p.informers[gvr]; ok {
+			informer.lastUsed = time.Now()
+		}
+		return pd, namespaced, nil
+	}
+
+	verbs, err := p.verbs(gvr)
+	if err != nil {
+		return nil, false, err
+	}
+	if !verbs.Has("list") {
+		return nil, false, &meta.NoResourceMatchError{PartialResource: gvr}
+	}
+
+	p.providers[gvr] = provider{gvr: gvr, client: p.client}
+	if verbs.Has("watch") {
+		informer := &dynamicInformer{
+			GenericInformer: dynamicinformer.NewFilteredDynamicInformer(p.client, gvr, metav1.NamespaceAll, 0,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil),
+			stopCh:   make(chan struct{}),
+			lastUsed: time.Now(),
+		}
+		go informer.Informer().Run(informer.stopCh)
+		p.informers[gvr] = informer
+		p.providers[gvr] = informerProvider{provider: provider{gvr: gvr, client: p.client}, informer: informer, providers: p}
+	}
+	return p.providers[gvr], namespaced, nil
+}
+
+// Resolve resolves resource in the form of resource[.version][.group] to the preferred version
+// served by host cluster
+func (p *Providers) Resolve(resource string) (schema.GroupVersionResource, error) {
+	gvr, _, err := Resolve(p.mapper, resource)
+	return gvr, err
+}
+
+// evict stops informers not used for idle and not being watched, all informers are stopped if idle is 0
+func (p *Providers) evict(idle time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	for gvr, informer := range p.informers {
+		if idle != 0 && (informer.watchers != 0 || time.Since(informer.lastUsed) < idle) {
+			continue
+		}
+		klog.V(4).Infof("Stop idle informer of %s", gvr)
+		close(informer.stopCh)
+		delete(p.informers, gvr)
+		delete(p.providers, gvr)
+		p.evicted(informer.Informer())
+	}
+}
+
+// watch keeps informer running until release is called
+func (p *Providers) watch(informer *dynamicInformer) (release func()) {
+	p.Lock()
+	defer p.Unlock()
+	informer.watchers++
+	return func() {
+		p.Lock()
+		defer p.Unlock()
+		informer.watchers--
+		informer.lastUsed = time.Now()
+	}
+}
+
+// verbs returns verbs supported by resource
+func (p *Providers) verbs(gvr schema.GroupVersionResource) (sets.String, error) {
+	resources, err := p.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return sets.NewString(resource.Verbs...), nil
+		}
+	}
+	return nil, &meta.NoResourceMatchError{PartialResource: gvr}
+}
+
+// Resolve resolves resource in the form of resource[.version][.group] to the preferred version
+// served by the cluster, the same as kubectl does
+func Resolve(mapper meta.RESTMapper, resource string) (schema.GroupVersionResource, bool, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(resource)
+
+	var gvr schema.GroupVersionResource
+	var err error
+	if fullySpecified != nil {
+		gvr, err = mapper.ResourceFor(*fullySpecified)
+	}
+	if fullySpecified == nil || err != nil {
+		gvr, err = mapper.ResourceFor(groupResource.WithVersion(""))
+		if err != nil {
+			return schema.GroupVersionResource{}, false, err
+		}
+	}
+
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	return gvr, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// provider lists resources from apiserver, used by resources can't be watched
+type provider struct {
+	gvr    schema.GroupVersionResource
+	client dynamic.Interface
+}
+
+func (pd provider) Get(namespace, name string) (runtime.Object, error) {
+	return pd.client.Resource(pd.gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func (pd provider) List(namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	return list(pd.client, pd.gvr, namespace, query)
+}
+
+// informerProvider lists resources from informer, requests go to apiserver until informer has synced
+type informerProvider struct {
+	provider
+	informer  *dynamicInformer
+	providers *Providers
+}
+
+func (pd informerProvider) Get(namespace, name string) (runtime.Object, error) {
+	if !pd.informer.Informer().HasSynced() {
+		return pd.provider.Get(namespace, name)
+	}
+	if len(namespace) == 0 {
+		return pd.informer.Lister().Get(name)
+	}
+	return pd.informer.Lister().ByNamespace(namespace).Get(name)
+}
+
+func (pd informerProvider) List(namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if !pd.informer.Informer().HasSynced() {
+		return pd.provider.List(namespace, query)
+	}
+	result, err := pd.informer.Lister().ByNamespace(namespace).List(query.GetSelector())
+	if err != nil {
+		return nil, err
+	}
+	return alpha1.DefaultList(result, query, compareFunc, filter)
+}
+
+func (pd informerProvider) Informer() cache.SharedIndexInformer {
+	return pd.informer.Informer()
+}
+
+func (pd informerProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
+
+// Watched keeps the informer running while it's watched, release is called once the watch stops
+func (pd informerProvider) Watched() (release func()) {
+	return pd.providers.watch(pd.informer)
+}
+
+// list lists resources from apiserver
+func list(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	list, err := client.Resource(gvr).Namespace(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []runtime.Object
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, &list.Items[i])
+	}
+	return alpha1.DefaultList(result, query, compareFunc, filter)
+}
+
+func filter(object runtime.Object, filter query.Filter) bool {
+	obj, ok := object.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	return alpha1.DefaultObjectMetaFilter(objectMeta(obj), filter)
+}
+
+func compareFunc(left, right runtime.Object, field query.Field) bool {
+	leftObj, ok := left.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	rightObj, ok := right.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	return alpha1.DefaultObjectMetaCompare(objectMeta(leftObj), objectMeta(rightObj), field)
+}
+
+// objectMeta returns metadata of unstructured object used by filtering and sorting
+func objectMeta(obj *unstructured.Unstructured) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		UID:               obj.GetUID(),
+		Labels:            obj.GetLabels(),
+		Annotations:       obj.GetAnnotations(),
+		OwnerReferences:   obj.GetOwnerReferences(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+	}
+}
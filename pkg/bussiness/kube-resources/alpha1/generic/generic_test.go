@@ -0,0 +1,157 @@
+package generic
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/unify/query"
+)
+
+func TestResolve(t *testing.T) {
+	cronTab := metav1.APIResource{Name: "crontabs", Kind: "CronTab", Namespaced: true}
+	mapper := restmapper.NewDiscoveryRESTMapper([]*restmapper.APIGroupResources{
+		{
+			Group: metav1.APIGroup{
+				Name:             "stable.example.com",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}, {Version: "v1beta1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{"v1": {cronTab}, "v1beta1": {cronTab}},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "other.example.com",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{"v1": {{Name: "widgets", Kind: "Widget"}}},
+		},
+	})
+
+	tests := []struct {
+		resource   string
+		expected   schema.GroupVersionResource
+		namespaced bool
+		noMatch    bool
+	}{
+		{resource: "crontabs", expected: schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}, namespaced: true},
+		{resource: "crontabs.stable.example.com", expected: schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}, namespaced: true},
+		{resource: "crontabs.v1beta1.stable.example.com", expected: schema.GroupVersionResource{Group: "stable.example.com", Version: "v1beta1", Resource: "crontabs"}, namespaced: true},
+		{resource: "widgets", expected: schema.GroupVersionResource{Group: "other.example.com", Version: "v1", Resource: "widgets"}},
+		{resource: "crontabs.other.example.com", noMatch: true},
+	}
+
+	for _, test := range tests {
+		gvr, namespaced, err := Resolve(mapper, test.resource)
+		if test.noMatch {
+			if !meta.IsNoMatchError(err) {
+				t.Errorf("%s: expected no match error, got %v", test.resource, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.resource, err)
+			continue
+		}
+		if gvr != test.expected || namespaced != test.namespaced {
+			t.Errorf("%s: expected %v namespaced %v, got %v namespaced %v", test.resource, test.expected, test.namespaced, gvr, namespaced)
+		}
+	}
+}
+
+func TestProviderList(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}
+	newCronTab := func(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("stable.example.com/v1")
+		obj.SetKind("CronTab")
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return obj
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CronTabList"},
+		newCronTab("default", "a", map[string]string{"app": "x"}),
+		newCronTab("default", "b", map[string]string{"app": "y"}),
+		newCronTab("default", "ab", map[string]string{"app": "x"}),
+		newCronTab("kube-system", "ac", map[string]string{"app": "x"}),
+	)
+	pd := provider{gvr: gvr, client: client}
+
+	q := query.New()
+	q.LabelSelector = "app=x"
+	q.Filters[query.FieldName] = "a"
+	q.Ascending = true
+	result, err := pd.List("default", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("expected 2 items, got %d", result.Total)
+	}
+	for i, name := range []string{"a", "ab"} {
+		if got := result.Items[i].(*unstructured.Unstructured).GetName(); got != name {
+			t.Errorf("expected item %d %s, got %s", i, name, got)
+		}
+	}
+}
+
+func TestEvictIdleInformers(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CronTabList"})
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "stable.example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "crontabs", Kind: "CronTab", Namespaced: true, Verbs: []string{"list", "watch"}}},
+	}}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	p := NewProviders(client, discovery, stopCh)
+	var evicted []cache.SharedIndexInformer
+	p.OnEvict(func(informer cache.SharedIndexInformer) {
+		evicted = append(evicted, informer)
+	})
+
+	pd, _, err := p.Provider("crontabs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched, ok := pd.(informerProvider)
+	if !ok {
+		t.Fatalf("expected informer provider, got %T", pd)
+	}
+
+	// informers being watched are kept running
+	release := watched.Watched()
+	p.informers[gvr].lastUsed = time.Now().Add(-2 * informerIdleTimeout)
+	p.evict(informerIdleTimeout)
+	if len(evicted) != 0 {
+		t.Fatalf("expected informer being watched kept")
+	}
+
+	release()
+	p.evict(informerIdleTimeout)
+	if len(evicted) != 0 {
+		t.Fatalf("expected informer just watched kept")
+	}
+	p.informers[gvr].lastUsed = time.Now().Add(-2 * informerIdleTimeout)
+	p.evict(informerIdleTimeout)
+	if len(evicted) != 1 || evicted[0] != watched.Informer() {
+		t.Fatalf("expected idle informer evicted, got %v", evicted)
+	}
+	if pd, _, err := p.Provider("crontabs"); err != nil || pd.(informerProvider).informer == watched.informer {
+		t.Errorf("expected informer started again, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package generic
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clusterclient"
+)
+
+// mcGenericProvider lists resource in the form of resource[.version][.group] from member clusters,
+// resource is resolved in each cluster since clusters may serve different versions
+type mcGenericProvider struct {
+	clusterclient.ClusterClients
+	resource string
+}
+
+func NewMCResProvider(clients clusterclient.ClusterClients, resource string) mcGenericProvider {
+	return mcGenericProvider{ClusterClients: clients, resource: resource}
+}
+
+func (pd mcGenericProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	cli, gvr, namespace, err := pd.resolve(region, cluster, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return cli.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
+func (pd mcGenericProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	cli, gvr, namespace, err := pd.resolve(region, cluster, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return list(cli, gvr, namespace, query)
+}
+
+// resolve returns client and resource of cluster, namespace is ignored for cluster scoped resources
+// as the other multi cluster providers do
+func (pd mcGenericProvider) resolve(region, cluster, namespace string) (dynamic.Interface, schema.GroupVersionResource, string, error) {
+	mapper, err := pd.GetRESTMapper(region, cluster)
+	if err != nil {
+		return nil, schema.GroupVersionResource{}, "", err
+	}
+	gvr, namespaced, err := Resolve(mapper, pd.resource)
+	if err != nil {
+		return nil, schema.GroupVersionResource{}, "", err
+	}
+	if !namespaced {
+		namespace = ""
+	}
+
+	cli, err := pd.GetDynamicClient(region, cluster)
+	if err != nil {
+		return nil, schema.GroupVersionResource{}, "", err
+	}
+	return cli, gvr, namespace, nil
+}
@@ -7,8 +7,18 @@ import (
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of ingresses of both networking.k8s.io/v1 and extensions/v1beta1 in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Class", ".spec.ingressClassName", 0),
+	alpha1.Column("Hosts", ".spec.rules[*].host", 0),
+	alpha1.Column("Address", ".status.loadBalancer.ingress[*].ip", 0),
+	alpha1.AgeColumn,
+}
+
 type ingressProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -32,7 +42,7 @@ func (ing ingressProvider) List(namespace string, query *query.QueryInfo) (*resp
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -62,3 +72,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		return alpha1.DefaultObjectMetaCompare(leftIngress.ObjectMeta, rightIngress.ObjectMeta, field)
 	}
 }
+
+func (ing ingressProvider) Informer() cache.SharedIndexInformer {
+	return ing.sharedInformers.Networking().V1().Ingresses().Informer()
+}
+
+func (ing ingressProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
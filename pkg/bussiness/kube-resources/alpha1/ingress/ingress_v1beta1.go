@@ -8,6 +8,7 @@ import (
 	v1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 type ingressV1beta1Provider struct {
@@ -33,7 +34,7 @@ func (ing ingressV1beta1Provider) List(namespace string, query *query.QueryInfo)
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, v1beta1CompareFunc, v1beta1Filter), nil
+	return alpha1.DefaultList(result, query, v1beta1CompareFunc, v1beta1Filter)
 }
 
 func v1beta1Filter(object runtime.Object, filter query.Filter) bool {
@@ -63,3 +64,11 @@ func v1beta1CompareFunc(left, right runtime.Object, field query.Field) bool {
 		return alpha1.DefaultObjectMetaCompare(leftIngress.ObjectMeta, rightIngress.ObjectMeta, field)
 	}
 }
+
+func (ing ingressV1beta1Provider) Informer() cache.SharedIndexInformer {
+	return ing.sharedInformers.Networking().V1beta1().Ingresses().Informer()
+}
+
+func (ing ingressV1beta1Provider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, v1beta1Filter)
+}
@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcIngressV1beta1Provider struct {
 	clusterclient.ClusterClients
 }
 
+var ingressesV1beta1GVR = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}
+
 func NewMCV1beta1ResProvider(clients clusterclient.ClusterClients) mcIngressV1beta1Provider {
 	return mcIngressV1beta1Provider{ClusterClients: clients}
 }
 
 func (pd mcIngressV1beta1Provider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, ingressesV1beta1GVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcIngressV1beta1Provider) Get(region, cluster, namespace, name string)
 }
 
 func (pd mcIngressV1beta1Provider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, ingressesV1beta1GVR, namespace, query, v1beta1CompareFunc, v1beta1Filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcIngressV1beta1Provider) List(region, cluster, namespace string, query
 		}
 	}
 
-	return alpha1.DefaultList(result, query, v1beta1CompareFunc, v1beta1Filter), nil
+	return alpha1.DefaultList(result, query, v1beta1CompareFunc, v1beta1Filter)
 }
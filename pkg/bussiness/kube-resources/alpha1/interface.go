@@ -7,8 +7,11 @@ import (
 	"sort"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
 )
 
 type KubeResProvider interface {
@@ -19,6 +22,18 @@ type KubeResProvider interface {
 	List(namespace string, query *query.QueryInfo) (*response.ListResult, error)
 }
 
+// WatchableKubeResProvider is implemented by providers backed by shared informers, changes of
+// objects are watched through the informer
+type WatchableKubeResProvider interface {
+	KubeResProvider
+
+	// Informer returns the informer objects are listed from
+	Informer() cache.SharedIndexInformer
+
+	// Match returns true if object is selected by query, the same as List does
+	Match(object runtime.Object, query *query.QueryInfo) bool
+}
+
 type MultiClusterKubeResProvider interface {
 	// Get retrieves a single object by its namespace and name
 	Get(region, cluster, namespace, name string) (runtime.Object, error)
@@ -32,21 +47,27 @@ type CompareFunc func(runtime.Object, runtime.Object, query.Field) bool
 
 type FilterFunc func(runtime.Object, query.Filter) bool
 
+// TransformFunc transforms objects listed, it receives copies of objects which can be mutated
 type TransformFunc func(runtime.Object) runtime.Object
 
-func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc CompareFunc, filterFunc FilterFunc, transferFuncs ...TransformFunc) *response.ListResult {
+// DefaultList filters, sorts and pages objects by query, errors are returned for continue tokens
+// which can't be resumed
+func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc CompareFunc, filterFunc FilterFunc, transferFuncs ...TransformFunc) (*response.ListResult, error) {
+
+	fieldSelector := fieldSelector(q)
 
 	var filtered []runtime.Object
 	for _, obj := range objects {
 		//is targeted by such filter key/values
-		targeted := true
-		for k, v := range q.Filters {
-			if !filterFunc(obj, query.Filter{Field: k, Value: v}) {
-				targeted = false
-			}
-		}
+		targeted := MatchFields(obj, fieldSelector) && q.MatchFilters(func(filter query.Filter) bool {
+			return filterFunc(obj, filter)
+		})
 
 		if targeted {
+			// objects are shared with informers, transforms mutate copies of them
+			if len(transferFuncs) != 0 {
+				obj = obj.DeepCopyObject()
+			}
 			for _, transform := range transferFuncs {
 				obj = transform(obj)
 			}
@@ -54,19 +75,37 @@ func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc Compa
 		}
 	}
 
-	//sort by some field
-	sort.Slice(filtered, func(i, j int) bool {
-		//Ascending
-		if q.Ascending {
-			return compareFunc(filtered[i], filtered[j], q.SortBy)
+	//sort by some field, JSONPaths are sorted the same for all resources
+	if q.SortBy.IsJSONPath() {
+		if compare, err := newJSONPathCompareFunc(q.SortBy, filtered); err == nil {
+			compareFunc = compare
 		}
-		return !compareFunc(filtered[i], filtered[j], q.SortBy)
+	}
+	less := newLessFunc(compareFunc, q.SortBy, q.Ascending)
+	sort.Slice(filtered, func(i, j int) bool {
+		return less(filtered[i], filtered[j])
 	})
 
 	//summarize
 
 	total := len(filtered)
 
+	if q.CursorMode() {
+		resourceVersion := newestResourceVersion(objects)
+		items, next, err := cursorPage(filtered, q, resourceVersion)
+		if err != nil {
+			return nil, err
+		}
+		return &response.ListResult{
+			Total:           total,
+			PageSize:        cursorLimit(q),
+			TotalPages:      int(math.Ceil(float64(total) / float64(cursorLimit(q)))),
+			Items:           objects2Interfaces(items),
+			Continue:        next,
+			ResourceVersion: resourceVersion,
+		}, nil
+	}
+
 	begin, end := q.Pagination.GetValidPagination(total)
 
 	return &response.ListResult{
@@ -75,7 +114,24 @@ func DefaultList(objects []runtime.Object, q *query.QueryInfo, compareFunc Compa
 		PageSize:    q.Pagination.PageSize,
 		TotalPages:  int(math.Ceil(float64(total) / float64(q.Pagination.PageSize))),
 		Items:       objects2Interfaces(filtered[begin:end]),
+	}, nil
+}
+
+// DefaultMatch returns true if object is selected by label selector, field selector and filters of query
+func DefaultMatch(object runtime.Object, q *query.QueryInfo, filterFunc FilterFunc) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return false
 	}
+	if !q.GetSelector().Matches(labels.Set(accessor.GetLabels())) {
+		return false
+	}
+	if !MatchFields(object, fieldSelector(q)) {
+		return false
+	}
+	return q.MatchFilters(func(filter query.Filter) bool {
+		return filterFunc(object, filter)
+	})
 }
 
 func objects2Interfaces(objs []runtime.Object) []interface{} {
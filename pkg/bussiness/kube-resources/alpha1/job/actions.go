@@ -0,0 +1,213 @@
+package job
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/unify/response"
+)
+
+// Outcomes of job runs
+const (
+	OutcomeRunning   = "Running"
+	OutcomeSucceeded = "Succeeded"
+	OutcomeFailed    = "Failed"
+	OutcomeSuspended = "Suspended"
+)
+
+const (
+	// RerunOfAnnotation is set to name of the job a job is cloned from by rerun
+	RerunOfAnnotation = "captain.io/rerun-of"
+	// InstantiateAnnotation is set to manual on jobs created from cron jobs manually, the same as kubectl does
+	InstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+	// maxNameLength is the max length of names of jobs, it's the max length of label values as
+	// names of jobs are set to the job-name label of their pods
+	maxNameLength = 63
+	// randomSuffixLength is length of random suffixes of names generated
+	randomSuffixLength = 5
+)
+
+// labels and annotations set by the job controller and the apiserver, they are bound to the original job
+var (
+	controllerLabels = []string{
+		"controller-uid", "job-name",
+		"batch.kubernetes.io/controller-uid", "batch.kubernetes.io/job-name",
+	}
+	controllerAnnotations = []string{
+		"batch.kubernetes.io/job-tracking",
+		"kubectl.kubernetes.io/last-applied-configuration",
+	}
+)
+
+// GenerateName returns name of the form <base>-<suffix>-<random>, base is truncated to keep the name
+// within the max length
+func GenerateName(base, suffix string) string {
+	suffix = "-" + suffix + "-" + utilrand.String(randomSuffixLength)
+	if max := maxNameLength - len(suffix); len(base) > max {
+		base = strings.TrimRight(base[:max], "-.")
+	}
+	return base + suffix
+}
+
+// Rerun creates a job cloned from job name with a new name, the selector is generated again by the
+// apiserver so pods of the original job are not adopted. Owner references are kept so that the job
+// is garbage collected with owners of the original job, but owners don't control it, e.g. reruns of
+// jobs of a cron job are neither counted nor cleaned up by the cron job controller.
+func Rerun(ctx context.Context, client kubernetes.Interface, namespace, name string) (*batchv1.Job, error) {
+	original, err := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	base := name
+	if rerunOf, ok := original.Annotations[RerunOfAnnotation]; ok {
+		base = rerunOf
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            GenerateName(base, "rerun"),
+			Namespace:       namespace,
+			Labels:          withoutKeys(original.Labels, controllerLabels),
+			Annotations:     withoutKeys(original.Annotations, controllerAnnotations),
+			OwnerReferences: withoutController(original.OwnerReferences),
+		},
+		Spec: *original.Spec.DeepCopy(),
+	}
+	if job.Annotations == nil {
+		job.Annotations = make(map[string]string)
+	}
+	job.Annotations[RerunOfAnnotation] = base
+	job.Spec.Selector, job.Spec.ManualSelector = nil, nil
+	job.Spec.Template.Labels = withoutKeys(job.Spec.Template.Labels, controllerLabels)
+	return client.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+}
+
+// withoutKeys returns a copy of m without keys
+func withoutKeys(m map[string]string, keys []string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	for _, key := range keys {
+		delete(copied, key)
+	}
+	return copied
+}
+
+// withoutController returns a copy of references, none of which is a controller
+func withoutController(references []metav1.OwnerReference) []metav1.OwnerReference {
+	var copied []metav1.OwnerReference
+	for _, reference := range references {
+		reference.Controller = nil
+		copied = append(copied, reference)
+	}
+	return copied
+}
+
+// Lister lists jobs and pods of a namespace, which are read from informers of the cluster
+type Lister interface {
+	ListJobs(namespace string) ([]*batchv1.Job, error)
+	ListPods(namespace string, selector labels.Selector) ([]*corev1.Pod, error)
+}
+
+// Runs returns runs of jobs controlled by owner, pods of jobs are listed too
+func Runs(lister Lister, namespace string, owner types.UID) (*response.JobRunHistory, error) {
+	jobs, err := lister.ListJobs(namespace)
+	if err != nil {
+		return nil, err
+	}
+	history := &response.JobRunHistory{Items: []response.JobRun{}}
+	for _, job := range jobs {
+		if controller := metav1.GetControllerOf(job); controller == nil || controller.UID != owner {
+			continue
+		}
+		// selectors of jobs are set by the apiserver, empty selectors would select all pods
+		var pods []corev1.Pod
+		if job.Spec.Selector != nil && (len(job.Spec.Selector.MatchLabels) != 0 || len(job.Spec.Selector.MatchExpressions) != 0) {
+			selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+			if err != nil {
+				return nil, err
+			}
+			listed, err := lister.ListPods(namespace, selector)
+			if err != nil {
+				return nil, err
+			}
+			// pods are shared with informers, Run sorts copies of them
+			for _, pod := range listed {
+				pods = append(pods, *pod)
+			}
+		}
+		history.Items = append(history.Items, Run(job, pods, time.Now()))
+	}
+	sort.SliceStable(history.Items, func(i, j int) bool {
+		return startTime(history.Items[i]).After(startTime(history.Items[j]))
+	})
+	return history, nil
+}
+
+func startTime(run response.JobRun) time.Time {
+	if run.StartTime == nil {
+		// jobs not started yet are the newest
+		return time.Unix(1<<62, 0)
+	}
+	return run.StartTime.Time
+}
+
+// Run returns run of job, duration of running jobs is until now
+func Run(job *batchv1.Job, pods []corev1.Pod, now time.Time) response.JobRun {
+	run := response.JobRun{
+		Name:           job.Name,
+		Manual:         job.Annotations[InstantiateAnnotation] == "manual",
+		StartTime:      job.Status.StartTime,
+		CompletionTime: job.Status.CompletionTime,
+		Outcome:        OutcomeRunning,
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+		Pods:           []response.JobRunPod{},
+	}
+	end := now
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			run.Outcome = OutcomeSucceeded
+			if job.Status.CompletionTime != nil {
+				end = job.Status.CompletionTime.Time
+			}
+		case batchv1.JobFailed:
+			run.Outcome, run.Message = OutcomeFailed, strings.TrimSpace(condition.Reason+" "+condition.Message)
+			end = condition.LastTransitionTime.Time
+		}
+	}
+	if run.Outcome == OutcomeRunning && job.Spec.Suspend != nil && *job.Spec.Suspend {
+		run.Outcome = OutcomeSuspended
+	}
+	if run.StartTime != nil && run.Outcome != OutcomeSuspended && !end.Before(run.StartTime.Time) {
+		run.Duration = &metav1.Duration{Duration: end.Sub(run.StartTime.Time).Round(time.Second)}
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+	})
+	for _, pod := range pods {
+		run.Pods = append(run.Pods, response.JobRunPod{Name: pod.Name, Phase: string(pod.Status.Phase)})
+	}
+	return run
+}
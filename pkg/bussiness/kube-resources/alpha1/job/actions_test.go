@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRerun(t *testing.T) {
+	manual, controller := true, true
+	labels := map[string]string{"app": "migrate", "controller-uid": "migrate-uid", "job-name": "migrate"}
+	original := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "migrate",
+			Namespace:       "default",
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "migrate", UID: "cronjob-uid", Controller: &controller}},
+		},
+		Spec: batchv1.JobSpec{
+			ManualSelector: &manual,
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "migrate-uid"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate", Image: "migrate:v1"}}},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(original)
+	ctx := context.Background()
+
+	rerun, err := Rerun(ctx, client, "default", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rerun.Name, "migrate-rerun-") || rerun.Annotations[RerunOfAnnotation] != "migrate" {
+		t.Errorf("unexpected job %+v", rerun.ObjectMeta)
+	}
+	if rerun.Spec.Selector != nil || rerun.Spec.ManualSelector != nil {
+		t.Errorf("expected selector generated again, got %v", rerun.Spec.Selector)
+	}
+	// reruns are garbage collected with the cron job, but are not controlled by it
+	if len(rerun.OwnerReferences) != 1 || rerun.OwnerReferences[0].UID != "cronjob-uid" || metav1.GetControllerOf(rerun) != nil {
+		t.Errorf("unexpected owner references %+v", rerun.OwnerReferences)
+	}
+	if _, ok := rerun.Spec.Template.Labels["controller-uid"]; ok || rerun.Spec.Template.Labels["app"] != "migrate" {
+		t.Errorf("unexpected labels of pod template %v", rerun.Spec.Template.Labels)
+	}
+
+	// reruns of reruns are named after the first job
+	again, err := Rerun(ctx, client, "default", rerun.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(again.Name, "migrate-rerun-") || again.Annotations[RerunOfAnnotation] != "migrate" {
+		t.Errorf("unexpected job %+v", again.ObjectMeta)
+	}
+
+	if name := GenerateName(strings.Repeat("a", 70), "rerun"); len(name) != maxNameLength {
+		t.Errorf("expected name of %d characters, got %s", maxNameLength, name)
+	}
+}
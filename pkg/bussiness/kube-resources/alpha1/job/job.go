@@ -8,6 +8,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 	"strings"
 	"time"
 )
@@ -18,6 +19,19 @@ const (
 	jobRunning   = "running"
 )
 
+// TableColumns are default columns of jobs in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Completions", ".spec.completions", 0),
+	alpha1.Column("Succeeded", ".status.succeeded", 0),
+	alpha1.Column("Active", ".status.active", 0),
+	alpha1.AgeColumn,
+	alpha1.Column("Images", ".spec.template.spec.containers[*].image", 1),
+}
+
+// SortKeys are keys jobs are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldUpdateTime, query.FieldStatus, query.FieldStartTime)
+
 type jobProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -41,7 +55,7 @@ func (j jobProvider) List(namespace string, query *query.QueryInfo) (*response.L
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -52,13 +66,32 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(jobStatus(job.Status), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(job.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of job to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	job, ok := object.(*batchv1.Job)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return jobStatus(job.Status), true
+}
+
+// Requests returns resource requests of active pods of job
+func Requests(object runtime.Object) (corev1.ResourceList, bool) {
+	job, ok := object.(*batchv1.Job)
+	if !ok {
+		return nil, false
+	}
+	return alpha1.ReplicasRequests(&job.Spec.Template.Spec, job.Status.Active), true
+}
+
+// Compare returns true if job left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftJob, ok := left.(*batchv1.Job)
 	if !ok {
@@ -77,6 +110,14 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		return lastUpdateTime(leftJob).After(lastUpdateTime(rightJob))
 	case query.FieldStatus:
 		return strings.Compare(jobStatus(leftJob.Status), jobStatus(rightJob.Status)) > 0
+	case query.FieldStartTime:
+		if leftJob.Status.StartTime == nil {
+			return false
+		}
+		if rightJob.Status.StartTime == nil {
+			return true
+		}
+		return leftJob.Status.StartTime.Before(rightJob.Status.StartTime)
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftJob.ObjectMeta, rightJob.ObjectMeta, field)
 	}
@@ -104,3 +145,11 @@ func lastUpdateTime(job *batchv1.Job) time.Time {
 	}
 	return lut
 }
+
+func (j jobProvider) Informer() cache.SharedIndexInformer {
+	return j.sharedInformers.Batch().V1().Jobs().Informer()
+}
+
+func (j jobProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -8,8 +8,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of limit ranges in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type limitRangeProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -33,7 +40,7 @@ func (lr limitRangeProvider) List(namespace string, query *query.QueryInfo) (*re
 		result = append(result, limitRange)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -59,3 +66,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftlr.ObjectMeta, rightlr.ObjectMeta, field)
 }
+
+func (lr limitRangeProvider) Informer() cache.SharedIndexInformer {
+	return lr.sharedInformers.Core().V1().LimitRanges().Informer()
+}
+
+func (lr limitRangeProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
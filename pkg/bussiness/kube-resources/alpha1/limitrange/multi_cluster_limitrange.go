@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcLimitRangeProvider struct {
 	clusterclient.ClusterClients
 }
 
+var limitrangesGVR = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcLimitRangeProvider {
 	return mcLimitRangeProvider{ClusterClients: clients}
 }
 
 func (pd mcLimitRangeProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, limitrangesGVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcLimitRangeProvider) Get(region, cluster, namespace, name string) (run
 }
 
 func (pd mcLimitRangeProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, limitrangesGVR, namespace, query, compareFunc, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcLimitRangeProvider) List(region, cluster, namespace string, query *qu
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
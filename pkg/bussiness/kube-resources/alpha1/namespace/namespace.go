@@ -10,8 +10,19 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of namespaces in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Status", ".status.phase", 0),
+	alpha1.AgeColumn,
+}
+
+// SortKeys are keys namespaces are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldUpdateTime, query.FieldStatus)
+
 type namespaceProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -36,7 +47,7 @@ func (ns namespaceProvider) List(namespace string, query *query.QueryInfo) (*res
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -47,13 +58,23 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(string(namespace.Status.Phase), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(namespace.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of namespace to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	namespace, ok := object.(*v1.Namespace)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return string(namespace.Status.Phase), true
+}
+
+// Compare returns true if namespace left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftNS, ok := left.(*v1.Namespace)
 	if !ok {
@@ -68,6 +89,8 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		fallthrough
 	case query.FieldLastUpdateTimestamp:
 		return lastUpdateTime(leftNS).After(lastUpdateTime(rightNS))
+	case query.FieldStatus:
+		return strings.Compare(string(leftNS.Status.Phase), string(rightNS.Status.Phase)) < 0
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftNS.ObjectMeta, rightNS.ObjectMeta, field)
 	}
@@ -83,3 +106,11 @@ func lastUpdateTime(namespase *v1.Namespace) time.Time {
 	}
 	return recent
 }
+
+func (ns namespaceProvider) Informer() cache.SharedIndexInformer {
+	return ns.informers.Core().V1().Namespaces().Informer()
+}
+
+func (ns namespaceProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -8,8 +8,16 @@ import (
 	v1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of network policies in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Policy Types", ".spec.policyTypes[*]", 0),
+	alpha1.AgeColumn,
+}
+
 type networkpolicyProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -33,7 +41,7 @@ func (netp networkpolicyProvider) List(namespace string, query *query.QueryInfo)
 		result = append(result, item)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -58,3 +66,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 	}
 	return alpha1.DefaultObjectMetaCompare(leftNP.ObjectMeta, rightNP.ObjectMeta, field)
 }
+
+func (netp networkpolicyProvider) Informer() cache.SharedIndexInformer {
+	return netp.sharedInformers.Networking().V1().NetworkPolicies().Informer()
+}
+
+func (netp networkpolicyProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
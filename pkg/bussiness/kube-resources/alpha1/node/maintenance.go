@@ -0,0 +1,388 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	"captain/pkg/unify/response"
+)
+
+// Phases of drains
+const (
+	DrainRunning   = "Running"
+	DrainSucceeded = "Succeeded"
+	DrainFailed    = "Failed"
+	DrainCancelled = "Cancelled"
+)
+
+// Phases of pods in drains
+const (
+	PodPending  = "Pending"
+	PodEvicting = "Evicting"
+	PodBlocked  = "Blocked"
+	PodEvicted  = "Evicted"
+	PodSkipped  = "Skipped"
+	PodFailed   = "Failed"
+)
+
+// DefaultDrainTimeout is the timeout of drains if it's not set
+const DefaultDrainTimeout = 10 * time.Minute
+
+var (
+	// evictionRetryInterval is the interval of retrying evictions disallowed by PodDisruptionBudgets
+	evictionRetryInterval = 5 * time.Second
+	// deletionPollInterval is the interval of checking whether evicted pods are deleted
+	deletionPollInterval = 2 * time.Second
+	// finishedDrainTTL is how long progress of finished drains is kept
+	finishedDrainTTL = time.Hour
+)
+
+// Cordon marks node unschedulable, or schedulable if unschedulable is false
+func Cordon(ctx context.Context, client kubernetes.Interface, name string, unschedulable bool) (*corev1.Node, error) {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	return client.CoreV1().Nodes().Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+}
+
+// DrainOptions are options of draining nodes, the same as those of kubectl drain
+type DrainOptions struct {
+	// GracePeriodSeconds overrides grace periods of pods if it's set
+	GracePeriodSeconds *int64
+	// Timeout is how long the drain lasts before giving up, DefaultDrainTimeout if it's zero
+	Timeout time.Duration
+	// Force allows evicting pods not managed by controllers
+	Force bool
+	// DeleteEmptyDirData allows evicting pods using emptyDir volumes, data of which is deleted
+	DeleteEmptyDirData bool
+}
+
+// Drains runs drains of nodes in background and keeps the latest drain of each node in memory until
+// finishedDrainTTL after it finishes. Progress is local to the replica running the drain, so that
+// captain-server must run a single replica, otherwise progress requests routed to other replicas
+// are not found, and a node could be drained by many replicas at the same time.
+type Drains struct {
+	lock   sync.Mutex
+	drains map[string]*drain
+	ttl    time.Duration
+}
+
+type drain struct {
+	status response.NodeDrain
+	cancel context.CancelFunc
+	// changed is closed and replaced once status is updated
+	changed chan struct{}
+}
+
+// NewDrains returns an empty registry of drains
+func NewDrains() *Drains {
+	return &Drains{drains: make(map[string]*drain), ttl: finishedDrainTTL}
+}
+
+func drainKey(region, cluster, node string) string {
+	return region + "/" + cluster + "/" + node
+}
+
+// Start cordons node and evicts pods on it in background, the initial progress is returned,
+// it fails with conflict if the node is being drained
+func (d *Drains) Start(ctx context.Context, client kubernetes.Interface, region, cluster, name string, options DrainOptions) (*response.NodeDrain, error) {
+	if _, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultDrainTimeout
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	key := drainKey(region, cluster, name)
+	if current, ok := d.drains[key]; ok && current.status.Phase == DrainRunning {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "nodes"}, name, fmt.Errorf("node is being drained"))
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	current := &drain{
+		status: response.NodeDrain{
+			Region:    region,
+			Cluster:   cluster,
+			Node:      name,
+			Phase:     DrainRunning,
+			StartTime: metav1.Now(),
+			Pods:      []response.DrainPod{},
+		},
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	d.drains[key] = current
+	go d.run(drainCtx, client, current, options)
+	return copyDrain(&current.status), nil
+}
+
+// Get returns progress of the latest drain of node
+func (d *Drains) Get(region, cluster, name string) (*response.NodeDrain, error) {
+	status, _, err := d.Watch(region, cluster, name)
+	return status, err
+}
+
+// Watch returns progress of the latest drain of node and a channel closed once the progress changes
+func (d *Drains) Watch(region, cluster, name string) (*response.NodeDrain, <-chan struct{}, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	current, ok := d.drains[drainKey(region, cluster, name)]
+	if !ok {
+		return nil, nil, newDrainNotFound(name)
+	}
+	return copyDrain(&current.status), current.changed, nil
+}
+
+// Cancel stops the running drain of node, evictions already made are not reverted and the node is
+// left unschedulable
+func (d *Drains) Cancel(region, cluster, name string) (*response.NodeDrain, error) {
+	d.lock.Lock()
+	current, ok := d.drains[drainKey(region, cluster, name)]
+	d.lock.Unlock()
+	if !ok {
+		return nil, newDrainNotFound(name)
+	}
+	current.cancel()
+	status, _, err := d.Watch(region, cluster, name)
+	return status, err
+}
+
+func copyDrain(status *response.NodeDrain) *response.NodeDrain {
+	copied := *status
+	copied.Pods = append([]response.DrainPod{}, status.Pods...)
+	return &copied
+}
+
+func newDrainNotFound(name string) error {
+	return apierrors.NewNotFound(schema.GroupResource{Resource: "nodes/drain"}, name)
+}
+
+// update modifies status of drain and notifies watchers
+func (d *Drains) update(current *drain, modify func(status *response.NodeDrain)) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	modify(&current.status)
+	close(current.changed)
+	current.changed = make(chan struct{})
+}
+
+func (d *Drains) updatePod(current *drain, index int, phase, message string) {
+	d.update(current, func(status *response.NodeDrain) {
+		pod := &status.Pods[index]
+		if phase == PodEvicted && pod.Phase != PodEvicted {
+			status.Evicted++
+		}
+		pod.Phase, pod.Message = phase, message
+	})
+}
+
+func (d *Drains) run(ctx context.Context, client kubernetes.Interface, current *drain, options DrainOptions) {
+	defer current.cancel()
+	phase, message := DrainSucceeded, ""
+	if err := d.drain(ctx, client, current, options); err != nil {
+		phase, message = DrainFailed, err.Error()
+		switch ctx.Err() {
+		case context.Canceled:
+			phase, message = DrainCancelled, "drain is cancelled"
+		case context.DeadlineExceeded:
+			message = fmt.Sprintf("drain timed out after %s", options.Timeout)
+		}
+		klog.Warningf("drain node %s of cluster %s/%s failed, %s", current.status.Node, current.status.Region, current.status.Cluster, message)
+	}
+	d.update(current, func(status *response.NodeDrain) {
+		now := metav1.Now()
+		status.Phase, status.Message, status.CompletionTime = phase, message, &now
+	})
+
+	key := drainKey(current.status.Region, current.status.Cluster, current.status.Node)
+	time.AfterFunc(d.ttl, func() {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		// the node may be drained again since
+		if d.drains[key] == current {
+			delete(d.drains, key)
+		}
+	})
+}
+
+func (d *Drains) drain(ctx context.Context, client kubernetes.Interface, current *drain, options DrainOptions) error {
+	name := current.status.Node
+	if _, err := Cordon(ctx, client, name, true); err != nil {
+		return err
+	}
+	evict, err := evictor(client)
+	if err != nil {
+		return err
+	}
+	podList, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// indexes are indexes of states of pods to be evicted
+	var pods []corev1.Pod
+	var indexes []int
+	states := []response.DrainPod{}
+	var errs []string
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != name {
+			continue
+		}
+		state := response.DrainPod{Namespace: pod.Namespace, Name: pod.Name, Phase: PodPending}
+		if skip, reason := skipPod(&pod); skip {
+			state.Phase, state.Message = PodSkipped, reason
+		} else if err := checkPod(&pod, options); err != nil {
+			state.Phase, state.Message = PodFailed, err.Error()
+			errs = append(errs, fmt.Sprintf("%s/%s %s", pod.Namespace, pod.Name, err))
+		} else {
+			pods = append(pods, pod)
+			indexes = append(indexes, len(states))
+		}
+		states = append(states, state)
+	}
+	d.update(current, func(status *response.NodeDrain) {
+		status.Pods, status.Total = states, len(pods)
+	})
+	if len(errs) != 0 {
+		return fmt.Errorf("cannot evict pods: %s", strings.Join(errs, "; "))
+	}
+
+	var wg sync.WaitGroup
+	failures := make(chan error, len(pods))
+	for i := range pods {
+		wg.Add(1)
+		go func(pod *corev1.Pod, index int) {
+			defer wg.Done()
+			if err := d.evictPod(ctx, client, current, index, pod, evict, options); err != nil {
+				failures <- err
+			}
+		}(&pods[i], indexes[i])
+	}
+	wg.Wait()
+	close(failures)
+	if failed := len(failures); failed != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%d pods failed to be evicted, %v", failed, <-failures)
+	}
+	return nil
+}
+
+// evictPod evicts pod and waits until it's deleted, evictions disallowed by PodDisruptionBudgets are retried
+func (d *Drains) evictPod(ctx context.Context, client kubernetes.Interface, current *drain, index int, pod *corev1.Pod,
+	evict evictFunc, options DrainOptions) error {
+	d.updatePod(current, index, PodEvicting, "")
+	for {
+		err := evict(ctx, pod, options.GracePeriodSeconds)
+		if err == nil || apierrors.IsNotFound(err) {
+			break
+		}
+		if !apierrors.IsTooManyRequests(err) {
+			d.updatePod(current, index, PodFailed, err.Error())
+			return err
+		}
+		d.updatePod(current, index, PodBlocked, err.Error())
+		select {
+		case <-ctx.Done():
+			d.updatePod(current, index, PodFailed, ctx.Err().Error())
+			return ctx.Err()
+		case <-time.After(evictionRetryInterval):
+		}
+	}
+
+	err := wait.PollImmediateUntil(deletionPollInterval, func() (bool, error) {
+		latest, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		// pods recreated with the same name are new ones
+		return latest.UID != pod.UID, nil
+	}, ctx.Done())
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		d.updatePod(current, index, PodFailed, err.Error())
+		return err
+	}
+	d.updatePod(current, index, PodEvicted, "")
+	return nil
+}
+
+// skipPod returns true if pod isn't evicted, mirror pods can't be evicted and pods of DaemonSets are
+// recreated on the node anyway
+func skipPod(pod *corev1.Pod) (bool, string) {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true, "mirror pod"
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return true, "managed by DaemonSet " + owner.Name
+	}
+	return false, ""
+}
+
+// checkPod returns error if pod is not allowed to be evicted by options
+func checkPod(pod *corev1.Pod, options DrainOptions) error {
+	finished := pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+	if !finished && !options.Force && metav1.GetControllerOf(pod) == nil {
+		return fmt.Errorf("not managed by controller, set force to evict it")
+	}
+	if !finished && !options.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return fmt.Errorf("uses emptyDir volume %s, set deleteEmptyDirData to evict it", volume.Name)
+			}
+		}
+	}
+	return nil
+}
+
+type evictFunc func(ctx context.Context, pod *corev1.Pod, gracePeriodSeconds *int64) error
+
+// evictor returns function evicting pods through the latest version of eviction API served
+func evictor(client kubernetes.Interface) (evictFunc, error) {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name != "pods/eviction" || resource.Kind != "Eviction" {
+			continue
+		}
+		if resource.Group == policyv1.GroupName && resource.Version == "v1" {
+			return func(ctx context.Context, pod *corev1.Pod, gracePeriodSeconds *int64) error {
+				return client.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+					ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+					DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds},
+				})
+			}, nil
+		}
+		return func(ctx context.Context, pod *corev1.Pod, gracePeriodSeconds *int64) error {
+			return client.CoreV1().Pods(pod.Namespace).EvictV1beta1(ctx, &policyv1beta1.Eviction{
+				ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+				DeleteOptions: &metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds},
+			})
+		}, nil
+	}
+	return nil, fmt.Errorf("eviction is not supported by the cluster")
+}
@@ -0,0 +1,126 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"captain/pkg/unify/response"
+)
+
+func TestDrain(t *testing.T) {
+	evictionRetryInterval, deletionPollInterval = 10*time.Millisecond, 10*time.Millisecond
+	controller := true
+	pod := func(name, owner string, annotations map[string]string, volumes ...corev1.Volume) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       corev1.PodSpec{NodeName: "node-1", Volumes: volumes},
+		}
+		if len(owner) != 0 {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name, Controller: &controller}}
+		}
+		return pod
+	}
+	newClient := func(objects ...runtime.Object) *fake.Clientset {
+		client := fake.NewSimpleClientset(append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})...)
+		client.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods/eviction", Group: "policy", Version: "v1", Kind: "Eviction"}},
+		}}
+		return client
+	}
+	wait := func(drains *Drains) *response.NodeDrain {
+		timeout := time.After(5 * time.Second)
+		for {
+			status, changed, err := drains.Watch("", "", "node-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Phase != DrainRunning {
+				return status
+			}
+			select {
+			case <-changed:
+			case <-timeout:
+				t.Fatalf("drain is not finished, %+v", status)
+			}
+		}
+	}
+
+	client := newClient(pod("web", "ReplicaSet", nil), pod("agent", "DaemonSet", nil),
+		pod("etcd", "", map[string]string{corev1.MirrorPodAnnotationKey: "etcd"}))
+	// the first eviction is disallowed by PodDisruptionBudget
+	evictions := 0
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if evictions++; evictions == 1 {
+			return true, nil, apierrors.NewTooManyRequests("disruption budget web needs 1 healthy pods", 10)
+		}
+		return true, nil, client.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), "default", "web")
+	})
+
+	drains := NewDrains()
+	ctx := context.Background()
+	if _, err := drains.Start(ctx, client, "", "", "node-1", DrainOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drains.Start(ctx, client, "", "", "node-1", DrainOptions{}); !apierrors.IsConflict(err) {
+		t.Errorf("expected conflict of draining node being drained, got %v", err)
+	}
+	status := wait(drains)
+	if status.Phase != DrainSucceeded || status.Total != 1 || status.Evicted != 1 || evictions != 2 || len(status.Pods) != 3 {
+		t.Fatalf("unexpected drain %+v", status)
+	}
+	for _, pod := range status.Pods {
+		if expected := map[string]string{"web": PodEvicted, "agent": PodSkipped, "etcd": PodSkipped}[pod.Name]; pod.Phase != expected {
+			t.Errorf("expected pod %s %s, got %s", pod.Name, expected, pod.Phase)
+		}
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !node.Spec.Unschedulable {
+		t.Errorf("expected node cordoned after drain")
+	}
+
+	// pods not managed by controllers and pods using emptyDir are not evicted without force and deleteEmptyDirData
+	client = newClient(pod("standalone", "", nil),
+		pod("cache", "ReplicaSet", nil, corev1.Volume{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}))
+	drains = NewDrains()
+	if _, err := drains.Start(ctx, client, "", "", "node-1", DrainOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(drains); status.Phase != DrainFailed || status.Pods[0].Phase != PodFailed || status.Pods[1].Phase != PodFailed {
+		t.Errorf("unexpected drain %+v", status)
+	}
+
+	if _, err := drains.Cancel("", "", "node-2"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found of cancelling unknown drain, got %v", err)
+	}
+
+	// finished drains are forgotten after the TTL
+	finishedDrainTTL = 10 * time.Millisecond
+	drains = NewDrains()
+	if _, err := drains.Start(ctx, newClient(), "", "", "node-1", DrainOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		_, err := drains.Get("", "", "node-1")
+		if apierrors.IsNotFound(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected finished drain forgotten, got %v", err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcNodeProvider struct {
 	clusterclient.ClusterClients
 }
 
+var nodesGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcNodeProvider {
 	return mcNodeProvider{ClusterClients: clients}
 }
 
 func (pd mcNodeProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, nodesGVR); ok {
+		return lister.Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcNodeProvider) Get(region, cluster, namespace, name string) (runtime.O
 }
 
 func (pd mcNodeProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, nodesGVR, "", query, Compare, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcNodeProvider) List(region, cluster, namespace string, query *query.Qu
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
@@ -5,14 +5,31 @@ import (
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of nodes in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Ready", ".status.conditions[?(@.type==\"Ready\")].status", 0),
+	alpha1.Column("Unschedulable", ".spec.unschedulable", 0),
+	alpha1.AgeColumn,
+	alpha1.Column("Version", ".status.nodeInfo.kubeletVersion", 0),
+	alpha1.Column("Internal-IP", ".status.addresses[?(@.type==\"InternalIP\")].address", 1),
+	alpha1.Column("OS-Image", ".status.nodeInfo.osImage", 1),
+	alpha1.Column("Container-Runtime", ".status.nodeInfo.containerRuntimeVersion", 1),
+}
+
+// SortKeys are keys nodes are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldUpdateTime, query.FieldStatus)
+
 type nodeProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -23,6 +40,8 @@ const (
 	StatusUnschedulable                      = "unschedulable"
 	StatusWarning                            = "warning"
 	StatusRunning                            = "running"
+
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
 )
 
 func New(informer informers.SharedInformerFactory) nodeProvider {
@@ -45,7 +64,7 @@ func (nd nodeProvider) List(node string, query *query.QueryInfo) (*response.List
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -63,13 +82,39 @@ func filter(object runtime.Object, filter query.Filter) bool {
 			return true
 		}
 	case query.FieldStatus:
-		return strings.Compare(getNodeStatus(node), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 	default:
 		return alpha1.DefaultObjectMetaFilter(node.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of node to group by, role of node with multiple roles is
+// the sorted roles joined by commas
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	node, ok := object.(*v1.Node)
+	if !ok {
+		return "", false
+	}
+
+	switch field {
+	case query.FieldRole:
+		var roles []string
+		for key := range node.Labels {
+			if strings.HasPrefix(key, nodeRoleLabelPrefix) {
+				roles = append(roles, strings.TrimPrefix(key, nodeRoleLabelPrefix))
+			}
+		}
+		sort.Strings(roles)
+		return strings.Join(roles, ","), true
+	case query.FieldStatus:
+		return getNodeStatus(node), true
+	default:
+		return "", false
+	}
+}
+
+// Compare returns true if node left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftND, ok := left.(*v1.Node)
 	if !ok {
@@ -84,6 +129,8 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		fallthrough
 	case query.FieldLastUpdateTimestamp:
 		return lastUpdateTime(leftND).After(lastUpdateTime(rightND))
+	case query.FieldStatus:
+		return strings.Compare(getNodeStatus(leftND), getNodeStatus(rightND)) < 0
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftND.ObjectMeta, rightND.ObjectMeta, field)
 	}
@@ -130,3 +177,11 @@ func getNodeStatus(node *v1.Node) string {
 
 	return StatusRunning
 }
+
+func (nd nodeProvider) Informer() cache.SharedIndexInformer {
+	return nd.informers.Core().V1().Nodes().Informer()
+}
+
+func (nd nodeProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
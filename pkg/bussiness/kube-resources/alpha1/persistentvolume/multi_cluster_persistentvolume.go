@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcPersistentVolumeProvider struct {
 	clusterclient.ClusterClients
 }
 
+var persistentvolumesGVR = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcPersistentVolumeProvider {
 	return mcPersistentVolumeProvider{ClusterClients: clients}
 }
 
 func (pd mcPersistentVolumeProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, persistentvolumesGVR); ok {
+		return lister.Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcPersistentVolumeProvider) Get(region, cluster, namespace, name string
 }
 
 func (pd mcPersistentVolumeProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, persistentvolumesGVR, "", query, Compare, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcPersistentVolumeProvider) List(region, cluster, namespace string, que
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
@@ -7,13 +7,30 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 	"strings"
 )
 
 const (
 	storageClassName = "storageClassName"
+	fieldCapacity    = "capacity"
 )
 
+// TableColumns are default columns of persistent volumes in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Capacity", ".spec.capacity.storage", 0),
+	alpha1.Column("Access Modes", ".spec.accessModes[*]", 0),
+	alpha1.Column("Reclaim Policy", ".spec.persistentVolumeReclaimPolicy", 0),
+	alpha1.Column("Status", ".status.phase", 0),
+	alpha1.Column("Claim", ".spec.claimRef.name", 0),
+	alpha1.Column("StorageClass", ".spec.storageClassName", 0),
+	alpha1.AgeColumn,
+}
+
+// SortKeys are keys persistent volumes are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldStatus, storageClassName, fieldCapacity)
+
 type persistentvolumeProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -38,7 +55,7 @@ func (pv persistentvolumeProvider) List(namespace string, query *query.QueryInfo
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -48,17 +65,33 @@ func filter(object runtime.Object, filter query.Filter) bool {
 	}
 
 	switch filter.Field {
-	case query.FieldStatus:
-		return strings.EqualFold(string(persistentVolume.Status.Phase), string(filter.Value))
-	case storageClassName:
-		return persistentVolume.Spec.StorageClassName != "" && persistentVolume.Spec.StorageClassName == string(filter.Value)
+	case query.FieldStatus, storageClassName:
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 
 	default:
 		return alpha1.DefaultObjectMetaFilter(persistentVolume.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of persistent volume to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	persistentVolume, ok := object.(*corev1.PersistentVolume)
+	if !ok {
+		return "", false
+	}
+
+	switch field {
+	case query.FieldStatus:
+		return string(persistentVolume.Status.Phase), true
+	case storageClassName:
+		return persistentVolume.Spec.StorageClassName, true
+	default:
+		return "", false
+	}
+}
+
+// Compare returns true if persistent volume left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	pv1, ok := left.(*corev1.PersistentVolume)
 	if !ok {
@@ -68,6 +101,24 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 	if !ok {
 		return false
 	}
-	return alpha1.DefaultObjectMetaCompare(pv1.ObjectMeta, pv2.ObjectMeta, field)
+	switch field {
+	case query.FieldStatus:
+		return strings.Compare(string(pv1.Status.Phase), string(pv2.Status.Phase)) < 0
+	case storageClassName:
+		return strings.Compare(pv1.Spec.StorageClassName, pv2.Spec.StorageClassName) < 0
+	case fieldCapacity:
+		capacity1, capacity2 := pv1.Spec.Capacity[corev1.ResourceStorage], pv2.Spec.Capacity[corev1.ResourceStorage]
+		return capacity1.Cmp(capacity2) < 0
+	default:
+		return alpha1.DefaultObjectMetaCompare(pv1.ObjectMeta, pv2.ObjectMeta, field)
+	}
+
+}
+
+func (pv persistentvolumeProvider) Informer() cache.SharedIndexInformer {
+	return pv.informers.Core().V1().PersistentVolumes().Informer()
+}
 
+func (pv persistentvolumeProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
 }
@@ -9,6 +9,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
@@ -21,6 +22,8 @@ type mcPersistentVolumeClaimProvider struct {
 	clusterclient.ClusterClients
 }
 
+var persistentvolumeclaimsGVR = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcPersistentVolumeClaimProvider {
 	return mcPersistentVolumeClaimProvider{ClusterClients: clients}
 }
@@ -31,14 +34,20 @@ func (pd mcPersistentVolumeClaimProvider) Get(region, cluster, namespace, name s
 		return nil, err
 	}
 
-	pvc, err := cli.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	var pvc *v1.PersistentVolumeClaim
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, persistentvolumeclaimsGVR); ok {
+		var obj runtime.Object
+		if obj, err = lister.ByNamespace(namespace).Get(name); err == nil {
+			// we should never mutate the shared objects from informers
+			pvc = obj.(*v1.PersistentVolumeClaim).DeepCopy()
+		}
+	} else {
+		pvc, err = cli.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// we should never mutate the shared objects from informers
-	pvc = pvc.DeepCopy()
-
 	helper := &pvcHelper{Clientset: cli}
 	helper.annotatePVC(pvc)
 
@@ -50,23 +59,27 @@ func (pd mcPersistentVolumeClaimProvider) List(region, cluster, namespace string
 	if err != nil {
 		return nil, err
 	}
+
+	// objects transformed are copies, the shared objects from informers are never mutated
+	annotate := func(obj runtime.Object) runtime.Object {
+		helper := &pvcHelper{Clientset: cli}
+		helper.annotatePVC(obj.(*v1.PersistentVolumeClaim))
+		return obj
+	}
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, persistentvolumeclaimsGVR, namespace, query, Compare, filter, annotate); ok {
+		return result, err
+	}
+
 	list, err := cli.CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
 	if err != nil {
 		return nil, err
 	}
-
 	var result []runtime.Object
-	if list != nil && list.Items != nil {
-		for i := 0; i < len(list.Items); i++ {
-			pvc := &list.Items[i]
-			pvc = pvc.DeepCopy()
-			helper := &pvcHelper{Clientset: cli}
-			helper.annotatePVC(pvc)
-			result = append(result, &list.Items[i])
-		}
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, &list.Items[i])
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter, annotate)
 }
 
 type pvcHelper struct {
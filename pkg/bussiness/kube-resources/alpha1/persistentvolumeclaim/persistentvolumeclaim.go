@@ -12,16 +12,32 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
 	storageClassName = "storageClassName"
+	fieldCapacity    = "capacity"
 
 	annotationInUse              = "captain.io/in-use"
 	annotationAllowSnapshot      = "captain.io/allow-snapshot"
 	annotationStorageProvisioner = "volume.beta.kubernetes.io/storage-provisioner"
 )
 
+// TableColumns are default columns of persistent volume claims in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Status", ".status.phase", 0),
+	alpha1.Column("Volume", ".spec.volumeName", 0),
+	alpha1.Column("Capacity", ".status.capacity.storage", 0),
+	alpha1.Column("Access Modes", ".status.accessModes[*]", 0),
+	alpha1.Column("StorageClass", ".spec.storageClassName", 0),
+	alpha1.AgeColumn,
+}
+
+// SortKeys are keys persistent volume claims are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldStatus, storageClassName, fieldCapacity)
+
 type persistentvolumeclaimProvider struct {
 	sharedInformers   informers.SharedInformerFactory
 	snapshotInformers snapshotinformers.SharedInformerFactory
@@ -54,7 +70,7 @@ func (p persistentvolumeclaimProvider) List(namespace string, query *query.Query
 		p.annotatePVC(pvc)
 		result = append(result, pvc)
 	}
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -64,16 +80,32 @@ func filter(object runtime.Object, filter query.Filter) bool {
 	}
 
 	switch filter.Field {
+	case query.FieldStatus, storageClassName:
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
+	default:
+		return alpha1.DefaultObjectMetaFilter(pvc.ObjectMeta, filter)
+	}
+}
+
+// FieldValue returns value of field of persistent volume claim to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	pvc, ok := object.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return "", false
+	}
+
+	switch field {
 	case query.FieldStatus:
-		return strings.EqualFold(string(pvc.Status.Phase), string(filter.Value))
+		return string(pvc.Status.Phase), true
 	case storageClassName:
-		return pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == string(filter.Value)
+		return claimStorageClassName(pvc), true
 	default:
-		return alpha1.DefaultObjectMetaFilter(pvc.ObjectMeta, filter)
+		return "", false
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// Compare returns true if persistent volume claim left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftClaim, ok := left.(*v1.PersistentVolumeClaim)
 	if !ok {
@@ -83,7 +115,25 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 	if !ok {
 		return false
 	}
-	return alpha1.DefaultObjectMetaCompare(leftClaim.ObjectMeta, rightClaim.ObjectMeta, field)
+	switch field {
+	case query.FieldStatus:
+		return strings.Compare(string(leftClaim.Status.Phase), string(rightClaim.Status.Phase)) < 0
+	case storageClassName:
+		return strings.Compare(claimStorageClassName(leftClaim), claimStorageClassName(rightClaim)) < 0
+	case fieldCapacity:
+		leftCapacity, rightCapacity := leftClaim.Status.Capacity[v1.ResourceStorage], rightClaim.Status.Capacity[v1.ResourceStorage]
+		return leftCapacity.Cmp(rightCapacity) < 0
+	default:
+		return alpha1.DefaultObjectMetaCompare(leftClaim.ObjectMeta, rightClaim.ObjectMeta, field)
+	}
+}
+
+// claimStorageClassName returns storage class of claim, empty if the default class is used
+func claimStorageClassName(pvc *v1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
 }
 
 func (p *persistentvolumeclaimProvider) annotatePVC(pvc *v1.PersistentVolumeClaim) {
@@ -126,3 +176,11 @@ func (p *persistentvolumeclaimProvider) isSnapshotAllowed(provisioner string) bo
 	}
 	return false
 }
+
+func (p persistentvolumeclaimProvider) Informer() cache.SharedIndexInformer {
+	return p.sharedInformers.Core().V1().PersistentVolumeClaims().Informer()
+}
+
+func (p persistentvolumeclaimProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -0,0 +1,222 @@
+package pod
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// MaxLogStreams is the max number of containers whose logs are merged in a request
+const MaxLogStreams = 50
+
+// workloadKinds are kinds of workloads owning pods, keyed by resource names
+var workloadKinds = map[string]string{
+	"deployments":  "Deployment",
+	"statefulsets": "StatefulSet",
+	"daemonsets":   "DaemonSet",
+	"replicasets":  "ReplicaSet",
+	"jobs":         "Job",
+}
+
+// WorkloadKind returns kind of workload resource, ok is false if pods of the resource are unknown
+func WorkloadKind(resource string) (kind string, ok bool) {
+	kind, ok = workloadKinds[resource]
+	return kind, ok
+}
+
+// logStream is log stream of a container, prefix is prepended to each line when streams are merged
+type logStream struct {
+	prefix string
+	reader io.ReadCloser
+	err    error
+}
+
+// LogStreams are opened log streams of containers, logs are copied as is if there is only one stream,
+// or merged line by line with lines prefixed by pod/container or container otherwise
+type LogStreams struct {
+	streams []logStream
+	follow  bool
+}
+
+// OpenLogs opens log streams of pod, logs of all containers are merged if container is not specified
+// in options and the pod has more than one container
+func OpenLogs(ctx context.Context, client kubernetes.Interface, namespace, name string, options *v1.PodLogOptions) (*LogStreams, error) {
+	if len(options.Container) != 0 {
+		reader, err := client.CoreV1().Pods(namespace).GetLogs(name, options).Stream(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &LogStreams{streams: []logStream{{reader: reader}}, follow: options.Follow}, nil
+	}
+
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	streams := &LogStreams{follow: options.Follow}
+	for _, container := range pod.Spec.Containers {
+		prefix := ""
+		if len(pod.Spec.Containers) > 1 {
+			prefix = fmt.Sprintf("[%s] ", container.Name)
+		}
+		streams.open(ctx, client, pod, container.Name, prefix, options)
+	}
+	return streams, streams.err()
+}
+
+// OpenWorkloadLogs opens log streams of all containers of pods owned by workload of kind, only containers
+// named after container of options are streamed if it is specified
+func OpenWorkloadLogs(ctx context.Context, client kubernetes.Interface, namespace, kind, name string, options *v1.PodLogOptions) (*LogStreams, error) {
+	list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podCli := PodProviderClient{Interface: client}
+	var pods []*v1.Pod
+	containers := 0
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if !podCli.podBelongTo(pod, kind, name) {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if len(options.Container) == 0 || container.Name == options.Container {
+				containers++
+			}
+		}
+		pods = append(pods, pod)
+	}
+	if containers == 0 {
+		return nil, apierrors.NewNotFound(v1.Resource("pods"), fmt.Sprintf("pods of %s %s", kind, name))
+	}
+	if containers > MaxLogStreams {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("%s %s has %d containers, logs of at most %d containers are merged, "+
+			"specify container to narrow down", kind, name, containers, MaxLogStreams))
+	}
+
+	streams := &LogStreams{follow: options.Follow}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if len(options.Container) == 0 || container.Name == options.Container {
+				streams.open(ctx, client, pod, container.Name, fmt.Sprintf("[%s/%s] ", pod.Name, container.Name), options)
+			}
+		}
+	}
+	return streams, streams.err()
+}
+
+func (s *LogStreams) open(ctx context.Context, client kubernetes.Interface, pod *v1.Pod, container, prefix string, options *v1.PodLogOptions) {
+	containerOptions := options.DeepCopy()
+	containerOptions.Container = container
+	reader, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, containerOptions).Stream(ctx)
+	s.streams = append(s.streams, logStream{prefix: prefix, reader: reader, err: err})
+}
+
+// err returns error of the first stream if no stream is opened, failures of some streams are written
+// as lines of logs instead
+func (s *LogStreams) err() error {
+	for _, stream := range s.streams {
+		if stream.err == nil {
+			return nil
+		}
+	}
+	if len(s.streams) == 0 {
+		return nil
+	}
+	return s.streams[0].err
+}
+
+// CopyTo copies logs to w until all streams end, streams are closed when it returns. Streams are
+// copied one by one unless they are followed, in which case lines are merged as they come.
+func (s *LogStreams) CopyTo(w io.Writer) error {
+	defer s.Close()
+
+	if len(s.streams) == 1 && len(s.streams[0].prefix) == 0 {
+		_, err := io.Copy(w, s.streams[0].reader)
+		return err
+	}
+
+	writer := &prefixWriter{w: w}
+	if !s.follow {
+		for _, stream := range s.streams {
+			if err := writer.copy(stream); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(s.streams))
+	for _, stream := range s.streams {
+		wg.Add(1)
+		go func(stream logStream) {
+			defer wg.Done()
+			if err := writer.copy(stream); err != nil {
+				errs <- err
+				// the client is gone, stop other streams
+				s.Close()
+			}
+		}(stream)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// Close closes all streams
+func (s *LogStreams) Close() {
+	for _, stream := range s.streams {
+		if stream.reader != nil {
+			stream.reader.Close()
+		}
+	}
+}
+
+// prefixWriter writes lines of streams with their prefixes, lines of different streams are never interleaved
+type prefixWriter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// copy copies lines of stream, an error is returned only if lines can't be written
+func (p *prefixWriter) copy(stream logStream) error {
+	if stream.err != nil {
+		return p.writeLine(stream.prefix, []byte(fmt.Sprintf("failed to get logs, %v\n", stream.err)))
+	}
+
+	reader := bufio.NewReader(stream.reader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			if err := p.writeLine(stream.prefix, line); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				klog.V(4).Infof("read logs %s failed, %v", stream.prefix, err)
+			}
+			return nil
+		}
+	}
+}
+
+func (p *prefixWriter) writeLine(prefix string, line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(append([]byte(prefix), line...))
+	return err
+}
@@ -0,0 +1,110 @@
+package pod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogs(t *testing.T) {
+	newPod := func(name, ownerKind, owner string, containers ...string) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: owner}},
+		}}
+		for _, container := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: container})
+		}
+		return pod
+	}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d4f",
+		Namespace:       "default",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+	}}
+	client := fake.NewSimpleClientset(
+		replicaSet,
+		newPod("web-5d4f-a", "ReplicaSet", "web-5d4f", "app", "sidecar"),
+		newPod("web-5d4f-b", "ReplicaSet", "web-5d4f", "app", "sidecar"),
+		newPod("db-0", "StatefulSet", "db", "mysql"),
+	)
+
+	logs := func(streams *LogStreams, err error) string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := streams.CopyTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	// the fake client responds "fake logs" for each container
+	tests := []struct {
+		streams  func() (*LogStreams, error)
+		expected string
+	}{
+		{
+			streams: func() (*LogStreams, error) {
+				return OpenLogs(context.Background(), client, "default", "db-0", &v1.PodLogOptions{})
+			},
+			expected: "fake logs",
+		},
+		{
+			streams: func() (*LogStreams, error) {
+				return OpenLogs(context.Background(), client, "default", "web-5d4f-a", &v1.PodLogOptions{})
+			},
+			expected: "[app] fake logs\n[sidecar] fake logs\n",
+		},
+		{
+			streams: func() (*LogStreams, error) {
+				return OpenWorkloadLogs(context.Background(), client, "default", "Deployment", "web", &v1.PodLogOptions{Container: "app"})
+			},
+			expected: "[web-5d4f-a/app] fake logs\n[web-5d4f-b/app] fake logs\n",
+		},
+		{
+			streams: func() (*LogStreams, error) {
+				return OpenWorkloadLogs(context.Background(), client, "default", "StatefulSet", "db", &v1.PodLogOptions{})
+			},
+			expected: "[db-0/mysql] fake logs\n",
+		},
+	}
+	for i, test := range tests {
+		if got := logs(test.streams()); got != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, got)
+		}
+	}
+
+	// lines are never interleaved when followed logs are merged
+	streams, err := OpenWorkloadLogs(context.Background(), client, "default", "Deployment", "web", &v1.PodLogOptions{Follow: true})
+	lines := strings.Split(strings.TrimSuffix(logs(streams, err), "\n"), "\n")
+	sort.Strings(lines)
+	expected := []string{"[web-5d4f-a/app] fake logs", "[web-5d4f-a/sidecar] fake logs", "[web-5d4f-b/app] fake logs", "[web-5d4f-b/sidecar] fake logs"}
+	if fmt.Sprint(lines) != fmt.Sprint(expected) {
+		t.Errorf("expected followed logs %q, got %q", expected, lines)
+	}
+
+	if _, err := OpenWorkloadLogs(context.Background(), client, "default", "Deployment", "api", &v1.PodLogOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	var objects []runtime.Object
+	for i := 0; i <= MaxLogStreams; i++ {
+		objects = append(objects, newPod(fmt.Sprintf("job-%d", i), "Job", "batch", "main"))
+	}
+	if _, err := OpenWorkloadLogs(context.Background(), fake.NewSimpleClientset(objects...), "default", "Job", "batch", &v1.PodLogOptions{}); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request, got %v", err)
+	}
+}
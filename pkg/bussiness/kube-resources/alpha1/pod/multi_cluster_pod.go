@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
@@ -21,11 +22,17 @@ type mcPodProvider struct {
 	clusterclient.ClusterClients
 }
 
+var podsGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcPodProvider {
 	return mcPodProvider{ClusterClients: clients}
 }
 
 func (pd mcPodProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, podsGVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -39,6 +46,13 @@ func (pd mcPodProvider) List(region, cluster, namespace string, query *query.Que
 	if err != nil {
 		return nil, err
 	}
+	podCli := PodProviderClient{Interface: cli}
+
+	statuses := podStatuses{}
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, podsGVR, namespace, query, statuses.compareFunc, statuses.filterFunc(podCli.filter)); ok {
+		return result, err
+	}
+
 	list, err := cli.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: query.LabelSelector})
 	if err != nil {
 		return nil, err
@@ -51,12 +65,11 @@ func (pd mcPodProvider) List(region, cluster, namespace string, query *query.Que
 		}
 	}
 
-	podCli := PodProviderClient{Clientset: cli}
-	return alpha1.DefaultList(result, query, compareFunc, podCli.filter), nil
+	return alpha1.DefaultList(result, query, statuses.compareFunc, statuses.filterFunc(podCli.filter))
 }
 
 type PodProviderClient struct {
-	*kubernetes.Clientset
+	kubernetes.Interface
 	replicaSets *appv1.ReplicaSetList
 	service     *v1.Service
 }
@@ -82,8 +95,6 @@ func (c *PodProviderClient) filter(object runtime.Object, filter query.Filter) b
 		return podBindPVC(pod, string(filter.Value))
 	case fieldServiceName:
 		return c.podBelongToService(pod, string(filter.Value))
-	case query.FieldStatus:
-		return strings.Compare(string(pod.Status.Phase), string(filter.Value)) == 0
 	default:
 		return alpha1.DefaultObjectMetaFilter(pod.ObjectMeta, filter)
 	}
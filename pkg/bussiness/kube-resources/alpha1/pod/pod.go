@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
@@ -19,6 +20,32 @@ const (
 	fieldServiceName = "serviceName"
 )
 
+// TableColumns are default columns of pods in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.ComputedColumn("Ready", "string", 0, func(object runtime.Object) (interface{}, bool) {
+		status, ok := FieldValue(object, fieldReady)
+		return status, ok
+	}),
+	alpha1.ComputedColumn("Status", "string", 0, func(object runtime.Object) (interface{}, bool) {
+		status, ok := FieldValue(object, query.FieldStatus)
+		return status, ok
+	}),
+	alpha1.ComputedColumn("Restarts", "integer", 0, func(object runtime.Object) (interface{}, bool) {
+		pod, ok := object.(*v1.Pod)
+		if !ok {
+			return nil, false
+		}
+		return computeStatus(pod).Restarts, true
+	}),
+	alpha1.AgeColumn,
+	alpha1.Column("IP", ".status.podIP", 1),
+	alpha1.Column("Node", ".spec.nodeName", 1),
+}
+
+// SortKeys are keys pods are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldStartTime, fieldNodeName, query.FieldStatus, fieldPhase, fieldRestarts, fieldReady)
+
 type podProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -42,7 +69,8 @@ func (pd podProvider) List(namespace string, query *query.QueryInfo) (*response.
 		result = append(result, pod)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, pd.filter), nil
+	statuses := podStatuses{}
+	return alpha1.DefaultList(result, query, statuses.compareFunc, statuses.filterFunc(pd.filter))
 }
 
 func (pd *podProvider) filter(object runtime.Object, filter query.Filter) bool {
@@ -59,19 +87,35 @@ func (pd *podProvider) filter(object runtime.Object, filter query.Filter) bool {
 		kind := kn[0]
 		name := kn[1]
 		return pd.podBelongTo(pod, kind, name)
-	case fieldNodeName:
-		return pod.Spec.NodeName == string(filter.Value)
 	case fieldPVCName:
 		return podBindPVC(pod, string(filter.Value))
 	case fieldServiceName:
 		return pd.podBelongToService(pod, string(filter.Value))
-	case query.FieldStatus:
-		return strings.Compare(string(pod.Status.Phase), string(filter.Value)) == 0
 	default:
 		return alpha1.DefaultObjectMetaFilter(pod.ObjectMeta, filter)
 	}
 }
 
+// FieldValue returns value of field of pod to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	return make(podStatuses).fieldValue(object, field)
+}
+
+// Requests returns resource requests of pod, pods terminated don't request resources any more
+func Requests(object runtime.Object) (v1.ResourceList, bool) {
+	pod, ok := object.(*v1.Pod)
+	if !ok || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return nil, false
+	}
+	return alpha1.PodRequests(&pod.Spec), true
+}
+
+// Compare returns true if pod left is less than right by field, including fields computed from
+// states of containers
+func Compare(left, right runtime.Object, field query.Field) bool {
+	return make(podStatuses).compareFunc(left, right, field)
+}
+
 func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	leftPod, ok := left.(*v1.Pod)
@@ -90,10 +134,12 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		if rightPod.Status.StartTime == nil {
 			return true
 		}
+		return leftPod.Status.StartTime.Before(rightPod.Status.StartTime)
+	case fieldNodeName:
+		return strings.Compare(leftPod.Spec.NodeName, rightPod.Spec.NodeName) < 0
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftPod.ObjectMeta, rightPod.ObjectMeta, field)
 	}
-	return false
 }
 func (pd *podProvider) podBelongTo(item *v1.Pod, kind string, name string) bool {
 	switch kind {
@@ -203,3 +249,12 @@ func (pd *podProvider) podBelongToService(item *v1.Pod, serviceName string) bool
 	}
 	return true
 }
+
+func (pd podProvider) Informer() cache.SharedIndexInformer {
+	return pd.sharedInformers.Core().V1().Pods().Informer()
+}
+
+// Match matches pod with query the same as List does, computed fields like status included
+func (pd podProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, podStatuses{}.filterFunc(pd.filter))
+}
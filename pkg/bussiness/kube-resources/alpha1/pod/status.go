@@ -0,0 +1,239 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+)
+
+const (
+	// fieldPhase is phase of pods, status is the STATUS column of kubectl get pods
+	fieldPhase    = "phase"
+	fieldRestarts = "restarts"
+	fieldReady    = "ready"
+
+	// nodeUnreachablePodReason is reason of pods on nodes lost, the same as node controller sets
+	nodeUnreachablePodReason = "NodeLost"
+)
+
+// podStatus is status of pod shown by kubectl get pods, derived from phase and states of containers
+type podStatus struct {
+	// Status is the STATUS column, e.g. Running, CrashLoopBackOff, Init:0/1 and Terminating
+	Status string
+	// Restarts is the sum of restarts of containers, restarts of init containers are counted
+	// while the pod is initializing
+	Restarts int32
+	// Ready and Total are numbers of ready containers and all containers
+	Ready, Total int
+}
+
+// readiness returns readiness of containers as kubectl shows, e.g. 1/2
+func (s podStatus) readiness() string {
+	return fmt.Sprintf("%d/%d", s.Ready, s.Total)
+}
+
+// computeStatus computes status of pod the same as kubectl printPod does
+func computeStatus(pod *v1.Pod) podStatus {
+	status := podStatus{Status: string(pod.Status.Phase), Total: len(pod.Spec.Containers)}
+	if len(pod.Status.Reason) != 0 {
+		status.Status = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, container := range pod.Status.InitContainerStatuses {
+		status.Restarts += container.RestartCount
+		switch {
+		case container.State.Terminated != nil && container.State.Terminated.ExitCode == 0:
+			continue
+		case container.State.Terminated != nil:
+			// initialization failed
+			if len(container.State.Terminated.Reason) != 0 {
+				status.Status = "Init:" + container.State.Terminated.Reason
+			} else if container.State.Terminated.Signal != 0 {
+				status.Status = fmt.Sprintf("Init:Signal:%d", container.State.Terminated.Signal)
+			} else {
+				status.Status = fmt.Sprintf("Init:ExitCode:%d", container.State.Terminated.ExitCode)
+			}
+		case container.State.Waiting != nil && len(container.State.Waiting.Reason) != 0 && container.State.Waiting.Reason != "PodInitializing":
+			status.Status = "Init:" + container.State.Waiting.Reason
+		default:
+			status.Status = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		status.Restarts = 0
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			container := pod.Status.ContainerStatuses[i]
+			status.Restarts += container.RestartCount
+			switch {
+			case container.State.Waiting != nil && len(container.State.Waiting.Reason) != 0:
+				status.Status = container.State.Waiting.Reason
+			case container.State.Terminated != nil && len(container.State.Terminated.Reason) != 0:
+				status.Status = container.State.Terminated.Reason
+			case container.State.Terminated != nil && container.State.Terminated.Signal != 0:
+				status.Status = fmt.Sprintf("Signal:%d", container.State.Terminated.Signal)
+			case container.State.Terminated != nil:
+				status.Status = fmt.Sprintf("ExitCode:%d", container.State.Terminated.ExitCode)
+			case container.Ready && container.State.Running != nil:
+				hasRunning = true
+				status.Ready++
+			}
+		}
+
+		// a pod is still running if any container is running after others completed
+		if status.Status == "Completed" && hasRunning {
+			status.Status = "NotReady"
+			if podReady(pod) {
+				status.Status = string(v1.PodRunning)
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil && pod.Status.Reason == nodeUnreachablePodReason {
+		status.Status = "Unknown"
+	} else if pod.DeletionTimestamp != nil {
+		status.Status = "Terminating"
+	}
+	return status
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// podStatuses caches statuses of pods computed in a list request, so that statuses are computed
+// once no matter how many times pods are filtered and compared
+type podStatuses map[*v1.Pod]podStatus
+
+func (s podStatuses) get(pod *v1.Pod) podStatus {
+	status, ok := s[pod]
+	if !ok {
+		status = computeStatus(pod)
+		s[pod] = status
+	}
+	return status
+}
+
+// fieldValue returns value of field of pod, which pods are filtered and grouped by
+func (s podStatuses) fieldValue(object runtime.Object, field query.Field) (string, bool) {
+	pod, ok := object.(*v1.Pod)
+	if !ok {
+		return "", false
+	}
+
+	switch field {
+	case query.FieldStatus:
+		return s.get(pod).Status, true
+	case fieldPhase:
+		return string(pod.Status.Phase), true
+	case fieldRestarts:
+		return fmt.Sprint(s.get(pod).Restarts), true
+	case fieldReady:
+		return s.get(pod).readiness(), true
+	case fieldNodeName:
+		return pod.Spec.NodeName, true
+	default:
+		return "", false
+	}
+}
+
+// filterFunc filters pods by computed fields, other fields are filtered by next
+func (s podStatuses) filterFunc(next alpha1.FilterFunc) alpha1.FilterFunc {
+	return func(object runtime.Object, filter query.Filter) bool {
+		pod, ok := object.(*v1.Pod)
+		if !ok {
+			return false
+		}
+		switch filter.Field {
+		case query.FieldStatus, fieldPhase, fieldNodeName:
+			return alpha1.MatchFieldValue(s.fieldValue, object, filter)
+		case fieldRestarts:
+			return matchCount(int64(s.get(pod).Restarts), string(filter.Value))
+		case fieldReady:
+			return matchReady(s.get(pod), string(filter.Value))
+		default:
+			return next(object, filter)
+		}
+	}
+}
+
+// compareFunc compares pods by computed fields, other fields are compared by compareFunc of pods
+func (s podStatuses) compareFunc(left, right runtime.Object, field query.Field) bool {
+	leftPod, ok := left.(*v1.Pod)
+	if !ok {
+		return false
+	}
+	rightPod, ok := right.(*v1.Pod)
+	if !ok {
+		return false
+	}
+	switch field {
+	case query.FieldStatus:
+		return strings.Compare(s.get(leftPod).Status, s.get(rightPod).Status) < 0
+	case fieldPhase:
+		return strings.Compare(string(leftPod.Status.Phase), string(rightPod.Status.Phase)) < 0
+	case fieldRestarts:
+		return s.get(leftPod).Restarts < s.get(rightPod).Restarts
+	case fieldReady:
+		// ordered by ratio of ready containers, then by number of ready containers
+		l, r := s.get(leftPod), s.get(rightPod)
+		if lr, rr := l.Ready*r.Total, r.Ready*l.Total; lr != rr {
+			return lr < rr
+		}
+		return l.Ready < r.Ready
+	default:
+		return compareFunc(left, right, field)
+	}
+}
+
+// matchCount returns true if count matches value, which is a number optionally prefixed by one
+// of >, >=, < and <=, e.g. >3
+func matchCount(count int64, value string) bool {
+	operator := strings.TrimRight(value, "0123456789")
+	n, err := strconv.ParseInt(strings.TrimPrefix(value, operator), 10, 64)
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case "":
+		return count == n
+	case ">":
+		return count > n
+	case ">=":
+		return count >= n
+	case "<":
+		return count < n
+	case "<=":
+		return count <= n
+	default:
+		return false
+	}
+}
+
+// matchReady returns true if value is true and all containers are ready, false and any container
+// is not ready, or value is readiness such as 1/2
+func matchReady(status podStatus, value string) bool {
+	switch strings.ToLower(value) {
+	case "true":
+		return status.Total != 0 && status.Ready == status.Total
+	case "false":
+		return status.Ready != status.Total
+	default:
+		return status.readiness() == value
+	}
+}
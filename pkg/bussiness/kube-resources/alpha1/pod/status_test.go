@@ -0,0 +1,121 @@
+package pod
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+)
+
+func TestComputeStatus(t *testing.T) {
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	waiting := func(reason string) v1.ContainerState {
+		return v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}}
+	}
+	terminated := func(reason string, exitCode int32) v1.ContainerState {
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}}
+	}
+	newPod := func(name string, init []v1.ContainerStatus, containers ...v1.ContainerStatus) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, InitContainerStatuses: init, ContainerStatuses: containers},
+		}
+		for range init {
+			pod.Spec.InitContainers = append(pod.Spec.InitContainers, v1.Container{})
+		}
+		for range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{})
+		}
+		return pod
+	}
+
+	terminating := newPod("terminating", nil, v1.ContainerStatus{State: running, Ready: true})
+	terminating.DeletionTimestamp = &metav1.Time{}
+	evicted := newPod("evicted", nil)
+	evicted.Status.Phase, evicted.Status.Reason = v1.PodFailed, "Evicted"
+
+	tests := []struct {
+		pod      *v1.Pod
+		expected podStatus
+	}{
+		{
+			pod:      newPod("running", nil, v1.ContainerStatus{State: running, Ready: true, RestartCount: 1}, v1.ContainerStatus{State: running, Ready: true}),
+			expected: podStatus{Status: "Running", Restarts: 1, Ready: 2, Total: 2},
+		},
+		{
+			pod:      newPod("crash", nil, v1.ContainerStatus{State: running, Ready: true}, v1.ContainerStatus{State: waiting("CrashLoopBackOff"), RestartCount: 12}),
+			expected: podStatus{Status: "CrashLoopBackOff", Restarts: 12, Ready: 1, Total: 2},
+		},
+		{
+			pod:      newPod("oom", nil, v1.ContainerStatus{State: terminated("OOMKilled", 137), RestartCount: 3}),
+			expected: podStatus{Status: "OOMKilled", Restarts: 3, Total: 1},
+		},
+		{
+			pod:      newPod("exit", nil, v1.ContainerStatus{State: terminated("", 2)}),
+			expected: podStatus{Status: "ExitCode:2", Total: 1},
+		},
+		{
+			pod:      newPod("init-error", []v1.ContainerStatus{{State: terminated("Error", 1), RestartCount: 2}}, v1.ContainerStatus{State: waiting("PodInitializing")}),
+			expected: podStatus{Status: "Init:Error", Restarts: 2, Total: 1},
+		},
+		{
+			pod:      newPod("init", []v1.ContainerStatus{{State: terminated("Completed", 0)}, {State: running}}, v1.ContainerStatus{State: waiting("PodInitializing")}),
+			expected: podStatus{Status: "Init:1/2", Total: 1},
+		},
+		{
+			pod:      newPod("image", nil, v1.ContainerStatus{State: waiting("ImagePullBackOff")}),
+			expected: podStatus{Status: "ImagePullBackOff", Total: 1},
+		},
+		{pod: terminating, expected: podStatus{Status: "Terminating", Ready: 1, Total: 1}},
+		{pod: evicted, expected: podStatus{Status: "Evicted"}},
+	}
+	for _, test := range tests {
+		if status := computeStatus(test.pod); status != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.pod.Name, test.expected, status)
+		}
+	}
+
+	// pods are filtered and sorted by computed fields
+	var objects []runtime.Object
+	for _, test := range tests {
+		objects = append(objects, test.pod)
+	}
+	filter := func(object runtime.Object, filter query.Filter) bool {
+		return alpha1.DefaultObjectMetaFilter(object.(*v1.Pod).ObjectMeta, filter)
+	}
+	list := func(filters map[query.Field]query.Value, sortBy query.Field) []string {
+		q := query.New()
+		q.Filters = filters
+		q.SortBy = sortBy
+		statuses := podStatuses{}
+		result, err := alpha1.DefaultList(objects, q, statuses.compareFunc, statuses.filterFunc(filter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, item := range result.Items {
+			names = append(names, item.(*v1.Pod).Name)
+		}
+		return names
+	}
+	listTests := []struct {
+		filters  map[query.Field]query.Value
+		sortBy   query.Field
+		expected []string
+	}{
+		{filters: map[query.Field]query.Value{query.FieldStatus: "crashloopbackoff"}, expected: []string{"crash"}},
+		{filters: map[query.Field]query.Value{fieldRestarts: ">=2"}, sortBy: fieldRestarts, expected: []string{"crash", "oom", "init-error"}},
+		{filters: map[query.Field]query.Value{fieldReady: "false", fieldPhase: "Running"}, sortBy: fieldReady, expected: []string{"crash", "exit", "image", "init-error", "init", "oom"}},
+		{filters: map[query.Field]query.Value{fieldReady: "1/2"}, expected: []string{"crash"}},
+	}
+	for _, test := range listTests {
+		if names := list(test.filters, test.sortBy); fmt.Sprint(names) != fmt.Sprint(test.expected) {
+			t.Errorf("%v sorted by %s: expected %v, got %v", test.filters, test.sortBy, test.expected, names)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
+	"captain/pkg/bussiness/kube-resources/alpha1/daemonset"
+	"captain/pkg/bussiness/kube-resources/alpha1/deployment"
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+	"captain/pkg/bussiness/kube-resources/alpha1/namespace"
+	"captain/pkg/bussiness/kube-resources/alpha1/node"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolume"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolumeclaim"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/bussiness/kube-resources/alpha1/statefulset"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+const (
+	// fields of items of fleet requests to group by
+	fieldRegion  query.Field = "region"
+	fieldCluster query.Field = "cluster"
+)
+
+// aggregators of resources with fields other than metadata, the same for host and member clusters
+var aggregators = map[schema.GroupVersionResource]alpha1.Aggregator{
+	NamespaceGVR:             {Field: namespace.FieldValue},
+	NodeGVR:                  {Field: node.FieldValue},
+	PersistentvolumeGVR:      {Field: persistentvolume.FieldValue},
+	DeploymentGVR:            {Field: deployment.FieldValue, Requests: deployment.Requests},
+	PodGVR:                   {Field: pod.FieldValue, Requests: pod.Requests},
+	StatefulsetGVR:           {Field: statefulset.FieldValue, Requests: statefulset.Requests},
+	JobGVR:                   {Field: job.FieldValue, Requests: job.Requests},
+	CronJobGVR:               {Field: cronjob.FieldValue},
+	CronJobBatchV1beta1GVR:   {Field: cronjob.V1beta1FieldValue},
+	DaemonsetGVR:             {Field: daemonset.FieldValue, Requests: daemonset.Requests},
+	PersistentvolumeClaimGVR: {Field: persistentvolumeclaim.FieldValue},
+}
+
+func aggregator(resource string) alpha1.Aggregator {
+	for gvr, aggregator := range aggregators {
+		if matchResource(gvr, resource) {
+			return aggregator
+		}
+	}
+	return alpha1.Aggregator{}
+}
+
+// Aggregate counts resources matching query by values of groupBy fields, resources are listed the
+// same as List, so that filters of providers apply
+func (r *ResourceProcessor) Aggregate(region, cluster, resource, namespace string, q *query.QueryInfo, groupBy []query.Field, sumRequests bool) (*response.AggregateResult, error) {
+	list, err := r.List(region, cluster, resource, namespace, q.WithoutPagination())
+	if err != nil {
+		return nil, err
+	}
+	return alpha1.Aggregate(list.Items, groupBy, aggregator(resource), sumRequests), nil
+}
+
+// FleetAggregate counts resources in all clusters matching selector by values of groupBy fields,
+// besides fields of resource, items can be grouped by region and cluster they come from
+func (r *ResourceProcessor) FleetAggregate(selector labels.Selector, resource, namespace string, q *query.QueryInfo, groupBy []query.Field, sumRequests bool,
+	allowed func(region, cluster, resource, namespace string) bool) (*response.FleetAggregateResult, error) {
+	list, err := r.FleetList(selector, resource, namespace, q.WithoutPagination(), allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	fleetAggregator := aggregator(resource)
+	fieldValue := fleetAggregator.Field
+	fleetAggregator.Field = func(object runtime.Object, field query.Field) (string, bool) {
+		switch field {
+		case fieldRegion:
+			return alpha1.ObjectFieldValue(object, query.Field(alpha1.GroupByAnnotationPrefix+AnnotationRegion))
+		case fieldCluster:
+			return alpha1.ObjectFieldValue(object, query.Field(alpha1.GroupByAnnotationPrefix+AnnotationCluster))
+		}
+		if fieldValue == nil {
+			return "", false
+		}
+		return fieldValue(object, field)
+	}
+
+	return &response.FleetAggregateResult{
+		AggregateResult: *alpha1.Aggregate(list.Items, groupBy, fleetAggregator, sumRequests),
+		Errors:          list.Errors,
+	}, nil
+}
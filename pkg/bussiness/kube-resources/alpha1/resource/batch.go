@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+// Actions of cron jobs and jobs, cron jobs are resumed by ActionResume
+const (
+	ActionTrigger = "trigger"
+	ActionSuspend = "suspend"
+	ActionRerun   = "rerun"
+)
+
+// CronJobAction runs action on cron job in cluster, trigger returns the job created, suspend and resume
+// return the updated cron job
+func (r *ResourceProcessor) CronJobAction(ctx context.Context, region, cluster, namespace, name, action string) (runtime.Object, error) {
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	switch action {
+	case ActionTrigger:
+		return cronjob.Trigger(ctx, client, namespace, name)
+	case ActionSuspend:
+		return cronjob.Suspend(ctx, client, namespace, name, true)
+	case ActionResume:
+		return cronjob.Suspend(ctx, client, namespace, name, false)
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("unknown action %s, it's one of %s, %s and %s",
+		action, ActionTrigger, ActionSuspend, ActionResume))
+}
+
+// CronJobHistory returns runs of cron job in cluster, newest first
+func (r *ResourceProcessor) CronJobHistory(ctx context.Context, region, cluster, namespace, name string) (*response.JobRunHistory, error) {
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cronjob.History(ctx, client, &jobLister{processor: r, region: region, cluster: cluster}, namespace, name)
+}
+
+// jobLister lists jobs and pods in cluster by providers, which read from informers
+type jobLister struct {
+	processor       *ResourceProcessor
+	region, cluster string
+}
+
+func (l *jobLister) ListJobs(namespace string) ([]*batchv1.Job, error) {
+	items, err := l.list("jobs", namespace, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*batchv1.Job, 0, len(items))
+	for _, item := range items {
+		if job, ok := item.(*batchv1.Job); ok {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (l *jobLister) ListPods(namespace string, selector labels.Selector) ([]*corev1.Pod, error) {
+	items, err := l.list("pods", namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(items))
+	for _, item := range items {
+		if pod, ok := item.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func (l *jobLister) list(resource, namespace string, selector labels.Selector) ([]interface{}, error) {
+	q := query.New().WithoutPagination()
+	q.LabelSelector = selector.String()
+	result, err := l.processor.List(l.region, l.cluster, resource, namespace, q)
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// JobAction runs action on job in cluster, rerun returns the job created
+func (r *ResourceProcessor) JobAction(ctx context.Context, region, cluster, namespace, name, action string) (runtime.Object, error) {
+	if action != ActionRerun {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("unknown action %s, it's %s", action, ActionRerun))
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return job.Rerun(ctx, client, namespace, name)
+}
@@ -0,0 +1,166 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+const (
+	// AnnotationRegion and AnnotationCluster are added to items of fleet requests, telling
+	// which cluster the item comes from
+	AnnotationRegion  = clusterv1alpha1.ClusterRegion
+	AnnotationCluster = "cluster.captain.io/cluster"
+
+	// fleetParallelism is the max number of clusters listed at the same time
+	fleetParallelism = 16
+)
+
+// FleetList lists resources in all clusters matching selector concurrently, items are tagged with
+// region and cluster they come from, then sorted and paginated together. Clusters failed to list
+// are reported in errors of result without failing the others, clusters in which resources are not
+// allowed to be listed by allowed are skipped.
+func (r *ResourceProcessor) FleetList(selector labels.Selector, resource, namespace string, q *query.QueryInfo,
+	allowed func(region, cluster, resource, namespace string) bool) (*response.FleetListResult, error) {
+	result := &response.FleetListResult{}
+	var clusters []*clusterv1alpha1.Cluster
+	for _, cluster := range r.clusterClients.List(selector) {
+		region, name := r.clusterClients.GetRegionAndName(cluster)
+		gvr, err := r.resolveKind(cluster, resource)
+		if err != nil {
+			result.Errors = append(result.Errors, response.ClusterError{Region: region, Cluster: name, Error: err.Error(), Reason: apierrors.ReasonForError(err)})
+			continue
+		}
+		if allowed(region, name, gvr.Resource, namespace) {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	items := make([][]runtime.Object, len(clusters))
+	errs := make([]error, len(clusters))
+	workqueue.ParallelizeUntil(context.Background(), fleetParallelism, len(clusters), func(i int) {
+		// each cluster returns all matched items, pagination happens after merging
+		items[i], errs[i] = r.listCluster(clusters[i], resource, namespace, q.WithoutPagination())
+	})
+
+	var merged []runtime.Object
+	for i, cluster := range clusters {
+		if errs[i] != nil {
+			region, name := r.clusterClients.GetRegionAndName(cluster)
+			result.Errors = append(result.Errors, response.ClusterError{Region: region, Cluster: name, Error: errs[i].Error(), Reason: apierrors.ReasonForError(errs[i])})
+			continue
+		}
+		merged = append(merged, items[i]...)
+	}
+
+	// items are filtered by providers of clusters already
+	list, err := alpha1.DefaultList(merged, q, fleetCompare(compareFunc(resource)), func(runtime.Object, query.Filter) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	result.ListResult = *list
+	return result, nil
+}
+
+func (r *ResourceProcessor) listCluster(cluster *clusterv1alpha1.Cluster, resource, namespace string, q *query.QueryInfo) ([]runtime.Object, error) {
+	region, name := r.clusterClients.GetRegionAndName(cluster)
+	list, err := r.listClusterResult(cluster, resource, namespace, q)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]runtime.Object, 0, len(list.Items))
+	for _, item := range list.Items {
+		obj, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		tagged, err := tagCluster(obj, region, name)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, tagged)
+	}
+	return objects, nil
+}
+
+// listClusterResult lists resources in cluster, which is either the host cluster or a ready member cluster
+func (r *ResourceProcessor) listClusterResult(cluster *clusterv1alpha1.Cluster, resource, namespace string, q *query.QueryInfo) (*response.ListResult, error) {
+	if r.clusterClients.IsHostCluster(cluster) {
+		return r.List("", "", resource, namespace, q)
+	}
+	if !r.clusterClients.IsClusterReady(cluster) {
+		return nil, fmt.Errorf("cluster %s is not ready", cluster.Name)
+	}
+	region, name := r.clusterClients.GetRegionAndName(cluster)
+	return r.TryMultiClusterResource(resource).List(region, name, namespace, q)
+}
+
+// tagCluster returns a copy of obj annotated with region and cluster, objects from informers are shared
+func tagCluster(obj runtime.Object, region, cluster string) (runtime.Object, error) {
+	obj = obj.DeepCopyObject()
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 2)
+	}
+	annotations[AnnotationRegion] = region
+	annotations[AnnotationCluster] = cluster
+	accessor.SetAnnotations(annotations)
+	return obj, nil
+}
+
+// fleetCompare compares objects by compare of their provider, objects equal by compare are
+// ordered by the cluster and namespace they locate in, which keeps pages stable
+func fleetCompare(compare alpha1.CompareFunc) alpha1.CompareFunc {
+	return func(left, right runtime.Object, field query.Field) bool {
+		if compare(left, right, field) || compare(right, left, field) {
+			return compare(left, right, field)
+		}
+		l, err := meta.Accessor(left)
+		if err != nil {
+			return false
+		}
+		r, err := meta.Accessor(right)
+		if err != nil {
+			return true
+		}
+		return fleetKey(l) < fleetKey(r)
+	}
+}
+
+// metadataCompare compares objects of any kind by metadata
+func metadataCompare(left, right runtime.Object, field query.Field) bool {
+	l, err := meta.Accessor(left)
+	if err != nil {
+		return false
+	}
+	r, err := meta.Accessor(right)
+	if err != nil {
+		return true
+	}
+	lm := metav1.ObjectMeta{Name: l.GetName(), CreationTimestamp: l.GetCreationTimestamp()}
+	rm := metav1.ObjectMeta{Name: r.GetName(), CreationTimestamp: r.GetCreationTimestamp()}
+	return alpha1.DefaultObjectMetaCompare(lm, rm, field)
+}
+
+func fleetKey(obj metav1.Object) string {
+	annotations := obj.GetAnnotations()
+	return strings.Join([]string{annotations[AnnotationRegion], annotations[AnnotationCluster], obj.GetNamespace()}, "/")
+}
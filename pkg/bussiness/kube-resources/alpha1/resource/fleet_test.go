@@ -0,0 +1,45 @@
+package resource
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+)
+
+func TestFleetCompare(t *testing.T) {
+	newPod := func(cluster, name string, restarts int32) runtime.Object {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{Name: "app", RestartCount: restarts}}},
+		}
+		obj, _ := tagCluster(pod, "wx", cluster)
+		return obj
+	}
+	objects := []runtime.Object{newPod("a", "web", 3), newPod("b", "api", 3), newPod("b", "web", 1)}
+
+	// restarts is computed by the pod provider, pods restarted as many times are ordered by cluster
+	q := query.New()
+	q.SortBy = "restarts"
+	q.Ascending = true
+	result, err := alpha1.DefaultList(objects, q, fleetCompare(compareFunc("pods")), func(runtime.Object, query.Filter) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"b/web", "a/web", "b/api"}
+	if len(result.Items) != len(expected) {
+		t.Fatalf("expected %d items, got %d", len(expected), len(result.Items))
+	}
+	for i, item := range result.Items {
+		pod := item.(*v1.Pod)
+		if got := pod.Annotations[AnnotationCluster] + "/" + pod.Name; got != expected[i] {
+			t.Errorf("item %d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+}
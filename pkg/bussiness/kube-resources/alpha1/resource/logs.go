@@ -0,0 +1,49 @@
+package resource
+
+import (
+	"context"
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+)
+
+var errNoKubernetesClient = errors.New("client of host cluster is not configured")
+
+// PodLogs opens log streams of pod in cluster, logs of all containers are merged if container
+// is not specified
+func (r *ResourceProcessor) PodLogs(ctx context.Context, region, cluster, namespace, name string, options *v1.PodLogOptions) (*pod.LogStreams, error) {
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return pod.OpenLogs(ctx, client, namespace, name, options)
+}
+
+// WorkloadLogs opens log streams of all pods owned by workload in cluster, workload is one of
+// deployments, statefulsets, daemonsets, replicasets and jobs
+func (r *ResourceProcessor) WorkloadLogs(ctx context.Context, region, cluster, namespace, workload, name string, options *v1.PodLogOptions) (*pod.LogStreams, error) {
+	kind, ok := pod.WorkloadKind(workload)
+	if !ok {
+		return nil, ErrResourceNotSupported
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return pod.OpenWorkloadLogs(ctx, client, namespace, kind, name, options)
+}
+
+// kubernetes returns client of host cluster or member cluster
+func (r *ResourceProcessor) kubernetes(region, cluster string) (kubernetes.Interface, error) {
+	if !alpha1.IsHostCluster(region, cluster) {
+		return r.clusterClients.GetClientSet(region, cluster)
+	}
+	if r.kubernetesClient == nil {
+		return nil, errNoKubernetesClient
+	}
+	return r.kubernetesClient, nil
+}
@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"captain/pkg/bussiness/kube-resources/alpha1/node"
+	"captain/pkg/unify/response"
+)
+
+// Actions of node maintenance
+const (
+	ActionCordon   = "cordon"
+	ActionUncordon = "uncordon"
+	ActionDrain    = "drain"
+)
+
+// NodeAction runs action on node in cluster, the updated node is returned by cordon and uncordon,
+// and the initial progress by drain which runs in background
+func (r *ResourceProcessor) NodeAction(ctx context.Context, region, cluster, name, action string, options node.DrainOptions) (interface{}, error) {
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	switch action {
+	case ActionCordon:
+		return node.Cordon(ctx, client, name, true)
+	case ActionUncordon:
+		return node.Cordon(ctx, client, name, false)
+	case ActionDrain:
+		return r.drains.Start(ctx, client, region, cluster, name, options)
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("unknown action %s, it's one of %s, %s and %s",
+		action, ActionCordon, ActionUncordon, ActionDrain))
+}
+
+// NodeDrain returns progress of the latest drain of node in cluster
+func (r *ResourceProcessor) NodeDrain(region, cluster, name string) (*response.NodeDrain, error) {
+	return r.drains.Get(region, cluster, name)
+}
+
+// WatchNodeDrain returns progress of the latest drain of node in cluster and a channel closed once
+// the progress changes
+func (r *ResourceProcessor) WatchNodeDrain(region, cluster, name string) (*response.NodeDrain, <-chan struct{}, error) {
+	return r.drains.Watch(region, cluster, name)
+}
+
+// CancelNodeDrain stops the running drain of node in cluster
+func (r *ResourceProcessor) CancelNodeDrain(region, cluster, name string) (*response.NodeDrain, error) {
+	return r.drains.Cancel(region, cluster, name)
+}
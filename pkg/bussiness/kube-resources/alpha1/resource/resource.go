@@ -8,6 +8,7 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
 	"captain/pkg/bussiness/kube-resources/alpha1/daemonset"
 	"captain/pkg/bussiness/kube-resources/alpha1/deployment"
+	"captain/pkg/bussiness/kube-resources/alpha1/generic"
 	"captain/pkg/bussiness/kube-resources/alpha1/ingress"
 	"captain/pkg/bussiness/kube-resources/alpha1/job"
 	"captain/pkg/bussiness/kube-resources/alpha1/limitrange"
@@ -25,15 +26,22 @@ import (
 	"captain/pkg/bussiness/kube-resources/alpha1/serviceaccount"
 	"captain/pkg/bussiness/kube-resources/alpha1/statefulset"
 	"captain/pkg/bussiness/kube-resources/alpha1/storageclass"
+	"captain/pkg/bussiness/kube-resources/alpha1/terminal"
 	"captain/pkg/informers"
 	"captain/pkg/server/config"
+	"captain/pkg/simple/client/k8s"
 	"captain/pkg/unify/query"
 	"captain/pkg/unify/response"
 	"captain/pkg/utils/clusterclient"
 	"errors"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
 
@@ -72,9 +80,32 @@ type ResourceProcessor struct {
 	namespacedResourceProcessors map[schema.GroupVersionResource]alpha1.KubeResProvider
 
 	multiClusterResourceProcessors map[schema.GroupVersionResource]alpha1.MultiClusterKubeResProvider
+
+	// genericProviders serves resources of host cluster not in the maps above, nil if no client
+	genericProviders *generic.Providers
+
+	// kubernetesClient is client of host cluster, nil if no client
+	kubernetesClient kubernetes.Interface
+	// kubernetesConfig is rest config of host cluster, nil if no client
+	kubernetesConfig *rest.Config
+
+	terminalOptions *terminal.Options
+
+	// rollouters roll out workloads, keyed by resource names
+	rollouters map[string]alpha1.Rollouter
+
+	// drains keeps progress of draining nodes of all clusters
+	drains *node.Drains
+
+	clusterClients clusterclient.ClusterClients
+
+	// multiClusterEnabled is true if member clusters are managed, search requests cover them too
+	multiClusterEnabled bool
+
+	broadcasters *informerBroadcasters
 }
 
-func NewResourceProcessor(factory informers.CapInformerFactory, cache cache.Cache, config *config.Config) *ResourceProcessor {
+func NewResourceProcessor(factory informers.CapInformerFactory, client k8s.Client, cache cache.Cache, config *config.Config) *ResourceProcessor {
 	namespacedResourceProcessors := make(map[schema.GroupVersionResource]alpha1.KubeResProvider)
 	clusterResourceProcessors := make(map[schema.GroupVersionResource]alpha1.KubeResProvider)
 
@@ -136,39 +167,95 @@ func NewResourceProcessor(factory informers.CapInformerFactory, cache cache.Cach
 	multiClusterResourceProcessors[ResourceQuotaGVR] = resourcequota.NewMCResProvider(clients)
 	multiClusterResourceProcessors[LimitRangeGVR] = limitrange.NewMCResProvider(clients)
 
+	var genericProviders *generic.Providers
+	var kubernetesClient kubernetes.Interface
+	var kubernetesConfig *rest.Config
+	if client != nil {
+		genericProviders = generic.NewProviders(client.Dynamic(), client.Discovery(), wait.NeverStop)
+		kubernetesClient = client.Kubernetes()
+		kubernetesConfig = client.Config()
+	}
+	rollouters := map[string]alpha1.Rollouter{
+		DeploymentGVR.Resource:  deployment.NewRollouter(),
+		StatefulsetGVR.Resource: statefulset.NewRollouter(),
+		DaemonsetGVR.Resource:   daemonset.NewRollouter(),
+	}
+	terminalOptions := config.TerminalOptions
+	if terminalOptions == nil {
+		terminalOptions = terminal.NewOptions()
+	}
+
+	broadcasters := newInformerBroadcasters()
+	if genericProviders != nil {
+		genericProviders.OnEvict(broadcasters.remove)
+	}
+
 	return &ResourceProcessor{
 		namespacedResourceProcessors:   namespacedResourceProcessors,
 		clusterResourceProcessors:      clusterResourceProcessors,
 		multiClusterResourceProcessors: multiClusterResourceProcessors,
+		genericProviders:               genericProviders,
+		kubernetesClient:               kubernetesClient,
+		kubernetesConfig:               kubernetesConfig,
+		terminalOptions:                terminalOptions,
+		rollouters:                     rollouters,
+		drains:                         node.NewDrains(),
+		clusterClients:                 clients,
+		multiClusterEnabled:            config.MultiClusterOptions.Enable,
+		broadcasters:                   broadcasters,
 	}
 }
 
-// TryResource will retrieve a getter with resource name, it doesn't guarantee find resource with correct group version
-// need to refactor this use schema.GroupVersionResource
+// TryResource will retrieve a getter with resource in the form of resource[.version][.group], e.g. deployments,
+// deployments.apps or deployments.v1.apps, resources without dedicated providers are served by generic provider
 func (r *ResourceProcessor) TryResource(clusterScope bool, resource string) alpha1.KubeResProvider {
 	if clusterScope {
 		for k, v := range r.clusterResourceProcessors {
-			if k.Resource == resource {
+			if matchResource(k, resource) {
 				return v
 			}
 		}
 	}
 	for k, v := range r.namespacedResourceProcessors {
-		if k.Resource == resource {
+		if matchResource(k, resource) {
 			return v
 		}
 	}
 
-	return nil
+	if r.genericProviders == nil {
+		return nil
+	}
+	provider, namespaced, err := r.genericProviders.Provider(resource)
+	if err != nil {
+		if !meta.IsNoMatchError(err) {
+			klog.Errorf("get provider of %s failed, %v", resource, err)
+		}
+		return nil
+	}
+	if !clusterScope && !namespaced {
+		return nil
+	}
+	return provider
 }
 
+// TryMultiClusterResource returns provider of resource in member clusters, resources without dedicated
+// providers are served by generic provider, which returns meta.NoResourceMatchError if they are not served
 func (r *ResourceProcessor) TryMultiClusterResource(resource string) alpha1.MultiClusterKubeResProvider {
 	for k, v := range r.multiClusterResourceProcessors {
-		if k.Resource == resource {
+		if matchResource(k, resource) {
 			return v
 		}
 	}
-	return nil
+	return generic.NewMCResProvider(r.clusterClients, resource)
+}
+
+// matchResource returns true if resource in the form of resource[.version][.group] refers to gvr
+func matchResource(gvr schema.GroupVersionResource, resource string) bool {
+	fullySpecified, groupResource := schema.ParseResourceArg(resource)
+	if fullySpecified != nil && *fullySpecified == gvr {
+		return true
+	}
+	return groupResource.Resource == gvr.Resource && (len(groupResource.Group) == 0 || groupResource.Group == gvr.Group)
 }
 
 func (r *ResourceProcessor) Get(region, cluster, resource, namespace, name string) (runtime.Object, error) {
@@ -181,10 +268,11 @@ func (r *ResourceProcessor) Get(region, cluster, resource, namespace, name strin
 		return getter.Get(namespace, name)
 	}
 	getter := r.TryMultiClusterResource(resource)
-	if getter == nil {
+	obj, err := getter.Get(region, cluster, namespace, name)
+	if meta.IsNoMatchError(err) {
 		return nil, ErrResourceNotSupported
 	}
-	return getter.Get(region, cluster, namespace, name)
+	return obj, err
 }
 
 func (r *ResourceProcessor) List(region, cluster, resource, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
@@ -199,8 +287,14 @@ func (r *ResourceProcessor) List(region, cluster, resource, namespace string, qu
 		return provider.List(namespace, query)
 	}
 	provider := r.TryMultiClusterResource(resource)
-	if provider == nil {
+	result, err := provider.List(region, cluster, namespace, query)
+	if meta.IsNoMatchError(err) {
 		return nil, ErrResourceNotSupported
 	}
-	return provider.List(region, cluster, namespace, query)
+	return result, err
+}
+
+// ClusterCacheStatus returns sync status of informers of member clusters
+func (r *ResourceProcessor) ClusterCacheStatus() []clusterclient.ClusterCacheStatus {
+	return r.clusterClients.CacheStatus()
 }
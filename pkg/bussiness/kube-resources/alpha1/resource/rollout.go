@@ -0,0 +1,69 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/response"
+)
+
+// Actions of workload rollouts
+const (
+	ActionRestart  = "restart"
+	ActionScale    = "scale"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+	ActionRollback = "rollback"
+)
+
+// RolloutAction is an action on a workload, Replicas is used by scale and Revision by rollback
+type RolloutAction struct {
+	Name     string
+	Replicas int32
+	// Revision is the revision rolled back to, 0 is the previous revision
+	Revision int64
+}
+
+// Rollout runs action on workload in cluster, workload is one of deployments, statefulsets and daemonsets,
+// the updated workload is returned
+func (r *ResourceProcessor) Rollout(ctx context.Context, region, cluster, namespace, workload, name string, action RolloutAction) (runtime.Object, error) {
+	rollouter, ok := r.rollouters[workload]
+	if !ok {
+		return nil, ErrResourceNotSupported
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action.Name {
+	case ActionRestart:
+		return rollouter.Restart(ctx, client, namespace, name)
+	case ActionScale:
+		return rollouter.Scale(ctx, client, namespace, name, action.Replicas)
+	case ActionPause:
+		return rollouter.Pause(ctx, client, namespace, name)
+	case ActionResume:
+		return rollouter.Resume(ctx, client, namespace, name)
+	case ActionRollback:
+		return rollouter.Rollback(ctx, client, namespace, name, action.Revision)
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("unknown action %s, it's one of %s, %s, %s, %s and %s",
+		action.Name, ActionRestart, ActionScale, ActionPause, ActionResume, ActionRollback))
+}
+
+// RevisionHistory returns revisions of workload in cluster with diffs of pod templates between revisions
+func (r *ResourceProcessor) RevisionHistory(ctx context.Context, region, cluster, namespace, workload, name string) (*response.RevisionHistory, error) {
+	rollouter, ok := r.rollouters[workload]
+	if !ok {
+		return nil, ErrResourceNotSupported
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return rollouter.History(ctx, client, namespace, name)
+}
@@ -0,0 +1,222 @@
+package resource
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/generic"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+// legacyGVRs are served by the same objects as other resources, they are searched only if requested
+var legacyGVRs = map[schema.GroupVersionResource]bool{
+	CronJobBatchV1beta1GVR: true,
+	IngresseV1beta1GVR:     true,
+}
+
+// unsearchableResources are never searched, names and labels of secrets are not revealed to users
+// allowed to list other kinds. Kinds are resolved before excluded, so secrets spelled in any form
+// like secrets.v1. are excluded too.
+var unsearchableResources = map[string]bool{
+	"secrets": true,
+}
+
+// searchTarget is a resource type in a cluster, cluster is nil for the host cluster if multi-cluster
+// is disabled. Kind is listed as requested, resource is the name it is resolved to.
+type searchTarget struct {
+	cluster  *clusterv1alpha1.Cluster
+	kind     string
+	resource string
+}
+
+// SearchKinds returns resources searched if kinds are not specified, which are resources with
+// dedicated providers
+func (r *ResourceProcessor) SearchKinds() []string {
+	var kinds []string
+	for _, processors := range []map[schema.GroupVersionResource]alpha1.KubeResProvider{r.clusterResourceProcessors, r.namespacedResourceProcessors} {
+		for gvr := range processors {
+			if !legacyGVRs[gvr] && !unsearchableResources[gvr.Resource] {
+				kinds = append(kinds, gvr.Resource)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Search lists resources of kinds concurrently, in the host cluster or all clusters matching selector
+// if multi-cluster is enabled, and returns objects matching search text ranked by score. Filters of
+// query apply to listed resources, hits are paginated by pagination of query. Kinds in clusters not
+// allowed to be listed by allowed are skipped, region and cluster are empty for the host cluster if
+// multi-cluster is disabled.
+func (r *ResourceProcessor) Search(text string, kinds []string, selector labels.Selector, q *query.QueryInfo,
+	allowed func(region, cluster, resource, namespace string) bool) *response.SearchResult {
+	if len(kinds) == 0 {
+		kinds = r.SearchKinds()
+	}
+	clusters := []*clusterv1alpha1.Cluster{nil}
+	if r.multiClusterEnabled {
+		clusters = r.clusterClients.List(selector)
+	}
+	result := &response.SearchResult{Items: []response.SearchHit{}}
+	namespace := string(q.Filters[query.FieldNamespace])
+	var targets []searchTarget
+	for _, cluster := range clusters {
+		var region, name string
+		if cluster != nil {
+			region, name = r.clusterClients.GetRegionAndName(cluster)
+		}
+		for _, kind := range kinds {
+			gvr, err := r.resolveKind(cluster, kind)
+			if err != nil {
+				result.Errors = append(result.Errors, response.ClusterError{Region: region, Cluster: name, Resource: kind, Error: err.Error(), Reason: apierrors.ReasonForError(err)})
+				continue
+			}
+			if unsearchableResources[gvr.Resource] || !allowed(region, name, gvr.Resource, namespace) {
+				continue
+			}
+			targets = append(targets, searchTarget{cluster: cluster, kind: kind, resource: gvr.Resource})
+		}
+	}
+
+	matcher := alpha1.NewSearchMatcher(text)
+	hits := make([][]response.SearchHit, len(targets))
+	errs := make([]error, len(targets))
+	workqueue.ParallelizeUntil(context.Background(), fleetParallelism, len(targets), func(i int) {
+		hits[i], errs[i] = r.searchTarget(targets[i], matcher, q.WithoutPagination())
+	})
+
+	var merged []response.SearchHit
+	for i, target := range targets {
+		if errs[i] != nil {
+			clusterError := response.ClusterError{Resource: target.resource, Error: errs[i].Error(), Reason: apierrors.ReasonForError(errs[i])}
+			if target.cluster != nil {
+				clusterError.Region, clusterError.Cluster = r.clusterClients.GetRegionAndName(target.cluster)
+			}
+			result.Errors = append(result.Errors, clusterError)
+			continue
+		}
+		merged = append(merged, hits[i]...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		left, right := merged[i], merged[j]
+		if left.Score != right.Score {
+			return left.Score > right.Score
+		}
+		for _, pair := range [][2]string{{left.Kind, right.Kind}, {left.Region, right.Region}, {left.Cluster, right.Cluster}, {left.Namespace, right.Namespace}} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		return left.Name < right.Name
+	})
+
+	result.Total = len(merged)
+	begin, end := q.Pagination.GetValidPagination(result.Total)
+	result.Items = append(result.Items, merged[begin:end]...)
+	result.CurrentPage = q.Pagination.Page
+	result.PageSize = q.Pagination.PageSize
+	result.TotalPages = int(math.Ceil(float64(result.Total) / float64(q.Pagination.PageSize)))
+	return result
+}
+
+func (r *ResourceProcessor) searchTarget(target searchTarget, matcher *alpha1.SearchMatcher, q *query.QueryInfo) ([]response.SearchHit, error) {
+	var list *response.ListResult
+	var err error
+	var region, cluster string
+	if target.cluster == nil {
+		list, err = r.List("", "", target.kind, "", q)
+	} else {
+		region, cluster = r.clusterClients.GetRegionAndName(target.cluster)
+		list, err = r.listClusterResult(target.cluster, target.kind, "", q)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []response.SearchHit
+	for _, item := range list.Items {
+		object, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			continue
+		}
+		score, matches := matcher.Match(accessor)
+		if score == 0 {
+			continue
+		}
+		apiVersion, kind := objectKind(object).ToAPIVersionAndKind()
+		hits = append(hits, response.SearchHit{
+			APIVersion:        apiVersion,
+			Kind:              kind,
+			Resource:          target.resource,
+			Region:            region,
+			Cluster:           cluster,
+			Namespace:         accessor.GetNamespace(),
+			Name:              accessor.GetName(),
+			Labels:            accessor.GetLabels(),
+			CreationTimestamp: accessor.GetCreationTimestamp(),
+			Score:             score,
+			Matches:           matches,
+		})
+	}
+	return hits, nil
+}
+
+// resolveKind resolves kind in the form of resource[.version][.group] to the resource it refers to in
+// cluster, cluster is nil for the host cluster
+func (r *ResourceProcessor) resolveKind(cluster *clusterv1alpha1.Cluster, kind string) (schema.GroupVersionResource, error) {
+	if cluster == nil {
+		for _, processors := range []map[schema.GroupVersionResource]alpha1.KubeResProvider{r.clusterResourceProcessors, r.namespacedResourceProcessors} {
+			for gvr := range processors {
+				if matchResource(gvr, kind) {
+					return gvr, nil
+				}
+			}
+		}
+		if r.genericProviders == nil {
+			return schema.GroupVersionResource{}, ErrResourceNotSupported
+		}
+		return r.genericProviders.Resolve(kind)
+	}
+
+	for gvr := range r.multiClusterResourceProcessors {
+		if matchResource(gvr, kind) {
+			return gvr, nil
+		}
+	}
+	mapper, err := r.clusterClients.GetRESTMapper(r.clusterClients.GetRegionAndName(cluster))
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	gvr, _, err := generic.Resolve(mapper, kind)
+	return gvr, err
+}
+
+// objectKind returns kind of object, objects from informers have no type meta and their kinds are
+// looked up in scheme
+func objectKind(object runtime.Object) schema.GroupVersionKind {
+	if gvk := object.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk
+	}
+	if gvks, _, err := scheme.Scheme.ObjectKinds(object); err == nil && len(gvks) != 0 {
+		return gvks[0]
+	}
+	return schema.GroupVersionKind{}
+}
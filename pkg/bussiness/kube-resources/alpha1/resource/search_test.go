@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/bussiness/kube-resources/alpha1/secret"
+	"captain/pkg/unify/query"
+)
+
+func TestSearchResolvesKinds(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-token", Namespace: "default"}},
+	)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	r := &ResourceProcessor{
+		namespacedResourceProcessors: map[schema.GroupVersionResource]alpha1.KubeResProvider{
+			PodGVR:    pod.New(factory),
+			SecretGVR: secret.New(factory),
+		},
+	}
+	factory.Core().V1().Pods().Informer()
+	factory.Core().V1().Secrets().Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	// secrets are excluded however they are spelled, and resources are authorized by their names
+	var authorized []string
+	allowed := func(_, _, resource, _ string) bool {
+		authorized = append(authorized, resource)
+		return true
+	}
+	result := r.Search("web", []string{"secrets.v1.", "secret", "pods.v1."}, labels.Everything(), query.New(), allowed)
+	if len(authorized) != 1 || authorized[0] != "pods" {
+		t.Errorf("expected only pods authorized, got %v", authorized)
+	}
+	if len(result.Items) != 1 || result.Items[0].Name != "web" || result.Items[0].Resource != "pods" {
+		t.Errorf("expected only pod web found, got %+v", result.Items)
+	}
+}
@@ -0,0 +1,106 @@
+package resource
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
+	"captain/pkg/bussiness/kube-resources/alpha1/daemonset"
+	"captain/pkg/bussiness/kube-resources/alpha1/deployment"
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+	"captain/pkg/bussiness/kube-resources/alpha1/namespace"
+	"captain/pkg/bussiness/kube-resources/alpha1/node"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolume"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolumeclaim"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/bussiness/kube-resources/alpha1/statefulset"
+	"captain/pkg/unify/query"
+)
+
+// sortKeys are keys resources are sorted by besides JSONPaths, resources not listed are sorted by
+// alpha1.DefaultSortKeys
+var sortKeys = map[schema.GroupVersionResource][]query.Field{
+	NamespaceGVR:             namespace.SortKeys,
+	NodeGVR:                  node.SortKeys,
+	PersistentvolumeGVR:      persistentvolume.SortKeys,
+	DeploymentGVR:            deployment.SortKeys,
+	PodGVR:                   pod.SortKeys,
+	StatefulsetGVR:           statefulset.SortKeys,
+	JobGVR:                   job.SortKeys,
+	CronJobGVR:               cronjob.SortKeys,
+	CronJobBatchV1beta1GVR:   cronjob.SortKeys,
+	DaemonsetGVR:             daemonset.SortKeys,
+	PersistentvolumeClaimGVR: persistentvolumeclaim.SortKeys,
+}
+
+// compareFuncs compare resources by sort keys of sortKeys, used by fleet lists which merge
+// resources of all clusters, resources not listed are compared by metadata
+var compareFuncs = map[schema.GroupVersionResource]alpha1.CompareFunc{
+	NamespaceGVR:             namespace.Compare,
+	NodeGVR:                  node.Compare,
+	PersistentvolumeGVR:      persistentvolume.Compare,
+	DeploymentGVR:            deployment.Compare,
+	PodGVR:                   pod.Compare,
+	StatefulsetGVR:           statefulset.Compare,
+	JobGVR:                   job.Compare,
+	CronJobGVR:               cronjob.Compare,
+	CronJobBatchV1beta1GVR:   cronjob.V1beta1Compare,
+	DaemonsetGVR:             daemonset.Compare,
+	PersistentvolumeClaimGVR: persistentvolumeclaim.Compare,
+}
+
+func compareFunc(resource string) alpha1.CompareFunc {
+	for gvr, compare := range compareFuncs {
+		if matchResource(gvr, resource) {
+			return compare
+		}
+	}
+	return metadataCompare
+}
+
+// SortKeys returns keys resource is sorted by besides JSONPaths
+func (r *ResourceProcessor) SortKeys(resource string) []query.Field {
+	for gvr, keys := range sortKeys {
+		if matchResource(gvr, resource) {
+			return keys
+		}
+	}
+	return alpha1.DefaultSortKeys
+}
+
+// ValidateSortBy returns a bad request if resource can't be sorted by sortBy
+func (r *ResourceProcessor) ValidateSortBy(resource string, sortBy query.Field) error {
+	return alpha1.ValidateSortBy(sortBy, r.SortKeys(resource))
+}
+
+// SortKeysDescription describes sort keys specific to resources in API docs, e.g.
+// deployments: updateTime, status, replicas
+func SortKeysDescription() string {
+	var descriptions []string
+	for gvr, keys := range sortKeys {
+		if legacyGVRs[gvr] {
+			continue
+		}
+		var names []string
+		for _, key := range keys {
+			if !isDefaultSortKey(key) {
+				names = append(names, string(key))
+			}
+		}
+		descriptions = append(descriptions, gvr.Resource+": "+strings.Join(names, ", "))
+	}
+	sort.Strings(descriptions)
+	return strings.Join(descriptions, "; ")
+}
+
+func isDefaultSortKey(key query.Field) bool {
+	for _, defaultKey := range alpha1.DefaultSortKeys {
+		if key == defaultKey {
+			return true
+		}
+	}
+	return false
+}
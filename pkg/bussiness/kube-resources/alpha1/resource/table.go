@@ -0,0 +1,89 @@
+package resource
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/clusterrole"
+	"captain/pkg/bussiness/kube-resources/alpha1/clusterrolebinding"
+	"captain/pkg/bussiness/kube-resources/alpha1/configmap"
+	"captain/pkg/bussiness/kube-resources/alpha1/cronjob"
+	"captain/pkg/bussiness/kube-resources/alpha1/daemonset"
+	"captain/pkg/bussiness/kube-resources/alpha1/deployment"
+	"captain/pkg/bussiness/kube-resources/alpha1/ingress"
+	"captain/pkg/bussiness/kube-resources/alpha1/job"
+	"captain/pkg/bussiness/kube-resources/alpha1/limitrange"
+	"captain/pkg/bussiness/kube-resources/alpha1/namespace"
+	"captain/pkg/bussiness/kube-resources/alpha1/networkpolicy"
+	"captain/pkg/bussiness/kube-resources/alpha1/node"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolume"
+	"captain/pkg/bussiness/kube-resources/alpha1/persistentvolumeclaim"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/bussiness/kube-resources/alpha1/resourcequota"
+	"captain/pkg/bussiness/kube-resources/alpha1/role"
+	"captain/pkg/bussiness/kube-resources/alpha1/rolebinding"
+	"captain/pkg/bussiness/kube-resources/alpha1/secret"
+	"captain/pkg/bussiness/kube-resources/alpha1/service"
+	"captain/pkg/bussiness/kube-resources/alpha1/serviceaccount"
+	"captain/pkg/bussiness/kube-resources/alpha1/statefulset"
+	"captain/pkg/bussiness/kube-resources/alpha1/storageclass"
+)
+
+// tableColumns are default columns of resources in table output, the same for host and member clusters
+var tableColumns = map[schema.GroupVersionResource][]alpha1.TableColumn{
+	NamespaceGVR:             namespace.TableColumns,
+	NodeGVR:                  node.TableColumns,
+	ClusterroleGVR:           clusterrole.TableColumns,
+	StorageclassGVR:          storageclass.TableColumns,
+	PersistentvolumeGVR:      persistentvolume.TableColumns,
+	ClusterrolebindingGVR:    clusterrolebinding.TableColumns,
+	DeploymentGVR:            deployment.TableColumns,
+	PodGVR:                   pod.TableColumns,
+	StatefulsetGVR:           statefulset.TableColumns,
+	JobGVR:                   job.TableColumns,
+	CronJobGVR:               cronjob.TableColumns,
+	CronJobBatchV1beta1GVR:   cronjob.TableColumns,
+	DaemonsetGVR:             daemonset.TableColumns,
+	IngresseGVR:              ingress.TableColumns,
+	IngresseV1beta1GVR:       ingress.TableColumns,
+	ServiceGVR:               service.TableColumns,
+	ConfigmapGVR:             configmap.TableColumns,
+	PersistentvolumeClaimGVR: persistentvolumeclaim.TableColumns,
+	SecretGVR:                secret.TableColumns,
+	ServiceaccountGVR:        serviceaccount.TableColumns,
+	RoleGVR:                  role.TableColumns,
+	RolebindingGVR:           rolebinding.TableColumns,
+	NetworkpolicieGVR:        networkpolicy.TableColumns,
+	ResourceQuotaGVR:         resourcequota.TableColumns,
+	LimitRangeGVR:            limitrange.TableColumns,
+}
+
+// TableColumns returns columns of resource in table output, which are columns of fields if any,
+// or default columns of resource, resources without default columns have name and age columns
+func (r *ResourceProcessor) TableColumns(resource string, fields []string) []alpha1.TableColumn {
+	if len(fields) != 0 {
+		return alpha1.FieldColumns(fields)
+	}
+	for gvr, columns := range tableColumns {
+		if matchResource(gvr, resource) {
+			return columns
+		}
+	}
+	return alpha1.DefaultTableColumns
+}
+
+// FleetTableColumns returns columns of resource in table output of fleet requests, region and
+// cluster of items are shown before the columns of resource
+func (r *ResourceProcessor) FleetTableColumns(resource string, fields []string) []alpha1.TableColumn {
+	return append([]alpha1.TableColumn{
+		alpha1.Column("Region", annotationPath(AnnotationRegion), 0),
+		alpha1.Column("Cluster", annotationPath(AnnotationCluster), 0),
+	}, r.TableColumns(resource, fields)...)
+}
+
+// annotationPath returns JSONPath of annotation, dots in keys of annotations are escaped
+func annotationPath(key string) string {
+	return ".metadata.annotations." + strings.ReplaceAll(key, ".", `\.`)
+}
@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"context"
+
+	"k8s.io/client-go/rest"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/terminal"
+)
+
+// TerminalOptions returns options of terminals
+func (r *ResourceProcessor) TerminalOptions() *terminal.Options {
+	return r.terminalOptions
+}
+
+// PodTerminal returns terminal of container of pod in cluster, shell is run if it's set, or bash
+// or sh otherwise
+func (r *ResourceProcessor) PodTerminal(ctx context.Context, region, cluster, namespace, name, container, shell string) (*terminal.Terminal, error) {
+	config, err := r.restConfig(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return terminal.NewPodTerminal(ctx, config, client, namespace, name, container, shell)
+}
+
+// NodeTerminal returns shell terminal of node in cluster, it runs in a privileged pod
+func (r *ResourceProcessor) NodeTerminal(ctx context.Context, region, cluster, node string) (*terminal.Terminal, error) {
+	config, err := r.restConfig(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	client, err := r.kubernetes(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return terminal.NewNodeTerminal(ctx, config, client, node, r.terminalOptions)
+}
+
+// restConfig returns rest config of host cluster or member cluster
+func (r *ResourceProcessor) restConfig(region, cluster string) (*rest.Config, error) {
+	if !alpha1.IsHostCluster(region, cluster) {
+		return r.clusterClients.GetConfig(region, cluster)
+	}
+	if r.kubernetesConfig == nil {
+		return nil, errNoKubernetesClient
+	}
+	return r.kubernetesConfig, nil
+}
@@ -0,0 +1,316 @@
+package resource
+
+import (
+	"strconv"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/query"
+)
+
+// watchQueueLength is the number of events buffered for each watcher, watchers too slow to keep
+// up are closed with an error event, telling clients to watch again
+const watchQueueLength = 1000
+
+// informerBroadcasters fans events of an informer out to watchers, event handlers can't be
+// removed from informers, so each informer is registered once and watchers come and go
+type informerBroadcasters struct {
+	sync.Mutex
+	broadcasters map[cache.SharedIndexInformer]*broadcaster
+}
+
+// broadcaster sends events to queues of watchers, it drops events once it's shut down, as handlers
+// of stopped informers may still be running
+type broadcaster struct {
+	lock     sync.Mutex
+	queues   map[*eventQueue]bool
+	shutdown bool
+}
+
+// eventQueue buffers events of a watcher, events is closed once the watcher stops, or it's full and
+// overflow is set
+type eventQueue struct {
+	events   chan informerEvent
+	overflow bool
+}
+
+// informerEvent is an event of informer, old is the object before it's modified, which tells whether
+// it was matched by watchers
+type informerEvent struct {
+	watch.Event
+	old runtime.Object
+}
+
+func (b *broadcaster) action(action watch.EventType, object runtime.Object) {
+	b.update(action, object, nil)
+}
+
+// update broadcasts event of object, old is the object before it's modified, nil for other events
+func (b *broadcaster) update(action watch.EventType, object, old runtime.Object) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.shutdown {
+		return
+	}
+	for queue := range b.queues {
+		select {
+		case queue.events <- informerEvent{Event: watch.Event{Type: action, Object: object}, old: old}:
+		default:
+			queue.overflow = true
+			close(queue.events)
+			delete(b.queues, queue)
+		}
+	}
+}
+
+// watch returns a queue receiving events broadcast from now on
+func (b *broadcaster) watch() *eventQueue {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	queue := &eventQueue{events: make(chan informerEvent, watchQueueLength)}
+	if b.shutdown {
+		close(queue.events)
+		return queue
+	}
+	b.queues[queue] = true
+	return queue
+}
+
+func (b *broadcaster) unwatch(queue *eventQueue) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.queues[queue] {
+		close(queue.events)
+		delete(b.queues, queue)
+	}
+}
+
+func (b *broadcaster) stop() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.shutdown = true
+	for queue := range b.queues {
+		close(queue.events)
+	}
+	b.queues = nil
+}
+
+func newInformerBroadcasters() *informerBroadcasters {
+	return &informerBroadcasters{broadcasters: make(map[cache.SharedIndexInformer]*broadcaster)}
+}
+
+func (b *informerBroadcasters) get(informer cache.SharedIndexInformer) *broadcaster {
+	b.Lock()
+	defer b.Unlock()
+
+	if broadcaster, ok := b.broadcasters[informer]; ok {
+		return broadcaster
+	}
+
+	// informers replay existing objects to new handlers, watchers get them from store already
+	replayed := make(map[string]string)
+	for _, obj := range informer.GetStore().List() {
+		if key, version, ok := objectVersion(obj); ok {
+			replayed[key] = version
+		}
+	}
+
+	broadcaster := &broadcaster{queues: make(map[*eventQueue]bool)}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if key, version, ok := objectVersion(obj); ok {
+				if replayedVersion, ok := replayed[key]; ok {
+					delete(replayed, key)
+					if replayedVersion == version {
+						return
+					}
+				}
+			}
+			broadcaster.action(watch.Added, obj.(runtime.Object))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			broadcaster.update(watch.Modified, newObj.(runtime.Object), oldObj.(runtime.Object))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if object, ok := obj.(runtime.Object); ok {
+				broadcaster.action(watch.Deleted, object)
+			}
+		},
+	})
+	b.broadcasters[informer] = broadcaster
+	return broadcaster
+}
+
+// remove shuts down broadcaster of informer stopped
+func (b *informerBroadcasters) remove(informer cache.SharedIndexInformer) {
+	b.Lock()
+	defer b.Unlock()
+
+	if broadcaster, ok := b.broadcasters[informer]; ok {
+		broadcaster.stop()
+		delete(b.broadcasters, informer)
+	}
+}
+
+// watchedProvider is implemented by providers whose informers are stopped when idle, informers
+// keep running until release is called
+type watchedProvider interface {
+	Watched() (release func())
+}
+
+// informerWatcher sends objects in store of an informer as ADDED events, followed by events of
+// broadcaster. Events already reflected by the store are skipped by resource versions of objects.
+type informerWatcher struct {
+	broadcaster *broadcaster
+	queue       *eventQueue
+	result      chan watch.Event
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	release  func()
+}
+
+func (w *informerWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *informerWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		w.broadcaster.unwatch(w.queue)
+		if w.release != nil {
+			w.release()
+		}
+	})
+}
+
+// run sends existing events and events of queue matched by match, versions are resource versions
+// of existing objects by keys. Objects modified to be matched or no longer matched are sent as ADDED
+// or DELETED events, the same as filtered watches of kube-apiserver.
+func (w *informerWatcher) run(existing []watch.Event, versions map[string]uint64, match func(runtime.Object) bool) {
+	defer close(w.result)
+	send := func(event watch.Event) bool {
+		select {
+		case w.result <- event:
+			return true
+		case <-w.stopCh:
+			return false
+		}
+	}
+
+	for _, event := range existing {
+		if !send(event) {
+			return
+		}
+	}
+	for event := range w.queue.events {
+		// the store is updated before handlers are notified, events of changes listed already are
+		// skipped, the last state of deleted objects is the one in store so deletions are always sent
+		if key, version, ok := objectVersion(event.Object); ok && event.Type != watch.Deleted {
+			if listed, ok := versions[key]; ok {
+				if v, err := strconv.ParseUint(version, 10, 64); err == nil && v <= listed {
+					continue
+				}
+				delete(versions, key)
+			}
+		}
+		if event, ok := filter(event, match); ok && !send(event) {
+			return
+		}
+	}
+	if w.queue.overflow {
+		status := apierrors.NewResourceExpired("too many events are not received in time, watch again").Status()
+		send(watch.Event{Type: watch.Error, Object: &status})
+	}
+}
+
+// filter returns event seen by watchers matching objects by match, false if the event isn't seen
+func filter(event informerEvent, match func(runtime.Object) bool) (watch.Event, bool) {
+	matched := match(event.Object)
+	if event.Type != watch.Modified || event.old == nil {
+		return event.Event, matched
+	}
+	switch oldMatched := match(event.old); {
+	case oldMatched && !matched:
+		return watch.Event{Type: watch.Deleted, Object: event.old}, true
+	case !oldMatched && matched:
+		return watch.Event{Type: watch.Added, Object: event.Object}, true
+	default:
+		return event.Event, matched
+	}
+}
+
+// objectVersion returns key and resource version of object
+func objectVersion(obj interface{}) (string, string, bool) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return "", "", false
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", "", false
+	}
+	return key, accessor.GetResourceVersion(), true
+}
+
+// Watch watches resources of host cluster selected by query, ADDED events of existing objects
+// are sent first, followed by changes of objects as they happen. Watchers are registered before
+// the store is listed, so changes in between are not lost. Watchers too slow to keep up receive
+// an ERROR event of reason Expired, and the watch is closed.
+func (r *ResourceProcessor) Watch(resource, namespace string, q *query.QueryInfo) (watch.Interface, error) {
+	provider, ok := r.TryResource(namespace == "", resource).(alpha1.WatchableKubeResProvider)
+	if !ok {
+		return nil, ErrResourceNotSupported
+	}
+
+	match := func(obj runtime.Object) bool {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return false
+		}
+		if len(namespace) != 0 && accessor.GetNamespace() != namespace {
+			return false
+		}
+		return provider.Match(obj, q)
+	}
+
+	informer := provider.Informer()
+	broadcaster := r.broadcasters.get(informer)
+	w := &informerWatcher{
+		broadcaster: broadcaster,
+		queue:       broadcaster.watch(),
+		result:      make(chan watch.Event),
+		stopCh:      make(chan struct{}),
+	}
+	if watched, ok := provider.(watchedProvider); ok {
+		w.release = watched.Watched()
+	}
+
+	var existing []watch.Event
+	versions := make(map[string]uint64)
+	for _, obj := range informer.GetStore().List() {
+		object, ok := obj.(runtime.Object)
+		if !ok {
+			continue
+		}
+		if key, version, ok := objectVersion(object); ok {
+			if v, err := strconv.ParseUint(version, 10, 64); err == nil {
+				versions[key] = v
+			}
+		}
+		if match(object) {
+			existing = append(existing, watch.Event{Type: watch.Added, Object: object})
+		}
+	}
+	go w.run(existing, versions, match)
+	return w, nil
+}
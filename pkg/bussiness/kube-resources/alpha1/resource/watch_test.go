@@ -0,0 +1,100 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/unify/query"
+)
+
+func TestWatchStatus(t *testing.T) {
+	newPod := func(name, reason string) *v1.Pod {
+		state := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+		if len(reason) != 0 {
+			state = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}}
+		}
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{Name: "app", State: state}}},
+		}
+	}
+	client := fake.NewSimpleClientset(newPod("web", ""), newPod("worker", "CrashLoopBackOff"))
+	factory := informers.NewSharedInformerFactory(client, 0)
+	r := &ResourceProcessor{
+		namespacedResourceProcessors: map[schema.GroupVersionResource]alpha1.KubeResProvider{PodGVR: pod.New(factory)},
+		broadcasters:                 newInformerBroadcasters(),
+	}
+	informer := factory.Core().V1().Pods().Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, informer.HasSynced)
+
+	q := query.New()
+	q.Filters = map[query.Field]query.Value{query.FieldStatus: "CrashLoopBackOff"}
+	w, err := r.Watch("pods", "default", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	next := func() watch.Event {
+		select {
+		case event := <-w.ResultChan():
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event is received")
+		}
+		return watch.Event{}
+	}
+	if event := next(); event.Type != watch.Added || event.Object.(*v1.Pod).Name != "worker" {
+		t.Errorf("expected worker added, got %s %s", event.Type, event.Object.(*v1.Pod).Name)
+	}
+
+	// pods modified to be matched, still matched and no longer matched are added, modified and deleted
+	update := func(pod *v1.Pod) {
+		t.Helper()
+		if _, err := client.CoreV1().Pods("default").Update(context.Background(), pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	update(newPod("web", "CrashLoopBackOff"))
+	if event := next(); event.Type != watch.Added || event.Object.(*v1.Pod).Name != "web" {
+		t.Errorf("expected web added, got %s %s", event.Type, event.Object.(*v1.Pod).Name)
+	}
+	restarted := newPod("web", "CrashLoopBackOff")
+	restarted.Labels = map[string]string{"restarted": "true"}
+	update(restarted)
+	if event := next(); event.Type != watch.Modified || event.Object.(*v1.Pod).Labels["restarted"] != "true" {
+		t.Errorf("expected web modified, got %s %v", event.Type, event.Object)
+	}
+	update(newPod("web", ""))
+	if event := next(); event.Type != watch.Deleted || event.Object.(*v1.Pod).Name != "web" {
+		t.Errorf("expected web deleted, got %s %s", event.Type, event.Object.(*v1.Pod).Name)
+	}
+
+	// watchers too slow to keep up are closed with an expired error
+	worker := newPod("worker", "CrashLoopBackOff")
+	for i := 0; i < watchQueueLength+2; i++ {
+		r.broadcasters.get(informer).action(watch.Modified, worker)
+	}
+	var last watch.Event
+	for event := range w.ResultChan() {
+		last = event
+	}
+	if status, ok := last.Object.(*metav1.Status); last.Type != watch.Error || !ok || status.Reason != metav1.StatusReasonExpired {
+		t.Errorf("expected expired error, got %s %v", last.Type, last.Object)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"captain/pkg/bussiness/kube-resources/alpha1"
 	"captain/pkg/unify/query"
@@ -16,11 +17,17 @@ type mcResourceQuotaProvider struct {
 	clusterclient.ClusterClients
 }
 
+var resourcequotasGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+
 func NewMCResProvider(clients clusterclient.ClusterClients) mcResourceQuotaProvider {
 	return mcResourceQuotaProvider{ClusterClients: clients}
 }
 
 func (pd mcResourceQuotaProvider) Get(region, cluster, namespace, name string) (runtime.Object, error) {
+	if lister, ok := alpha1.ClusterLister(pd, region, cluster, resourcequotasGVR); ok {
+		return lister.ByNamespace(namespace).Get(name)
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -30,6 +37,10 @@ func (pd mcResourceQuotaProvider) Get(region, cluster, namespace, name string) (
 }
 
 func (pd mcResourceQuotaProvider) List(region, cluster, namespace string, query *query.QueryInfo) (*response.ListResult, error) {
+	if result, ok, err := alpha1.ListFromCluster(pd, region, cluster, resourcequotasGVR, namespace, query, compareFunc, filter); ok {
+		return result, err
+	}
+
 	cli, err := pd.GetClientSet(region, cluster)
 	if err != nil {
 		return nil, err
@@ -46,5 +57,5 @@ func (pd mcResourceQuotaProvider) List(region, cluster, namespace string, query
 		}
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
@@ -8,8 +8,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of resource quotas in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type resourcequotaProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -33,7 +40,7 @@ func (rq resourcequotaProvider) List(namespace string, query *query.QueryInfo) (
 		result = append(result, resourceQuota)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -59,3 +66,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftrq.ObjectMeta, rightrq.ObjectMeta, field)
 }
+
+func (rq resourcequotaProvider) Informer() cache.SharedIndexInformer {
+	return rq.sharedInformers.Core().V1().ResourceQuotas().Informer()
+}
+
+func (rq resourcequotaProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -7,8 +7,15 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of roles in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type roleProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -37,7 +44,7 @@ func (cr roleProvider) List(namespace string, query *query.QueryInfo) (*response
 		result = append(result, role)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -64,3 +71,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftRole.ObjectMeta, rightRole.ObjectMeta, field)
 }
+
+func (cr roleProvider) Informer() cache.SharedIndexInformer {
+	return cr.informers.Rbac().V1().Roles().Informer()
+}
+
+func (cr roleProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -7,8 +7,17 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of role bindings in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Role", ".roleRef.kind", 0),
+	alpha1.Column("Role Name", ".roleRef.name", 0),
+	alpha1.AgeColumn,
+}
+
 type rolebindingProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -37,7 +46,7 @@ func (cr rolebindingProvider) List(namespace string, query *query.QueryInfo) (*r
 		result = append(result, roleBinding)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -63,3 +72,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftRoleBinding.ObjectMeta, rightRoleBinding.ObjectMeta, field)
 }
+
+func (cr rolebindingProvider) Informer() cache.SharedIndexInformer {
+	return cr.informers.Rbac().V1().RoleBindings().Informer()
+}
+
+func (cr rolebindingProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
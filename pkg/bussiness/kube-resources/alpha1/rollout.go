@@ -0,0 +1,275 @@
+package alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"captain/pkg/unify/response"
+)
+
+const (
+	// RestartedAtAnnotation is set on pod template to restart pods of workloads, the same as kubectl rollout restart
+	RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// ChangeCauseAnnotation tells why a revision is made
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+
+	diffContext = 3
+)
+
+// Rollouter rolls out workloads through client of the cluster they are in, actions not supported
+// by a kind of workloads return MethodNotSupported errors. Workloads are returned as updated.
+type Rollouter interface {
+	// Restart restarts pods of workload by updating annotation of pod template
+	Restart(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error)
+
+	// Scale sets replicas of workload
+	Scale(ctx context.Context, client kubernetes.Interface, namespace, name string, replicas int32) (runtime.Object, error)
+
+	// Pause pauses rollouts of workload
+	Pause(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error)
+
+	// Resume resumes rollouts of paused workload
+	Resume(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error)
+
+	// Rollback rolls back pod template of workload to revision, 0 is the previous revision
+	Rollback(ctx context.Context, client kubernetes.Interface, namespace, name string, revision int64) (runtime.Object, error)
+
+	// History returns revisions of workload with diffs of pod templates
+	History(ctx context.Context, client kubernetes.Interface, namespace, name string) (*response.RevisionHistory, error)
+}
+
+// NewActionNotSupported returns error of action not supported by resource
+func NewActionNotSupported(resource, action string) error {
+	return apierrors.NewMethodNotSupported(appsv1.Resource(resource), action)
+}
+
+// RestartPatch returns strategic merge patch restarting pods of workload
+func RestartPatch() []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		RestartedAtAnnotation, time.Now().Format(time.RFC3339)))
+}
+
+// ReplicasPatch returns merge patch setting replicas of workload
+func ReplicasPatch(replicas int32) []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+}
+
+// ControllerRevisions returns ControllerRevisions controlled by owner, they are selected by selector of owner
+func ControllerRevisions(ctx context.Context, client kubernetes.Interface, owner metav1.Object, selector *metav1.LabelSelector) ([]*appsv1.ControllerRevision, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.AppsV1().ControllerRevisions(owner.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*appsv1.ControllerRevision
+	for i := range list.Items {
+		if ref := metav1.GetControllerOf(&list.Items[i]); ref != nil && ref.UID == owner.GetUID() {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// FindControllerRevision returns revision of number in revisions sorted by ControllerRevisions, the
+// previous revision is returned if number is 0
+func FindControllerRevision(revisions []*appsv1.ControllerRevision, number int64) (*appsv1.ControllerRevision, error) {
+	if number == 0 {
+		if len(revisions) < 2 {
+			return nil, apierrors.NewBadRequest("no previous revision to roll back to")
+		}
+		return revisions[len(revisions)-2], nil
+	}
+	for _, revision := range revisions {
+		if revision.Revision == number {
+			return revision, nil
+		}
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("revision %d is not found", number))
+}
+
+// ApplyControllerRevision applies patch of revision to current, patched object is decoded into into
+func ApplyControllerRevision(current runtime.Object, revision *appsv1.ControllerRevision, into runtime.Object) error {
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, revision.Data.Raw, current)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(patched, into)
+}
+
+// NewRevisionHistory sorts revisions by revision numbers and fills diffs of pod templates from previous revisions
+func NewRevisionHistory(revisions []response.Revision) (*response.RevisionHistory, error) {
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	var previous string
+	for i := range revisions {
+		template, err := yaml.Marshal(revisions[i].Template)
+		if err != nil {
+			return nil, err
+		}
+		if i != 0 {
+			revisions[i].Diff = UnifiedDiff(fmt.Sprintf("revision %d", revisions[i-1].Revision),
+				fmt.Sprintf("revision %d", revisions[i].Revision), previous, string(template))
+		}
+		previous = string(template)
+	}
+	if revisions == nil {
+		revisions = []response.Revision{}
+	}
+	return &response.RevisionHistory{Items: revisions}, nil
+}
+
+// TemplateEqual returns true if pod templates are the same
+func TemplateEqual(a, b *corev1.PodTemplateSpec) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// edit is a line of diff, op is one of ' ', '-' and '+'
+type edit struct {
+	op   byte
+	line string
+}
+
+// UnifiedDiff returns unified diff of lines from from to to with 3 lines of context, it's empty if they are the same
+func UnifiedDiff(fromName, toName, from, to string) string {
+	edits := lineEdits(splitLines(from), splitLines(to))
+
+	// line numbers of from and to before each edit
+	fromLines := make([]int, len(edits)+1)
+	toLines := make([]int, len(edits)+1)
+	for i, e := range edits {
+		fromLines[i+1], toLines[i+1] = fromLines[i], toLines[i]
+		if e.op != '+' {
+			fromLines[i+1]++
+		}
+		if e.op != '-' {
+			toLines[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(edits); {
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		// changes separated by less than twice of context are in the same hunk
+		last := first
+		for i := first + 1; i < len(edits) && i-last-1 <= 2*diffContext; i++ {
+			if edits[i].op != ' ' {
+				last = i
+			}
+		}
+
+		lo, hi := first-diffContext, last+diffContext+1
+		if lo < start {
+			lo = start
+		}
+		if hi > len(edits) {
+			hi = len(edits)
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(fromLines[lo], fromLines[hi]-fromLines[lo]),
+			hunkRange(toLines[lo], toLines[hi]-toLines[lo]))
+		for _, e := range edits[lo:hi] {
+			b.WriteByte(e.op)
+			b.WriteString(e.line)
+			b.WriteByte('\n')
+		}
+		start = hi
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", fromName, toName, b.String())
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineEdits returns edits from a to b of the longest common subsequence
+func lineEdits(a, b []string) []edit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{op: ' ', line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{op: '-', line: a[i]})
+			i++
+		default:
+			edits = append(edits, edit{op: '+', line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, edit{op: '-', line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, edit{op: '+', line: b[j]})
+	}
+	return edits
+}
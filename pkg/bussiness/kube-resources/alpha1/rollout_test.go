@@ -0,0 +1,71 @@
+package alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"captain/pkg/unify/response"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		expected string
+	}{
+		{
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			from:     "a\nb\nc\n",
+			to:       "a\nx\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			from:     "",
+			to:       "a\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			// changes far apart are in different hunks
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:       "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+13\n",
+		},
+		{
+			// changes close to each other are in the same hunk
+			from:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			to:       "0\n2\n3\n4\n5\n6\n7\n9\n",
+			expected: "--- old\n+++ new\n@@ -1,8 +1,8 @@\n-1\n+0\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+9\n",
+		},
+	}
+
+	for i, test := range tests {
+		if got := UnifiedDiff("old", "new", test.from, test.to); got != test.expected {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, test.expected, got)
+		}
+	}
+}
+
+func TestNewRevisionHistory(t *testing.T) {
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}}}
+	}
+	history, err := NewRevisionHistory([]response.Revision{
+		{Revision: 2, Template: template("nginx:1.21")},
+		{Revision: 1, Template: template("nginx:1.20")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Items) != 2 || history.Items[0].Revision != 1 || history.Items[0].Diff != "" {
+		t.Fatalf("unexpected history %+v", history.Items)
+	}
+	expected := "--- revision 1\n+++ revision 2\n@@ -2,6 +2,6 @@\n   creationTimestamp: null\n spec:\n   containers:\n-  - image: nginx:1.20\n+  - image: nginx:1.21\n     name: app\n     resources: {}\n"
+	if history.Items[1].Diff != expected {
+		t.Errorf("expected diff\n%s\ngot\n%s", expected, history.Items[1].Diff)
+	}
+}
@@ -0,0 +1,141 @@
+package alpha1
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scores of matches of search terms, matches of names rank higher than labels and annotations
+const (
+	scoreNameExact     = 100
+	scoreNamePrefix    = 80
+	scoreNamePattern   = 70
+	scoreNameSubstring = 60
+	scoreLabel         = 40
+	scoreAnnotation    = 20
+)
+
+// matchName is reported for matches of names, matches of labels and annotations are reported the
+// same as GroupBy fields, e.g. label:app
+const matchName = "name"
+
+// SearchMatcher matches metadata of objects with search terms separated by spaces, all terms must
+// match. A term of key=value matches labels, other terms match names, labels and keys of annotations.
+// Names and values may be patterns of * and ?, e.g. payment-*.
+type SearchMatcher struct {
+	terms []searchTerm
+}
+
+type searchTerm struct {
+	key, value string
+	// pattern is true if value is a pattern of * and ?
+	pattern bool
+}
+
+// NewSearchMatcher returns matcher of search text, terms are case insensitive
+func NewSearchMatcher(text string) *SearchMatcher {
+	matcher := &SearchMatcher{}
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		term := searchTerm{value: word}
+		if kv := strings.SplitN(word, "=", 2); len(kv) == 2 && len(kv[0]) != 0 {
+			term.key, term.value = kv[0], kv[1]
+		}
+		term.pattern = strings.ContainsAny(term.value, "*?[")
+		matcher.terms = append(matcher.terms, term)
+	}
+	return matcher
+}
+
+// Empty returns true if there are no terms, which matches nothing
+func (m *SearchMatcher) Empty() bool {
+	return len(m.terms) == 0
+}
+
+// Match returns score of object and fields matched, e.g. name and label:app, score is 0 if
+// object doesn't match all terms
+func (m *SearchMatcher) Match(object metav1.Object) (int, []string) {
+	if m.Empty() {
+		return 0, nil
+	}
+	var score int
+	var matches []string
+	for _, term := range m.terms {
+		termScore, match := term.match(object)
+		if termScore == 0 {
+			return 0, nil
+		}
+		score += termScore
+		matches = appendMatch(matches, match)
+	}
+	return score, matches
+}
+
+func appendMatch(matches []string, match string) []string {
+	for _, m := range matches {
+		if m == match {
+			return matches
+		}
+	}
+	return append(matches, match)
+}
+
+func (t searchTerm) match(object metav1.Object) (int, string) {
+	if len(t.key) != 0 {
+		for _, key := range sortedKeys(object.GetLabels()) {
+			if value := object.GetLabels()[key]; strings.ToLower(key) == t.key && t.matchValue(strings.ToLower(value)) {
+				return scoreLabel, GroupByLabelPrefix + key
+			}
+		}
+		return 0, ""
+	}
+
+	name := strings.ToLower(object.GetName())
+	switch {
+	case t.pattern:
+		if t.matchValue(name) {
+			return scoreNamePattern, matchName
+		}
+	case name == t.value:
+		return scoreNameExact, matchName
+	case strings.HasPrefix(name, t.value):
+		return scoreNamePrefix, matchName
+	case strings.Contains(name, t.value):
+		return scoreNameSubstring, matchName
+	}
+
+	for _, key := range sortedKeys(object.GetLabels()) {
+		if value := object.GetLabels()[key]; t.matchValue(strings.ToLower(key)) || t.matchValue(strings.ToLower(value)) {
+			return scoreLabel, GroupByLabelPrefix + key
+		}
+	}
+	// values of annotations are not matched, they may hold whole manifests like last-applied-configuration
+	for _, key := range sortedKeys(object.GetAnnotations()) {
+		if t.matchValue(strings.ToLower(key)) {
+			return scoreAnnotation, GroupByAnnotationPrefix + key
+		}
+	}
+	return 0, ""
+}
+
+// sortedKeys returns keys of labels or annotations in order, so that the same match is reported
+// across requests
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matchValue returns true if value is the same as term, or matches pattern of term
+func (t searchTerm) matchValue(value string) bool {
+	if !t.pattern {
+		return value == t.value
+	}
+	matched, err := path.Match(t.value, value)
+	return err == nil && matched
+}
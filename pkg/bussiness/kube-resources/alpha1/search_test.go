@@ -0,0 +1,42 @@
+package alpha1
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSearchMatcher(t *testing.T) {
+	object := &metav1.ObjectMeta{
+		Name:        "payment-api",
+		Labels:      map[string]string{"team": "Checkout", "app": "payment"},
+		Annotations: map[string]string{"description": "handles card payments"},
+	}
+
+	tests := []struct {
+		text    string
+		score   int
+		matches []string
+	}{
+		{text: "payment-api", score: scoreNameExact, matches: []string{"name"}},
+		{text: "Payment", score: scoreNamePrefix, matches: []string{"name"}},
+		{text: "payment-*", score: scoreNamePattern, matches: []string{"name"}},
+		{text: "api", score: scoreNameSubstring, matches: []string{"name"}},
+		{text: "team=checkout", score: scoreLabel, matches: []string{"label:team"}},
+		{text: "team=check*", score: scoreLabel, matches: []string{"label:team"}},
+		{text: "checkout", score: scoreLabel, matches: []string{"label:team"}},
+		{text: "descr*", score: scoreAnnotation, matches: []string{"annotation:description"}},
+		{text: "card", score: 0},
+		{text: "pay* team=checkout", score: scoreNamePattern + scoreLabel, matches: []string{"name", "label:team"}},
+		{text: "payment team=billing", score: 0},
+		{text: "order-*", score: 0},
+		{text: "  ", score: 0},
+	}
+	for _, test := range tests {
+		score, matches := NewSearchMatcher(test.text).Match(object)
+		if score != test.score || fmt.Sprint(matches) != fmt.Sprint(test.matches) {
+			t.Errorf("%q: expected %d %v, got %d %v", test.text, test.score, test.matches, score, matches)
+		}
+	}
+}
@@ -8,8 +8,16 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of secrets in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Type", ".type", 0),
+	alpha1.AgeColumn,
+}
+
 type secretProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -33,7 +41,7 @@ func (s secretProvider) List(namespace string, query *query.QueryInfo) (*respons
 		result = append(result, sc)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func compareFunc(left, right runtime.Object, field query.Field) bool {
@@ -59,3 +67,11 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	return alpha1.DefaultObjectMetaFilter(secret.ObjectMeta, filter)
 }
+
+func (s secretProvider) Informer() cache.SharedIndexInformer {
+	return s.sharedInformers.Core().V1().Secrets().Informer()
+}
+
+func (s secretProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -7,8 +7,19 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of services in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Type", ".spec.type", 0),
+	alpha1.Column("Cluster-IP", ".spec.clusterIP", 0),
+	alpha1.Column("External-IP", ".status.loadBalancer.ingress[*].ip", 0),
+	alpha1.Column("Ports", ".spec.ports[*].port", 0),
+	alpha1.AgeColumn,
+}
+
 type serviceProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -32,7 +43,7 @@ func (svc serviceProvider) List(namespace string, query *query.QueryInfo) (*resp
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -58,3 +69,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftService.ObjectMeta, rightService.ObjectMeta, field)
 }
+
+func (svc serviceProvider) Informer() cache.SharedIndexInformer {
+	return svc.sharedInformers.Core().V1().Services().Informer()
+}
+
+func (svc serviceProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
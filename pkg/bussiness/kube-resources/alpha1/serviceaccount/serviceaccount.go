@@ -7,8 +7,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of service accounts in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.AgeColumn,
+}
+
 type serviceaccountProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -33,7 +40,7 @@ func (cr serviceaccountProvider) List(namespace string, query *query.QueryInfo)
 	for _, serviceaccount := range serviceaccounts {
 		result = append(result, serviceaccount)
 	}
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -59,3 +66,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 
 	return alpha1.DefaultObjectMetaCompare(leftCM.ObjectMeta, rightCM.ObjectMeta, field)
 }
+
+func (cr serviceaccountProvider) Informer() cache.SharedIndexInformer {
+	return cr.informers.Core().V1().ServiceAccounts().Informer()
+}
+
+func (cr serviceaccountProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -0,0 +1,266 @@
+package alpha1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"captain/pkg/unify/query"
+)
+
+// DefaultSortKeys are sort keys of all resources besides JSONPaths
+var DefaultSortKeys = []query.Field{query.FieldName, query.FieldCreationTimeStamp}
+
+// sortKeyAliases are other names of sort keys accepted for compatibility
+var sortKeyAliases = map[query.Field]query.Field{
+	query.FieldCreateTime:          query.FieldCreationTimeStamp,
+	query.FieldLastUpdateTimestamp: query.FieldUpdateTime,
+}
+
+// SortKeys returns DefaultSortKeys followed by keys specific to a resource
+func SortKeys(keys ...query.Field) []query.Field {
+	return append(append([]query.Field{}, DefaultSortKeys...), keys...)
+}
+
+// ValidateSortBy returns a bad request if sortBy is neither a JSONPath nor one of keys
+func ValidateSortBy(sortBy query.Field, keys []query.Field) error {
+	if sortBy.IsJSONPath() {
+		return nil
+	}
+	key := sortBy
+	if alias, ok := sortKeyAliases[sortBy]; ok {
+		key = alias
+	}
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == key {
+			return nil
+		}
+		names = append(names, string(k))
+	}
+	return apierrors.NewBadRequest(fmt.Sprintf("unsupported %s %s, supported keys are %s and JSONPaths", query.ParameterOrderBy, sortBy, strings.Join(names, ", ")))
+}
+
+// newJSONPathCompareFunc returns CompareFunc comparing values of JSONPath sortBy, the first value
+// found in each object is compared by compareValues.
+func newJSONPathCompareFunc(sortBy query.Field, objects []runtime.Object) (CompareFunc, error) {
+	key, err := newSortKey([]string{sortBy.JSONPathTemplate()}, objects)
+	if err != nil {
+		return nil, err
+	}
+	return func(left, right runtime.Object, _ query.Field) bool {
+		return key.compare(key.valuesOf(left), key.valuesOf(right)) < 0
+	}, nil
+}
+
+// sortKey is a sort key made of JSONPaths, which are compared in order. Values are evaluated once
+// per object, as converting objects to unstructured content is expensive. Strings of each path are
+// compared the same way for all objects, which is chosen by string values of objects, so that the
+// order is transitive.
+type sortKey struct {
+	parsers []*jsonpath.JSONPath
+	orders  []stringOrder
+	values  map[runtime.Object][]interface{}
+}
+
+func newSortKey(paths []string, objects []runtime.Object) (*sortKey, error) {
+	parsers, err := parseJSONPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	key := &sortKey{parsers: parsers, values: make(map[runtime.Object][]interface{})}
+	for i := range parsers {
+		var strs []string
+		for _, object := range objects {
+			if value, ok := key.valuesOf(object)[i].(string); ok {
+				strs = append(strs, value)
+			}
+		}
+		key.orders = append(key.orders, stringOrderOf(strs))
+	}
+	return key, nil
+}
+
+// valuesOf returns the first value found by each path in object, nil if none is found
+func (k *sortKey) valuesOf(object runtime.Object) []interface{} {
+	if values, ok := k.values[object]; ok {
+		return values
+	}
+	values := make([]interface{}, len(k.parsers))
+	if content, err := toUnstructured(object); err == nil {
+		for i, parser := range k.parsers {
+			if found := evaluate(parser, content); len(found) != 0 {
+				values[i] = found[0]
+			}
+		}
+	}
+	k.values[object] = values
+	return values
+}
+
+// compare compares values of paths in order by compareValues
+func (k *sortKey) compare(left, right []interface{}) int {
+	for i, order := range k.orders {
+		if c := compareValues(left[i], right[i], order); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// stringOrder is how strings of a sort key are compared
+type stringOrder int
+
+const (
+	lexicalOrder stringOrder = iota
+	timeOrder
+	quantityOrder
+)
+
+// stringOrderOf returns timeOrder if all values are timestamps, quantityOrder if all values are
+// quantities, and lexicalOrder otherwise
+func stringOrderOf(values []string) stringOrder {
+	if len(values) == 0 {
+		return lexicalOrder
+	}
+	for _, order := range []stringOrder{timeOrder, quantityOrder} {
+		parsed := true
+		for _, value := range values {
+			if _, ok := parseString(value, order); !ok {
+				parsed = false
+				break
+			}
+		}
+		if parsed {
+			return order
+		}
+	}
+	return lexicalOrder
+}
+
+// compareValues compares values of JSONPath by their types, numbers are compared numerically,
+// strings are compared in order, e.g. 2Gi is greater than 512Mi in quantityOrder. Missing values
+// are less than any value, values of different types are ordered by types.
+func compareValues(left, right interface{}, order stringOrder) int {
+	leftRank, rightRank := valueRank(left), valueRank(right)
+	if leftRank != rightRank {
+		return leftRank - rightRank
+	}
+
+	switch l := left.(type) {
+	case nil:
+		return 0
+	case bool:
+		r := right.(bool)
+		if l == r {
+			return 0
+		} else if !l {
+			return -1
+		}
+		return 1
+	case string:
+		return compareStrings(l, right.(string), order)
+	}
+
+	if leftRank == rankNumber {
+		// integers are compared exactly, large int64 values lose precision as float64
+		if l, ok := left.(int64); ok {
+			if r, ok := right.(int64); ok {
+				if l < r {
+					return -1
+				} else if l > r {
+					return 1
+				}
+				return 0
+			}
+		}
+		l, r := toFloat(left), toFloat(right)
+		if l < r {
+			return -1
+		} else if l > r {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+// compareStrings compares strings in order, strings failed to parse in order are greater than
+// those parsed, and are compared lexically, e.g. the last object of a continue token
+func compareStrings(left, right string, order stringOrder) int {
+	l, lok := parseString(left, order)
+	r, rok := parseString(right, order)
+	switch {
+	case lok && rok:
+		return l.Cmp(r)
+	case lok:
+		return -1
+	case rok:
+		return 1
+	default:
+		return strings.Compare(left, right)
+	}
+}
+
+// parseString parses value as a timestamp in timeOrder, which is a quantity of nanoseconds, or as a
+// quantity in quantityOrder. Strings are never parsed in lexicalOrder.
+func parseString(value string, order stringOrder) (resource.Quantity, bool) {
+	switch order {
+	case timeOrder:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return resource.Quantity{}, false
+		}
+		return *resource.NewScaledQuantity(t.UnixNano(), resource.Nano), true
+	case quantityOrder:
+		q, err := resource.ParseQuantity(value)
+		return q, err == nil
+	default:
+		return resource.Quantity{}, false
+	}
+}
+
+const (
+	rankMissing = iota
+	rankBool
+	rankNumber
+	rankString
+	rankOther
+)
+
+func valueRank(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return rankMissing
+	case bool:
+		return rankBool
+	case int, int32, int64, float32, float64:
+		return rankNumber
+	case string:
+		return rankString
+	default:
+		return rankOther
+	}
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,138 @@
+package alpha1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"captain/pkg/unify/query"
+)
+
+func TestJSONPathSort(t *testing.T) {
+	now := time.Now()
+	newClaim := func(name, storage string, restarts int, started time.Duration) *corev1.PersistentVolumeClaim {
+		claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{"restarts": fmt.Sprint(restarts)},
+		}}
+		if len(storage) != 0 {
+			claim.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(storage)}
+		}
+		if started != 0 {
+			claim.CreationTimestamp = metav1.NewTime(now.Add(started))
+		}
+		return claim
+	}
+	objects := []runtime.Object{
+		newClaim("a", "2Gi", 10, time.Hour),
+		newClaim("b", "512Mi", 9, -time.Hour),
+		newClaim("c", "", 100, time.Minute),
+		newClaim("d", "1Ti", 2, 0),
+	}
+	compare := func(left, right runtime.Object, field query.Field) bool {
+		return DefaultObjectMetaCompare(left.(*corev1.PersistentVolumeClaim).ObjectMeta, right.(*corev1.PersistentVolumeClaim).ObjectMeta, field)
+	}
+	filter := func(runtime.Object, query.Filter) bool { return true }
+
+	tests := []struct {
+		sortBy    query.Field
+		ascending bool
+		expected  []string
+	}{
+		// quantities are compared by values, missing values are the smallest
+		{sortBy: ".status.capacity.storage", ascending: true, expected: []string{"c", "b", "a", "d"}},
+		{sortBy: "{.status.capacity.storage}", expected: []string{"d", "a", "b", "c"}},
+		// numeric strings are compared as numbers
+		{sortBy: ".metadata.annotations.restarts", ascending: true, expected: []string{"d", "b", "a", "c"}},
+		// timestamps are compared as time, null timestamps are missing
+		{sortBy: ".metadata.creationTimestamp", ascending: true, expected: []string{"d", "b", "c", "a"}},
+		{sortBy: ".metadata.name", expected: []string{"d", "c", "b", "a"}},
+	}
+	for _, test := range tests {
+		q := query.New()
+		q.Pagination = query.NoPagination
+		q.SortBy = test.sortBy
+		q.Ascending = test.ascending
+		result, err := DefaultList(objects, q, compare, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, item := range result.Items {
+			names = append(names, item.(*corev1.PersistentVolumeClaim).Name)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(test.expected) {
+			t.Errorf("%s ascending %v: expected %v, got %v", test.sortBy, test.ascending, test.expected, names)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		left, right interface{}
+		expected    int
+	}{
+		{left: int64(2), right: int64(10), expected: -1},
+		{left: int64(3), right: 2.5, expected: 1},
+		{left: "100m", right: "1", expected: -1},
+		{left: "2022-01-02T00:00:00+08:00", right: "2022-01-01T17:00:00Z", expected: -1},
+		{left: "web-10", right: "web-9", expected: -1},
+		{left: true, right: false, expected: 1},
+		{left: nil, right: "", expected: -1},
+		{left: nil, right: nil, expected: 0},
+	}
+	for _, test := range tests {
+		var strs []string
+		for _, value := range []interface{}{test.left, test.right} {
+			if str, ok := value.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		if got := compareValues(test.left, test.right, stringOrderOf(strs)); sign(got) != test.expected {
+			t.Errorf("compare %v and %v: expected %d, got %d", test.left, test.right, test.expected, got)
+		}
+	}
+
+	// strings are compared the same way for all values, 5 < 10 as quantities but 10 < 3a < 5 lexically
+	values := []string{"5", "10", "3a"}
+	order := stringOrderOf(values)
+	if order != lexicalOrder {
+		t.Fatalf("expected lexical order of %v, got %v", values, order)
+	}
+	if compareValues("10", "3a", order) >= 0 || compareValues("3a", "5", order) >= 0 || compareValues("10", "5", order) >= 0 {
+		t.Errorf("expected 10 < 3a < 5 in lexical order")
+	}
+	// strings failed to parse are greater than those parsed
+	if order := stringOrderOf([]string{"1Gi", "512Mi"}); order != quantityOrder || compareValues("abc", "1Gi", order) <= 0 {
+		t.Errorf("expected unparsed strings to be greater in quantity order")
+	}
+}
+
+func TestValidateSortBy(t *testing.T) {
+	keys := SortKeys(query.FieldUpdateTime)
+	for _, sortBy := range []query.Field{query.FieldName, query.FieldUpdateTime, query.FieldCreateTime, query.FieldLastUpdateTimestamp, ".spec.replicas"} {
+		if err := ValidateSortBy(sortBy, keys); err != nil {
+			t.Errorf("%s: unexpected error %v", sortBy, err)
+		}
+	}
+	if err := ValidateSortBy("replicas", keys); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request, got %v", err)
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,90 @@
+package statefulset
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/response"
+)
+
+type statefulSetRollouter struct{}
+
+func NewRollouter() statefulSetRollouter {
+	return statefulSetRollouter{}
+}
+
+func (sr statefulSetRollouter) Restart(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, alpha1.RestartPatch(), metav1.PatchOptions{})
+}
+
+func (sr statefulSetRollouter) Scale(ctx context.Context, client kubernetes.Interface, namespace, name string, replicas int32) (runtime.Object, error) {
+	return client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, alpha1.ReplicasPatch(replicas), metav1.PatchOptions{})
+}
+
+func (sr statefulSetRollouter) Pause(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return nil, alpha1.NewActionNotSupported("statefulsets", "pause")
+}
+
+func (sr statefulSetRollouter) Resume(ctx context.Context, client kubernetes.Interface, namespace, name string) (runtime.Object, error) {
+	return nil, alpha1.NewActionNotSupported("statefulsets", "resume")
+}
+
+// Rollback applies ControllerRevision of revision to statefulset, the same as kubectl rollout undo
+func (sr statefulSetRollouter) Rollback(ctx context.Context, client kubernetes.Interface, namespace, name string, revision int64) (runtime.Object, error) {
+	sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := alpha1.ControllerRevisions(ctx, client, sts, sts.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	target, err := alpha1.FindControllerRevision(revisions, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := &appsv1.StatefulSet{}
+	if err := alpha1.ApplyControllerRevision(sts, target, applied); err != nil {
+		return nil, err
+	}
+	if alpha1.TemplateEqual(&applied.Spec.Template, &sts.Spec.Template) {
+		return sts, nil
+	}
+	return client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, target.Data.Raw, metav1.PatchOptions{})
+}
+
+// History returns revisions of statefulset built from ControllerRevisions owned by it
+func (sr statefulSetRollouter) History(ctx context.Context, client kubernetes.Interface, namespace, name string) (*response.RevisionHistory, error) {
+	sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	controllerRevisions, err := alpha1.ControllerRevisions(ctx, client, sts, sts.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []response.Revision
+	for _, revision := range controllerRevisions {
+		applied := &appsv1.StatefulSet{}
+		if err := alpha1.ApplyControllerRevision(sts, revision, applied); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, response.Revision{
+			Revision:          revision.Revision,
+			Name:              revision.Name,
+			CreationTimestamp: revision.CreationTimestamp,
+			ChangeCause:       revision.Annotations[alpha1.ChangeCauseAnnotation],
+			Current:           revision.Name == sts.Status.UpdateRevision,
+			Template:          applied.Spec.Template,
+		})
+	}
+	return alpha1.NewRevisionHistory(revisions)
+}
@@ -0,0 +1,79 @@
+package statefulset
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRollout(t *testing.T) {
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "mysql", Image: image}}},
+		}
+	}
+	controller := true
+	// data of ControllerRevisions of statefulsets replaces pod template, the same as the controller does
+	controllerRevision := func(name string, revision int64, image string) *appsv1.ControllerRevision {
+		tpl := template(image)
+		raw, _ := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"$patch":   "replace",
+					"metadata": tpl.ObjectMeta,
+					"spec":     tpl.Spec,
+				},
+			},
+		})
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          map[string]string{"app": "db"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", UID: "db-uid", Controller: &controller}},
+			},
+			Data:     runtime.RawExtension{Raw: raw},
+			Revision: revision,
+		}
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "db-uid"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: template("mysql:8.0"),
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "db-2"},
+	}
+	client := fake.NewSimpleClientset(sts, controllerRevision("db-1", 1, "mysql:5.7"), controllerRevision("db-2", 2, "mysql:8.0"))
+	rollouter := NewRollouter()
+	ctx := context.Background()
+
+	history, err := rollouter.History(ctx, client, "default", "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Items) != 2 || history.Items[0].Template.Spec.Containers[0].Image != "mysql:5.7" ||
+		history.Items[0].Current || !history.Items[1].Current || len(history.Items[1].Diff) == 0 {
+		t.Errorf("unexpected history %+v", history.Items)
+	}
+
+	object, err := rollouter.Rollback(ctx, client, "default", "db", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := object.(*appsv1.StatefulSet).Spec.Template.Spec.Containers[0].Image; image != "mysql:5.7" {
+		t.Errorf("expected image mysql:5.7 after rollback, got %s", image)
+	}
+
+	if _, err := rollouter.Pause(ctx, client, "default", "db"); !apierrors.IsMethodNotSupported(err) {
+		t.Errorf("expected method not supported of pause, got %v", err)
+	}
+}
@@ -8,16 +8,32 @@ import (
 	"time"
 
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
 	StatusStopped  = "stopped"
 	StatusRunning  = "running"
 	StatusUpdating = "updating"
+
+	fieldReplicas = "replicas"
 )
 
+// TableColumns are default columns of stateful sets in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Replicas", ".spec.replicas", 0),
+	alpha1.Column("Ready", ".status.readyReplicas", 0),
+	alpha1.AgeColumn,
+	alpha1.Column("Images", ".spec.template.spec.containers[*].image", 1),
+}
+
+// SortKeys are keys stateful sets are sorted by besides JSONPaths
+var SortKeys = alpha1.SortKeys(query.FieldUpdateTime, query.FieldStatus, fieldReplicas)
+
 type statefulSetProvider struct {
 	sharedInformers informers.SharedInformerFactory
 }
@@ -41,7 +57,7 @@ func (sts statefulSetProvider) List(namespace string, query *query.QueryInfo) (*
 		result = append(result, deploy)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, Compare, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -52,14 +68,33 @@ func filter(object runtime.Object, filter query.Filter) bool {
 
 	switch filter.Field {
 	case query.FieldStatus:
-		return strings.Compare(statefulSetStatus(statefulset), string(filter.Value)) == 0
+		return alpha1.MatchFieldValue(FieldValue, object, filter)
 
 	default:
 		return alpha1.DefaultObjectMetaFilter(statefulset.ObjectMeta, filter)
 	}
 }
 
-func compareFunc(left, right runtime.Object, field query.Field) bool {
+// FieldValue returns value of field of stateful set to group by
+func FieldValue(object runtime.Object, field query.Field) (string, bool) {
+	statefulset, ok := object.(*v1.StatefulSet)
+	if !ok || field != query.FieldStatus {
+		return "", false
+	}
+	return statefulSetStatus(statefulset), true
+}
+
+// Requests returns resource requests of desired replicas of stateful set
+func Requests(object runtime.Object) (corev1.ResourceList, bool) {
+	statefulset, ok := object.(*v1.StatefulSet)
+	if !ok {
+		return nil, false
+	}
+	return alpha1.ReplicasRequests(&statefulset.Spec.Template.Spec, desiredReplicas(statefulset)), true
+}
+
+// Compare returns true if stateful set left is less than right by field
+func Compare(left, right runtime.Object, field query.Field) bool {
 
 	leftDeploy, ok := left.(*v1.StatefulSet)
 	if !ok {
@@ -74,11 +109,23 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 		fallthrough
 	case query.FieldLastUpdateTimestamp:
 		return lastUpdateTime(leftDeploy).After(lastUpdateTime(rightDeploy))
+	case query.FieldStatus:
+		return strings.Compare(statefulSetStatus(leftDeploy), statefulSetStatus(rightDeploy)) < 0
+	case fieldReplicas:
+		return desiredReplicas(leftDeploy) < desiredReplicas(rightDeploy)
 	default:
 		return alpha1.DefaultObjectMetaCompare(leftDeploy.ObjectMeta, rightDeploy.ObjectMeta, field)
 	}
 }
 
+// desiredReplicas returns replicas of stateful set, which defaults to 1
+func desiredReplicas(statefulSet *v1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		return 1
+	}
+	return *statefulSet.Spec.Replicas
+}
+
 func lastUpdateTime(statefulSet *v1.StatefulSet) time.Time {
 	recent := statefulSet.CreationTimestamp.Time
 
@@ -102,3 +149,11 @@ func statefulSetStatus(item *v1.StatefulSet) string {
 	}
 	return StatusStopped
 }
+
+func (sts statefulSetProvider) Informer() cache.SharedIndexInformer {
+	return sts.sharedInformers.Apps().V1().StatefulSets().Informer()
+}
+
+func (sts statefulSetProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
@@ -7,8 +7,19 @@ import (
 	v1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
 )
 
+// TableColumns are default columns of storage classes in table output
+var TableColumns = []alpha1.TableColumn{
+	alpha1.NameColumn,
+	alpha1.Column("Provisioner", ".provisioner", 0),
+	alpha1.Column("ReclaimPolicy", ".reclaimPolicy", 0),
+	alpha1.Column("VolumeBindingMode", ".volumeBindingMode", 0),
+	alpha1.Column("AllowVolumeExpansion", ".allowVolumeExpansion", 0),
+	alpha1.AgeColumn,
+}
+
 type storageclassProvider struct {
 	informers informers.SharedInformerFactory
 }
@@ -33,7 +44,7 @@ func (sc storageclassProvider) List(namespace string, query *query.QueryInfo) (*
 		result = append(result, nasp)
 	}
 
-	return alpha1.DefaultList(result, query, compareFunc, filter), nil
+	return alpha1.DefaultList(result, query, compareFunc, filter)
 }
 
 func filter(object runtime.Object, filter query.Filter) bool {
@@ -57,3 +68,11 @@ func compareFunc(left, right runtime.Object, field query.Field) bool {
 	}
 	return alpha1.DefaultObjectMetaCompare(leftNS.ObjectMeta, rightNS.ObjectMeta, field)
 }
+
+func (sc storageclassProvider) Informer() cache.SharedIndexInformer {
+	return sc.informers.Storage().V1().StorageClasses().Informer()
+}
+
+func (sc storageclassProvider) Match(object runtime.Object, query *query.QueryInfo) bool {
+	return alpha1.DefaultMatch(object, query, filter)
+}
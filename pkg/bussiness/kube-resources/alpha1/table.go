@@ -0,0 +1,371 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/jsonpath"
+
+	"captain/pkg/unify/response"
+)
+
+// TableColumn is a column of table output, cells of the column are values of JSONPath in listed
+// objects, the same as additionalPrinterColumns of CRDs
+type TableColumn struct {
+	metav1.TableColumnDefinition
+
+	// JSONPath is a simple JSONPath evaluated in each object, e.g. .status.phase
+	JSONPath string
+	// Value computes cells of the column instead of JSONPath if set, for values derived from
+	// multiple fields such as status of pods shown by kubectl
+	Value ValueFunc
+}
+
+// ValueFunc returns value of a computed column of object, false if object has no such value
+type ValueFunc func(runtime.Object) (interface{}, bool)
+
+var (
+	NameColumn = TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		JSONPath:              ".metadata.name",
+	}
+	AgeColumn = TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: "Age", Type: "date", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		JSONPath:              ".metadata.creationTimestamp",
+	}
+
+	// DefaultTableColumns are columns of resources without their own columns
+	DefaultTableColumns = []TableColumn{NameColumn, AgeColumn}
+)
+
+// Column returns a column of string values at JSONPath, columns of priority greater than 0 are
+// shown only in wide output by kubectl
+func Column(name, path string, priority int32) TableColumn {
+	return TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: name, Type: "string", Priority: priority},
+		JSONPath:              path,
+	}
+}
+
+// DateColumn returns a column of timestamps at JSONPath, shown as age of the timestamp
+func DateColumn(name, path string, priority int32) TableColumn {
+	return TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: name, Type: "date", Priority: priority},
+		JSONPath:              path,
+	}
+}
+
+// ComputedColumn returns a column of values computed by value, columnType is a type of OpenAPI such
+// as string and integer
+func ComputedColumn(name, columnType string, priority int32, value ValueFunc) TableColumn {
+	return TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: name, Type: columnType, Priority: priority},
+		Value:                 value,
+	}
+}
+
+// FieldColumns returns columns of fields requested by fields query parameter, columns are named
+// after fields
+func FieldColumns(fields []string) []TableColumn {
+	columns := make([]TableColumn, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, Column(field, field, 0))
+	}
+	return columns
+}
+
+// ParseFields splits comma separated fields, commas in brackets or quotes such as
+// {.status.conditions[?(@.type=="Ready")].status} are not separators
+func ParseFields(s string) []string {
+	var fields []string
+	var depth int
+	var quote rune
+	begin := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '{' || r == '[' || r == '(':
+			depth++
+		case r == '}' || r == ']' || r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			fields = append(fields, s[begin:i])
+			begin = i + 1
+		}
+	}
+	fields = append(fields, s[begin:])
+
+	var result []string
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); len(field) != 0 {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// parseJSONPath parses path in the form of metadata.name, .metadata.name or {.metadata.name},
+// missing keys are evaluated to no results instead of errors
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	template := strings.TrimSpace(path)
+	if !strings.HasPrefix(template, "{") {
+		if !strings.HasPrefix(template, ".") {
+			template = "." + template
+		}
+		template = "{" + template + "}"
+	}
+	parser := jsonpath.New(path).AllowMissingKeys(true)
+	if err := parser.Parse(template); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %s: %v", path, err)
+	}
+	return parser, nil
+}
+
+func parseJSONPaths(paths []string) ([]*jsonpath.JSONPath, error) {
+	parsers := make([]*jsonpath.JSONPath, 0, len(paths))
+	for _, path := range paths {
+		parser, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		parsers = append(parsers, parser)
+	}
+	return parsers, nil
+}
+
+// evaluate returns all values found by parser in content
+func evaluate(parser *jsonpath.JSONPath, content map[string]interface{}) []interface{} {
+	results, err := parser.FindResults(content)
+	if err != nil {
+		return nil
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+	return values
+}
+
+// toUnstructured converts item of list result to unstructured content JSONPath is evaluated in
+func toUnstructured(item interface{}) (map[string]interface{}, error) {
+	switch obj := item.(type) {
+	case runtime.Unstructured:
+		return obj.UnstructuredContent(), nil
+	case runtime.Object:
+		return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	default:
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		content := map[string]interface{}{}
+		return content, json.Unmarshal(data, &content)
+	}
+}
+
+// ProjectFields replaces items of result with objects of fields, keyed by fields as they are
+// requested, a field is null if not found and an array if found multiple times
+func ProjectFields(result *response.ListResult, fields []string) error {
+	parsers, err := parseJSONPaths(fields)
+	if err != nil {
+		return err
+	}
+
+	items := make([]interface{}, 0, len(result.Items))
+	for _, item := range result.Items {
+		content, err := toUnstructured(item)
+		if err != nil {
+			return err
+		}
+		projected := make(map[string]interface{}, len(fields))
+		for i, field := range fields {
+			switch values := evaluate(parsers[i], content); len(values) {
+			case 0:
+				projected[field] = nil
+			case 1:
+				projected[field] = values[0]
+			default:
+				projected[field] = values
+			}
+		}
+		items = append(items, projected)
+	}
+	result.Items = items
+	return nil
+}
+
+// ColumnPrinter evaluates columns in items of list results, JSONPaths of columns are parsed once
+type ColumnPrinter struct {
+	columns []TableColumn
+	parsers []*jsonpath.JSONPath
+}
+
+// NewColumnPrinter returns printer of columns, an error is returned if any JSONPath is malformed
+func NewColumnPrinter(columns []TableColumn) (*ColumnPrinter, error) {
+	printer := &ColumnPrinter{columns: columns}
+	for _, column := range columns {
+		if column.Value != nil {
+			printer.parsers = append(printer.parsers, nil)
+			continue
+		}
+		parser, err := parseJSONPath(column.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		printer.parsers = append(printer.parsers, parser)
+	}
+	return printer, nil
+}
+
+// Names returns names of columns
+func (p *ColumnPrinter) Names() []string {
+	names := make([]string, 0, len(p.columns))
+	for _, column := range p.columns {
+		names = append(names, column.Name)
+	}
+	return names
+}
+
+// Cells returns cells of item in table output, formatted like kube-apiserver does for
+// additionalPrinterColumns, dates are shown as age and multiple values of string columns are
+// joined by commas
+func (p *ColumnPrinter) Cells(item interface{}) ([]interface{}, error) {
+	content, err := toUnstructured(item)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]interface{}, 0, len(p.columns))
+	for i, column := range p.columns {
+		cells = append(cells, cell(column, p.values(i, item, content)))
+	}
+	return cells, nil
+}
+
+// Values returns cells of item as plain text, e.g. for CSV, dates are kept as timestamps and
+// multiple values are joined by commas
+func (p *ColumnPrinter) Values(item interface{}) ([]string, error) {
+	content, err := toUnstructured(item)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(p.columns))
+	for i := range p.columns {
+		s := make([]string, 0, 1)
+		for _, value := range p.values(i, item, content) {
+			s = append(s, fmt.Sprint(value))
+		}
+		values = append(values, strings.Join(s, ","))
+	}
+	return values, nil
+}
+
+// values returns values of the ith column in item, content is unstructured content of item
+func (p *ColumnPrinter) values(i int, item interface{}, content map[string]interface{}) []interface{} {
+	if p.columns[i].Value == nil {
+		return evaluate(p.parsers[i], content)
+	}
+	if object, ok := item.(runtime.Object); ok {
+		if value, ok := p.columns[i].Value(object); ok {
+			return []interface{}{value}
+		}
+	}
+	return nil
+}
+
+// RenderTable renders items of result as rows of columns, object of each row is metadata of the
+// item, the same as kube-apiserver does with includeObject=Metadata
+func RenderTable(result *response.ListResult, columns []TableColumn) (*response.TableResult, error) {
+	printer, err := NewColumnPrinter(columns)
+	if err != nil {
+		return nil, err
+	}
+	definitions := make([]metav1.TableColumnDefinition, 0, len(columns))
+	for _, column := range columns {
+		definitions = append(definitions, column.TableColumnDefinition)
+	}
+
+	table := &response.TableResult{
+		TypeMeta:          metav1.TypeMeta{Kind: "Table", APIVersion: metav1.SchemeGroupVersion.String()},
+		ColumnDefinitions: definitions,
+		Rows:              make([]metav1.TableRow, 0, len(result.Items)),
+		Total:             result.Total,
+		PageSize:          result.PageSize,
+		TotalPages:        result.TotalPages,
+		CurrentPage:       result.CurrentPage,
+		Continue:          result.Continue,
+		ResourceVersion:   result.ResourceVersion,
+	}
+	for _, item := range result.Items {
+		row := metav1.TableRow{}
+		if row.Cells, err = printer.Cells(item); err != nil {
+			return nil, err
+		}
+		if row.Object.Raw, err = partialObjectMetadata(item); err != nil {
+			return nil, err
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// cell formats values of column in table output
+func cell(column TableColumn, values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	switch column.Type {
+	case "date":
+		if s, ok := values[0].(string); ok {
+			if timestamp, err := time.Parse(time.RFC3339, s); err == nil {
+				return duration.HumanDuration(time.Since(timestamp))
+			}
+		}
+		return "<unknown>"
+	case "string":
+		s := make([]string, 0, len(values))
+		for _, value := range values {
+			s = append(s, fmt.Sprint(value))
+		}
+		return strings.Join(s, ",")
+	default:
+		if len(values) == 1 {
+			return values[0]
+		}
+		return values
+	}
+}
+
+func partialObjectMetadata(item interface{}) ([]byte, error) {
+	accessor, err := meta.Accessor(item)
+	if err != nil {
+		return nil, nil
+	}
+	object := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{Kind: "PartialObjectMetadata", APIVersion: metav1.SchemeGroupVersion.String()},
+	}
+	object.Name = accessor.GetName()
+	object.Namespace = accessor.GetNamespace()
+	object.UID = accessor.GetUID()
+	object.ResourceVersion = accessor.GetResourceVersion()
+	object.CreationTimestamp = accessor.GetCreationTimestamp()
+	object.DeletionTimestamp = accessor.GetDeletionTimestamp()
+	object.Labels = accessor.GetLabels()
+	object.Annotations = accessor.GetAnnotations()
+	object.OwnerReferences = accessor.GetOwnerReferences()
+	return json.Marshal(object)
+}
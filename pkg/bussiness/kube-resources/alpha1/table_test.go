@@ -0,0 +1,74 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"captain/pkg/unify/response"
+)
+
+func TestTable(t *testing.T) {
+	newResult := func() *response.ListResult {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "nginx",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+				ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "nginx", RestartCount: 1},
+					{Name: "sidecar", RestartCount: 2},
+				},
+			},
+		}
+		return &response.ListResult{Items: []interface{}{pod}, Total: 1, TotalPages: 1, CurrentPage: 1, PageSize: 10}
+	}
+
+	fields := ParseFields(`metadata.name, {.status.conditions[?(@.type=="Ready")].status},.status.containerStatuses[*].restartCount,spec.missing`)
+	if len(fields) != 4 {
+		t.Fatalf("unexpected fields %q", fields)
+	}
+
+	result := newResult()
+	if err := ProjectFields(result, fields); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result.Items[0])
+	expected := `{".status.containerStatuses[*].restartCount":[1,2],"metadata.name":"nginx","spec.missing":null,"{.status.conditions[?(@.type==\"Ready\")].status}":"True"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	columns := []TableColumn{NameColumn, Column("Restarts", ".status.containerStatuses[*].restartCount", 0), Column("Node", "spec.nodeName", 1), AgeColumn}
+	table, err := RenderTable(newResult(), columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 4 || len(table.Rows) != 1 || table.Total != 1 {
+		t.Fatalf("unexpected table %+v", table)
+	}
+	if cells := fmt.Sprint(table.Rows[0].Cells); cells != "[nginx 1,2 node-1 120m]" {
+		t.Errorf("unexpected cells %s", cells)
+	}
+	object := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(table.Rows[0].Object.Raw, object); err != nil {
+		t.Fatal(err)
+	}
+	if object.Kind != "PartialObjectMetadata" || object.Name != "nginx" || len(object.ManagedFields) != 0 {
+		t.Errorf("unexpected object of row %+v", object)
+	}
+
+	if _, err := RenderTable(newResult(), []TableColumn{Column("Bad", "{.status", 0)}); err == nil {
+		t.Errorf("expected error of malformed JSONPath")
+	}
+}
@@ -0,0 +1,79 @@
+package terminal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	DefaultImage          = "alpine:3.15"
+	DefaultNamespace      = "kube-system"
+	DefaultSessionTimeout = 2 * time.Hour
+	DefaultIdleTimeout    = 15 * time.Minute
+	DefaultRecordDir      = "/var/log/captain/terminal"
+)
+
+type Options struct {
+	// Image of node shell pods, nsenter is required in the image
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	// Namespace node shell pods run in
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// SessionTimeout is the max duration of a session, sessions never time out if it's 0
+	SessionTimeout time.Duration `json:"sessionTimeout,omitempty" yaml:"sessionTimeout,omitempty"`
+
+	// IdleTimeout kills sessions receiving no input for the duration, sessions are never killed if it's 0
+	IdleTimeout time.Duration `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+
+	// RecordDir is the directory sessions are recorded to in asciinema v2 format, sessions are not
+	// recorded if it's set to empty
+	RecordDir string `json:"recordDir,omitempty" yaml:"recordDir,omitempty"`
+
+	// AllowedOrigins are origins of browsers allowed to connect besides the same origin, * allows any origin
+	AllowedOrigins []string `json:"allowedOrigins,omitempty" yaml:"allowedOrigins,omitempty"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Image:          DefaultImage,
+		Namespace:      DefaultNamespace,
+		SessionTimeout: DefaultSessionTimeout,
+		IdleTimeout:    DefaultIdleTimeout,
+		RecordDir:      DefaultRecordDir,
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if o.SessionTimeout < 0 || o.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("terminal session timeout and idle timeout must not be negative"))
+	}
+
+	if len(o.Image) == 0 || len(o.Namespace) == 0 {
+		errs = append(errs, fmt.Errorf("image and namespace of node shell pods must be set"))
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.StringVar(&o.Image, "terminal-image", s.Image, "Image of node shell pods, nsenter is required in the image.")
+
+	fs.StringVar(&o.Namespace, "terminal-namespace", s.Namespace, "Namespace node shell pods run in.")
+
+	fs.DurationVar(&o.SessionTimeout, "terminal-session-timeout", s.SessionTimeout, "Max duration of a "+
+		"terminal session, sessions never time out if it's 0.")
+
+	fs.DurationVar(&o.IdleTimeout, "terminal-idle-timeout", s.IdleTimeout, "Kill terminal sessions receiving "+
+		"no input for the duration, sessions are never killed if it's 0.")
+
+	fs.StringVar(&o.RecordDir, "terminal-record-dir", s.RecordDir, "Directory terminal sessions are recorded "+
+		"to in asciinema v2 format, sessions are not recorded if it's set to empty.")
+
+	fs.StringSliceVar(&o.AllowedOrigins, "terminal-allowed-origins", s.AllowedOrigins, "Origins of browsers "+
+		"allowed to open terminals besides the same origin, * allows any origin.")
+}
@@ -0,0 +1,116 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// asciinema v2 event types
+const (
+	eventOutput = "o"
+	eventResize = "r"
+)
+
+// header is the first line of an asciinema v2 recording
+type header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder records output and resizes of a session in asciinema v2 format, each event is a line
+// of [elapsed seconds, type, data], see https://docs.asciinema.org/manual/asciicast/v2/
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.WriteCloser
+	start time.Time
+	err   error
+}
+
+// NewRecorder writes header of a recording of size width x height to w
+func NewRecorder(w io.WriteCloser, title string, width, height uint16) (*Recorder, error) {
+	r := &Recorder{w: w, start: time.Now()}
+	line, err := json.Marshal(header{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateRecorder creates a recording file in dir, name of the file is made of the time and name,
+// characters other than letters, digits, '-' and '.' in name are replaced with '_'
+func CreateRecorder(dir, name, title string, width, height uint16) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.cast", time.Now().Format("20060102T150405.000"), name))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRecorder(file, title, width, height)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Output records data written to the terminal
+func (r *Recorder) Output(data []byte) {
+	r.event(eventOutput, string(data))
+}
+
+// Resize records resize of the terminal
+func (r *Recorder) Resize(width, height uint16) {
+	r.event(eventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// event writes an event, recording stops at the first failure so that the session is not affected
+func (r *Recorder) event(typ, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), typ, data})
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	r.err = err
+}
+
+// Close closes the recording, error of the first failed event is returned if any
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
@@ -0,0 +1,286 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog"
+)
+
+// Operations of messages, stdin and resize are sent by browsers, stdout and toast are sent by servers
+const (
+	OpStdin  = "stdin"
+	OpStdout = "stdout"
+	OpResize = "resize"
+	OpToast  = "toast"
+)
+
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+
+	// maxCloseReason is the max length of reason of websocket close frames
+	maxCloseReason = 123
+
+	// bearerProtocolPrefix is the prefix of subprotocols carrying bearer tokens of browsers, which
+	// can't set headers of websockets
+	bearerProtocolPrefix = "base64url.bearer.authorization.k8s.io."
+)
+
+// checkInterval is the interval timeouts of sessions are checked
+var checkInterval = time.Second
+
+// Message is a message exchanged with xterm of browsers, e.g. {"op":"stdin","data":"ls\r"}
+// and {"op":"resize","cols":120,"rows":40}
+type Message struct {
+	Op   string `json:"op"`
+	Data string `json:"data,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+}
+
+// NewUpgrader returns upgrader of terminal websockets, browsers of origins other than the same
+// origin are rejected unless they are allowed by options
+func NewUpgrader(options *Options) *websocket.Upgrader {
+	upgrader := &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+	if len(options.AllowedOrigins) == 0 {
+		return upgrader
+	}
+
+	allowed := make(map[string]bool, len(options.AllowedOrigins))
+	for _, origin := range options.AllowedOrigins {
+		allowed[origin] = true
+	}
+	upgrader.CheckOrigin = func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if len(origin) == 0 || allowed["*"] || allowed[origin] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	return upgrader
+}
+
+// ResponseHeader returns header of upgrade response echoing the first subprotocol requested besides
+// those carrying tokens, browsers fail websockets requesting subprotocols if none is echoed
+func ResponseHeader(r *http.Request) http.Header {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if !strings.HasPrefix(protocol, bearerProtocolPrefix) {
+			return http.Header{"Sec-Websocket-Protocol": {protocol}}
+		}
+	}
+	return nil
+}
+
+// Session is a terminal session over websocket, it's stdin, stdout and size queue of a tty. Session is
+// closed when it lasts longer than session timeout or receives no input longer than idle timeout.
+type Session struct {
+	conn     *websocket.Conn
+	recorder *Recorder
+
+	sizes     chan remotecommand.TerminalSize
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// lastInput is unix nanoseconds of the last stdin message
+	lastInput int64
+
+	// pending is stdin data not read yet
+	pending []byte
+
+	writeMu sync.Mutex
+	// partial is the incomplete utf-8 sequence at the end of the last write
+	partial []byte
+}
+
+// NewSession starts a session of size width x height on conn, recorder is optional
+func NewSession(conn *websocket.Conn, recorder *Recorder, width, height uint16, options *Options) *Session {
+	s := &Session{
+		conn:      conn,
+		recorder:  recorder,
+		sizes:     make(chan remotecommand.TerminalSize, 1),
+		done:      make(chan struct{}),
+		lastInput: time.Now().UnixNano(),
+	}
+	s.sizes <- remotecommand.TerminalSize{Width: width, Height: height}
+	go s.expire(options.SessionTimeout, options.IdleTimeout)
+	return s
+}
+
+// Read reads stdin, resize messages are put into the size queue
+func (s *Session) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+
+		var message Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			klog.V(4).Infof("invalid terminal message %q, %v", data, err)
+			continue
+		}
+		switch message.Op {
+		case OpStdin:
+			atomic.StoreInt64(&s.lastInput, time.Now().UnixNano())
+			s.pending = []byte(message.Data)
+		case OpResize:
+			if message.Cols != 0 && message.Rows != 0 {
+				s.resize(message.Cols, message.Rows)
+			}
+		default:
+			klog.V(4).Infof("unknown terminal message %q", data)
+		}
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// resize replaces the size not taken by the tty yet
+func (s *Session) resize(width, height uint16) {
+	size := remotecommand.TerminalSize{Width: width, Height: height}
+	select {
+	case <-s.sizes:
+	default:
+	}
+	select {
+	case s.sizes <- size:
+	default:
+	}
+	if s.recorder != nil {
+		s.recorder.Resize(width, height)
+	}
+}
+
+// Next returns the next size of the tty, nil if the session is closed
+func (s *Session) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-s.sizes:
+		return &size
+	case <-s.done:
+		return nil
+	}
+}
+
+// Write writes stdout and stderr of the tty, utf-8 sequences split across writes are sent as a whole
+func (s *Session) Write(p []byte) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	data := append(s.partial, p...)
+	n := completeRunes(data)
+	s.partial = append([]byte(nil), data[n:]...)
+	if n == 0 {
+		return len(p), nil
+	}
+
+	if s.recorder != nil {
+		s.recorder.Output(data[:n])
+	}
+	if err := s.writeMessage(Message{Op: OpStdout, Data: string(data[:n])}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Toast shows a notice in the terminal of browsers
+func (s *Session) Toast(format string, args ...interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.writeMessage(Message{Op: OpToast, Data: fmt.Sprintf(format, args...)}); err != nil {
+		klog.V(4).Infof("write terminal toast failed, %v", err)
+	}
+}
+
+func (s *Session) writeMessage(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Done is closed when the session is closed
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the session with websocket close code and reason, the tty gets EOF of stdin
+func (s *Session) Close(code int, reason string) {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		if len(reason) > maxCloseReason {
+			reason = reason[:maxCloseReason]
+		}
+		s.writeMu.Lock()
+		err := s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+		s.writeMu.Unlock()
+		if err != nil {
+			klog.V(4).Infof("write terminal close message failed, %v", err)
+		}
+		s.conn.Close()
+
+		if s.recorder != nil {
+			if err := s.recorder.Close(); err != nil {
+				klog.Errorf("record terminal session failed, %v", err)
+			}
+		}
+	})
+}
+
+// expire closes the session when it times out or is idle too long
+func (s *Session) expire(sessionTimeout, idleTimeout time.Duration) {
+	if sessionTimeout == 0 && idleTimeout == 0 {
+		return
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			if sessionTimeout != 0 && now.Sub(start) >= sessionTimeout {
+				s.Toast("session lasts longer than %v, closing", sessionTimeout)
+				s.Close(websocket.CloseNormalClosure, "session timeout")
+				return
+			}
+			if idleTimeout != 0 && now.Sub(time.Unix(0, atomic.LoadInt64(&s.lastInput))) >= idleTimeout {
+				s.Toast("no input for %v, closing", idleTimeout)
+				s.Close(websocket.CloseNormalClosure, "idle timeout")
+				return
+			}
+		}
+	}
+}
+
+// completeRunes returns length of the longest prefix of p not ending in the middle of a utf-8 sequence
+func completeRunes(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) {
+				return len(p)
+			}
+			return i
+		}
+	}
+	return len(p)
+}
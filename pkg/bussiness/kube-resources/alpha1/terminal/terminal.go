@@ -0,0 +1,238 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog"
+)
+
+const (
+	// NodeShellLabel is the label of node shell pods
+	NodeShellLabel = "captain.io/node-shell"
+	// NodeShellNodeAnnotation is the annotation of node shell pods telling the node
+	NodeShellNodeAnnotation = "captain.io/node-shell-node"
+
+	// defaultContainerAnnotation tells the container kubectl exec runs in by default
+	defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+	nodeShellContainer    = "shell"
+	nodeShellStartTimeout = 2 * time.Minute
+)
+
+// defaultShell runs bash if it's available, or sh otherwise
+var defaultShell = []string{"/bin/sh", "-c", "TERM=xterm-256color; export TERM; " +
+	"if command -v bash >/dev/null 2>&1; then exec bash -l; else exec sh; fi"}
+
+// Terminal is a tty of a container or a node, it's checked before websocket is upgraded and
+// runs once it's attached to a session
+type Terminal struct {
+	config *rest.Config
+	client kubernetes.Interface
+
+	namespace string
+	pod       string
+	container string
+	command   []string
+
+	// node is set for node shells, pod is created in namespace of options when terminal runs
+	node    string
+	options *Options
+}
+
+// NewPodTerminal checks that container of pod is running, the first container or the default container
+// told by annotation is used if container is empty. shell is run if it's set, or bash or sh otherwise.
+func NewPodTerminal(ctx context.Context, config *rest.Config, client kubernetes.Interface, namespace, name, container, shell string) (*Terminal, error) {
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("pod %s is %s, terminals are opened only in running pods", name, pod.Status.Phase))
+	}
+
+	if len(container) == 0 {
+		container = pod.Annotations[defaultContainerAnnotation]
+	}
+	if len(container) == 0 && len(pod.Spec.Containers) != 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+	found := false
+	for _, c := range pod.Spec.Containers {
+		found = found || c.Name == container
+	}
+	if !found {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("container %s is not found in pod %s", container, name))
+	}
+
+	command := defaultShell
+	if len(shell) != 0 {
+		command = []string{shell}
+	}
+	return &Terminal{config: config, client: client, namespace: namespace, pod: name, container: container, command: command}, nil
+}
+
+// NewNodeTerminal checks that node exists, shell of the node runs in a privileged pod entering
+// namespaces of the host
+func NewNodeTerminal(ctx context.Context, config *rest.Config, client kubernetes.Interface, node string, options *Options) (*Terminal, error) {
+	if _, err := client.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+	command := append([]string{"nsenter", "-t", "1", "-m", "-u", "-i", "-n", "-p", "--"}, defaultShell...)
+	return &Terminal{config: config, client: client, namespace: options.Namespace, container: nodeShellContainer,
+		command: command, node: node, options: options}, nil
+}
+
+// Name tells what the terminal is attached to, namespace/pod/container or node/name
+func (t *Terminal) Name() string {
+	if len(t.node) != 0 {
+		return "node/" + t.node
+	}
+	return fmt.Sprintf("%s/%s/%s", t.namespace, t.pod, t.container)
+}
+
+// Run runs the terminal until the shell exits or the session is closed, node shell pods are
+// deleted when it returns
+func (t *Terminal) Run(session *Session) error {
+	if len(t.node) == 0 {
+		return t.exec(session)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-session.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	pod, err := t.client.CoreV1().Pods(t.namespace).Create(ctx, nodeShellPod(t.node, t.options), metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		zero := int64(0)
+		if err := t.client.CoreV1().Pods(t.namespace).Delete(context.Background(), pod.Name,
+			metav1.DeleteOptions{GracePeriodSeconds: &zero}); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf("delete node shell pod %s/%s failed, %v", t.namespace, pod.Name, err)
+		}
+	}()
+
+	session.Toast("starting shell of node %s in pod %s/%s", t.node, t.namespace, pod.Name)
+	if err := waitForRunning(ctx, t.client, t.namespace, pod.Name); err != nil {
+		return err
+	}
+	t.pod = pod.Name
+	return t.exec(session)
+}
+
+// exec runs command in container with tty attached to session
+func (t *Terminal) exec(session *Session) error {
+	req := t.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(t.namespace).
+		Name(t.pod).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: t.container,
+			Command:   t.command,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+			TTY:       true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(t.config, http.MethodPost, req.URL())
+	if err != nil {
+		return err
+	}
+	return executor.Stream(remotecommand.StreamOptions{
+		Stdin:             session,
+		Stdout:            session,
+		Stderr:            session,
+		Tty:               true,
+		TerminalSizeQueue: session,
+	})
+}
+
+// nodeShellPod returns a privileged pod sharing namespaces of the host, it's killed by active deadline
+// in case it's not deleted after the session
+func nodeShellPod(node string, options *Options) *v1.Pod {
+	privileged := true
+	zero := int64(0)
+	automount := false
+	lifetime := int64(math.MaxInt32)
+	var deadline *int64
+	if options.SessionTimeout != 0 {
+		lifetime = int64((options.SessionTimeout + time.Minute).Seconds())
+		deadline = &lifetime
+	}
+
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "node-shell-",
+			Namespace:    options.Namespace,
+			Labels:       map[string]string{NodeShellLabel: "true"},
+			Annotations:  map[string]string{NodeShellNodeAnnotation: node},
+		},
+		Spec: v1.PodSpec{
+			NodeName:                      node,
+			HostPID:                       true,
+			HostIPC:                       true,
+			HostNetwork:                   true,
+			RestartPolicy:                 v1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &zero,
+			ActiveDeadlineSeconds:         deadline,
+			AutomountServiceAccountToken:  &automount,
+			Tolerations:                   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Containers: []v1.Container{{
+				Name:            nodeShellContainer,
+				Image:           options.Image,
+				Command:         []string{"sleep", strconv.FormatInt(lifetime, 10)},
+				Stdin:           true,
+				TTY:             true,
+				SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+	}
+}
+
+// waitForRunning waits until pod is running, it fails if pod terminates or doesn't start in time
+func waitForRunning(ctx context.Context, client kubernetes.Interface, namespace, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, nodeShellStartTimeout)
+	defer cancel()
+
+	var phase v1.PodPhase
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		phase = pod.Status.Phase
+		switch phase {
+		case v1.PodRunning:
+			return true, nil
+		case v1.PodSucceeded, v1.PodFailed:
+			return false, fmt.Errorf("node shell pod %s/%s is %s", namespace, name, phase)
+		}
+		return false, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("node shell pod %s/%s is still %s after %v", namespace, name, phase, nodeShellStartTimeout)
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+type recording struct {
+	bytes.Buffer
+	closed chan struct{}
+}
+
+func (r *recording) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestSession(t *testing.T) {
+	checkInterval = 10 * time.Millisecond
+	options := &Options{IdleTimeout: 300 * time.Millisecond}
+	record := &recording{closed: make(chan struct{})}
+	sizes := make(chan remotecommand.TerminalSize, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := NewUpgrader(options).Upgrade(w, r, ResponseHeader(r))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		recorder, err := NewRecorder(record, "test", 80, 24)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		session := NewSession(conn, recorder, 80, 24, options)
+		sizes <- *session.Next()
+
+		// utf-8 sequence split across writes is sent as a whole
+		session.Write([]byte("caf\xc3"))
+		session.Write([]byte("\xa9\r\n"))
+		go io.Copy(session, session)
+		sizes <- *session.Next()
+	}))
+	defer server.Close()
+
+	// browsers send tokens as subprotocols, the other subprotocol is echoed
+	dialer := websocket.Dialer{Subprotocols: []string{bearerProtocolPrefix + "dG9rZW4", "terminal"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != "terminal" {
+		t.Errorf("expected subprotocol terminal, got %q", conn.Subprotocol())
+	}
+
+	conn.WriteJSON(Message{Op: OpResize, Cols: 120, Rows: 40})
+	conn.WriteJSON(Message{Op: OpStdin, Data: "ls\r"})
+
+	var messages []Message
+	for {
+		var message Message
+		if err := conn.ReadJSON(&message); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Errorf("expected normal closure, got %v", err)
+			}
+			break
+		}
+		messages = append(messages, message)
+	}
+	expected := []Message{
+		{Op: OpStdout, Data: "caf"},
+		{Op: OpStdout, Data: "é\r\n"},
+		{Op: OpStdout, Data: "ls\r"},
+		{Op: OpToast, Data: "no input for 300ms, closing"},
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("expected messages %v, got %v", expected, messages)
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Errorf("expected message %v, got %v", expected[i], messages[i])
+		}
+	}
+
+	if size := <-sizes; size.Width != 80 || size.Height != 24 {
+		t.Errorf("expected initial size 80x24, got %v", size)
+	}
+	if size := <-sizes; size.Width != 120 || size.Height != 40 {
+		t.Errorf("expected size 120x40 after resize, got %v", size)
+	}
+
+	select {
+	case <-record.closed:
+	case <-time.After(time.Second):
+		t.Fatal("recording is not closed")
+	}
+	lines := strings.Split(strings.TrimSpace(record.String()), "\n")
+	var h header
+	if err := json.Unmarshal([]byte(lines[0]), &h); err != nil || h.Version != 2 || h.Width != 80 || h.Height != 24 {
+		t.Errorf("unexpected header %s, %v", lines[0], err)
+	}
+	var events []string
+	for _, line := range lines[1:] {
+		var event []interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil || len(event) != 3 {
+			t.Errorf("unexpected event %s, %v", line, err)
+			continue
+		}
+		events = append(events, event[1].(string)+" "+event[2].(string))
+	}
+	if got, want := strings.Join(events, "|"), "o caf|o é\r\n|r 120x40|o ls\r"; got != want {
+		t.Errorf("expected events %q, got %q", want, got)
+	}
+}
+
+func TestNewTerminal(t *testing.T) {
+	running := v1.PodStatus{Phase: v1.PodRunning}
+	client := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default",
+				Annotations: map[string]string{defaultContainerAnnotation: "app"}},
+			Spec:   v1.PodSpec{Containers: []v1.Container{{Name: "sidecar"}, {Name: "app"}}},
+			Status: running,
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "mysql"}}},
+			Status:     v1.PodStatus{Phase: v1.PodPending},
+		},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+
+	terminal, err := NewPodTerminal(context.Background(), nil, client, "default", "web", "", "")
+	if err != nil || terminal.Name() != "default/web/app" {
+		t.Errorf("expected terminal of default container, got %v, %v", terminal, err)
+	}
+	if _, err := NewPodTerminal(context.Background(), nil, client, "default", "web", "proxy", ""); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of unknown container, got %v", err)
+	}
+	if _, err := NewPodTerminal(context.Background(), nil, client, "default", "db", "", ""); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of pending pod, got %v", err)
+	}
+	if _, err := NewPodTerminal(context.Background(), nil, client, "default", "api", "", ""); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	options := NewOptions()
+	terminal, err = NewNodeTerminal(context.Background(), nil, client, "node-1", options)
+	if err != nil || terminal.Name() != "node/node-1" {
+		t.Errorf("expected terminal of node, got %v, %v", terminal, err)
+	}
+	if _, err := NewNodeTerminal(context.Background(), nil, client, "node-2", options); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	pod := nodeShellPod("node-1", options)
+	container := pod.Spec.Containers[0]
+	if pod.Spec.NodeName != "node-1" || !pod.Spec.HostPID || container.SecurityContext == nil || !*container.SecurityContext.Privileged ||
+		pod.Spec.ActiveDeadlineSeconds == nil || *pod.Spec.ActiveDeadlineSeconds != int64((options.SessionTimeout+time.Minute).Seconds()) {
+		t.Errorf("unexpected node shell pod %+v", pod.Spec)
+	}
+}
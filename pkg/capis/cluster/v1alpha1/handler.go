@@ -2,14 +2,15 @@ package v1alpha1
 
 import (
 	"captain/apis/cluster/v1alpha1"
+	"captain/pkg/api"
 	"captain/pkg/utils/clusterclient"
 	"context"
-	"errors"
 	"strings"
 
 	"github.com/emicklei/go-restful"
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -36,10 +37,7 @@ func (h *Handler) ClusterAdminToken(request *restful.Request, response *restful.
 	clustername := request.PathParameter("name")
 	cluster, err := h.GetByClusterName(clustername)
 	if err != nil {
-		response.WriteAsJson(map[string]interface{}{
-			"code":  404,
-			"error": err.Error(),
-		})
+		api.HandleError(response, request, err)
 		return
 	}
 	region := cluster.Annotations[v1alpha1.ClusterRegion]
@@ -49,10 +47,7 @@ func (h *Handler) ClusterAdminToken(request *restful.Request, response *restful.
 	dryRun := request.QueryParameter("dryRun")
 	token, err := h.getToken(region, clustername, dryRun)
 	if err != nil {
-		response.WriteAsJson(map[string]interface{}{
-			"code":  400,
-			"error": err.Error(),
-		})
+		api.HandleError(response, request, err)
 		return
 	}
 	response.WriteAsJson(map[string]string{
@@ -64,7 +59,7 @@ func (h *Handler) getToken(region, cluster, dryRun string) (string, error) {
 	// 获取k8s client
 	cli, err := h.ClusterClients.GetClientSet(region, cluster)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 	// 尝试获取 token secret
 	secret, err := getTokenSecret(cli)
@@ -83,7 +78,7 @@ func (h *Handler) getToken(region, cluster, dryRun string) (string, error) {
 		}
 	}
 	if secret == nil {
-		return "", errors.New("secret is nil")
+		return "", apierrors.NewNotFound(v1.Resource("secrets"), serviceAccountName+" token")
 	}
 	return string(secret.Data["token"]), nil
 }
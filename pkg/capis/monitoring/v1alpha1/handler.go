@@ -25,7 +25,7 @@ func (h handler) handleClusterMetricsQuery(req *restful.Request, resp *restful.R
 	params := parseRequestParams(req)
 	opt, err := h.makeQueryOptions(params, monitoring.LevelCluster)
 	if err != nil {
-		api.HandleBadRequest(resp, nil, err)
+		api.HandleBadRequest(resp, req, err)
 		return
 	}
 	h.handleNamedMetricsQuery(resp, opt)
@@ -35,7 +35,7 @@ func (h handler) handleNodeMetricsQuery(req *restful.Request, resp *restful.Resp
 	params := parseRequestParams(req)
 	opt, err := h.makeQueryOptions(params, monitoring.LevelNode)
 	if err != nil {
-		api.HandleBadRequest(resp, nil, err)
+		api.HandleBadRequest(resp, req, err)
 		return
 	}
 	h.handleNamedMetricsQuery(resp, opt)
@@ -51,7 +51,7 @@ func (h handler) handleWorkloadMetricsQuery(req *restful.Request, resp *restful.
 			return
 		}
 
-		api.HandleBadRequest(resp, nil, err)
+		api.HandleBadRequest(resp, req, err)
 		return
 	}
 	h.handleNamedMetricsQuery(resp, opt)
@@ -67,7 +67,7 @@ func (h handler) handlePodMetricsQuery(req *restful.Request, resp *restful.Respo
 			return
 		}
 
-		api.HandleBadRequest(resp, nil, err)
+		api.HandleBadRequest(resp, req, err)
 		return
 	}
 	h.handleNamedMetricsQuery(resp, opt)
@@ -83,7 +83,7 @@ func (h handler) handleContainerMetricsQuery(req *restful.Request, resp *restful
 			return
 		}
 
-		api.HandleBadRequest(resp, nil, err)
+		api.HandleBadRequest(resp, req, err)
 		return
 	}
 	h.handleNamedMetricsQuery(resp, opt)
@@ -443,6 +443,13 @@ func (c *clusterController) syncCluster(key string) error {
 		return nil
 	}
 
+	// kubeconfig of proxy connection cluster works only when agent is connected to cluster proxy
+	if cluster.Spec.Connection.Type == clusterv1alpha1.ConnectionTypeProxy &&
+		!isConditionTrue(cluster, clusterv1alpha1.ClusterAgentAvailable) {
+		klog.V(5).Infof("Skipping to join cluster %s cause the agent is not available", cluster.Name)
+		return nil
+	}
+
 	// build up cached cluster data if there isn't any
 	c.mu.Lock()
 	clusterDt, ok := c.clusterMap[cluster.Name]
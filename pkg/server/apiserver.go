@@ -2,12 +2,19 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"runtime/debug"
 
+	"captain/pkg/api"
 	monitoringv1alpha1 "captain/pkg/capis/monitoring/v1alpha1"
 	"captain/pkg/capis/openapi"
 	"captain/pkg/capis/version"
 	"captain/pkg/informers"
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/authentication"
+	"captain/pkg/server/authorization"
+	"captain/pkg/server/authorization/rbac"
 	captainserverconfig "captain/pkg/server/config"
 	"captain/pkg/server/dispatch"
 	"captain/pkg/server/filters"
@@ -16,12 +23,12 @@ import (
 	resV1alpha1 "captain/pkg/server/resources/v1alpha1"
 	"captain/pkg/simple/client/k8s"
 	"captain/pkg/simple/client/monitoring"
+	"captain/pkg/tunnel"
 
 	"github.com/emicklei/go-restful"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	urlruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
@@ -53,17 +60,21 @@ type CaptainAPIServer struct {
 type errorResponder struct{}
 
 func (e *errorResponder) Error(w http.ResponseWriter, req *http.Request, err error) {
-	klog.Error(err)
-	responsewriters.InternalError(w, req, err)
+	request.WriteStatus(w, req, http.StatusServiceUnavailable, err)
 }
 
 func (s *CaptainAPIServer) PrepareRun(stopCh <-chan struct{}) error {
 	s.container = restful.NewContainer()
 	//s.container.Filter(logRequestAndResponse)
 	s.container.Router(restful.CurlyRouter{})
-	//s.container.RecoverHandler(func(panicReason interface{}, httpWriter http.ResponseWriter) {
-	//	logStackOnRecover(panicReason, httpWriter)
-	//})
+	s.container.RecoverHandler(func(panicReason interface{}, httpWriter http.ResponseWriter) {
+		klog.Errorf("recover from panic, %v\n%s", panicReason, debug.Stack())
+		api.WriteStatus(httpWriter, api.NewStatus(http.StatusInternalServerError, fmt.Errorf("%v", panicReason)))
+	})
+	// errors of routing such as 404, 405 and 406 are responded as Status too
+	s.container.ServiceErrorHandler(func(err restful.ServiceError, req *restful.Request, resp *restful.Response) {
+		request.WriteStatus(resp, req.Request, err.Code, err)
+	})
 
 	// install apis
 	s.installCaptainAPIs()
@@ -75,9 +86,7 @@ func (s *CaptainAPIServer) PrepareRun(stopCh <-chan struct{}) error {
 	s.Server.Handler = s.container
 
 	// handle chain
-	s.buildHandlerChain(stopCh)
-
-	return nil
+	return s.buildHandlerChain(stopCh)
 }
 
 // Install all captain api groups
@@ -90,7 +99,7 @@ func (s *CaptainAPIServer) installCaptainAPIs() {
 	urlruntime.Must(monitoringv1alpha1.AddToContainer(s.container, s.MonitoringClient))
 
 	// captain apis for kube resources
-	urlruntime.Must(resAlpha1.AddToContainer(s.container, s.InformerFactory, s.KubeRuntimeCache, s.Config))
+	urlruntime.Must(resAlpha1.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient, s.KubeRuntimeCache, s.Config))
 
 	// cluster api
 	// clusterv1alpha1.AddToContainer(s.container, s.InformerFactory, s.Config)
@@ -103,8 +112,8 @@ func (s *CaptainAPIServer) installCaptainAPIs() {
 
 }
 
-// 通过WithRequestInfo解析API请求的信息，WithKubeAPIServer根据API请求信息判断是否代理请求给Kubernetes
-func (s *CaptainAPIServer) buildHandlerChain(stopCh <-chan struct{}) {
+// 通过WithRequestInfo解析API请求的信息，WithAuditing记录审计事件（包括认证失败的请求），WithAuthentication认证请求用户，WithAuthorization鉴权，WithKubeAPIServer根据API请求信息判断是否代理请求给Kubernetes
+func (s *CaptainAPIServer) buildHandlerChain(stopCh <-chan struct{}) error {
 	requestInfoResolver := &request.RequestInfoFactory{
 		APIPrefixes: sets.NewString("api", "apis", "capis"),
 	}
@@ -117,9 +126,50 @@ func (s *CaptainAPIServer) buildHandlerChain(stopCh <-chan struct{}) {
 		handler = filters.WithMultipleClusterDispatcher(handler, clusterDispatcher)
 	}
 
+	if s.Config.AuthorizationOptions != nil && s.Config.AuthorizationOptions.Mode == authorization.ModeRBAC {
+		authorizer, err := s.buildAuthorizer()
+		if err != nil {
+			return err
+		}
+		handler = filters.WithAuthorization(handler, authorizer,
+			s.Config.MultiClusterOptions.HostRegionName, s.Config.MultiClusterOptions.HostClusterName)
+	}
+
+	if s.Config.AuthenticationOptions != nil && s.Config.AuthenticationOptions.Enable {
+		authenticator, err := authentication.NewAuthenticator(stopCh, s.Config.AuthenticationOptions)
+		if err != nil {
+			return err
+		}
+		handler = filters.WithAuthentication(handler, authenticator)
+	}
+
+	if s.Config.AuditingOptions != nil && s.Config.AuditingOptions.Enable {
+		auditor, err := auditing.New(s.Config.AuditingOptions)
+		if err != nil {
+			return err
+		}
+		go auditor.Run(stopCh)
+		handler = filters.WithAuditing(handler, auditor)
+	}
+
 	handler = filters.WithRequestInfo(handler, requestInfoResolver)
 
 	s.Server.Handler = handler
+	return nil
+}
+
+func (s *CaptainAPIServer) buildAuthorizer() (authorization.Authorizer, error) {
+	pathAuthorizer, err := authorization.NewPathAuthorizer(s.Config.AuthorizationOptions.AlwaysAllowPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleAuthorizer, err := rbac.NewFromFile(s.Config.AuthorizationOptions.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorization.NewUnionAuthorizer(pathAuthorizer, ruleAuthorizer), nil
 }
 
 func (s *CaptainAPIServer) waitForResourceSync(ctx context.Context) error {
@@ -224,6 +274,21 @@ func (s *CaptainAPIServer) Run(ctx context.Context) (err error) {
 		_ = s.Server.Shutdown(shutdownCtx)
 	}()
 
+	// serve agents of proxy connection clusters
+	if s.Config.MultiClusterOptions.ProxyEnabled() {
+		clusterProxy := tunnel.NewServer(
+			s.KubernetesClient.Kubernetes(),
+			s.InformerFactory.CaptainSharedInformerFactory().Cluster().V1alpha1().Clusters(),
+			s.KubernetesClient.Crd().V1beta1().Clusters(),
+			s.Config.MultiClusterOptions)
+		s.InformerFactory.CaptainSharedInformerFactory().Start(ctx.Done())
+		go func() {
+			if err := clusterProxy.Start(ctx); err != nil {
+				klog.Errorf("cluster proxy exited, %v", err)
+			}
+		}()
+	}
+
 	// Caching resources
 	// informersFactory := informers.NewInformerFactories(kubeClient)
 
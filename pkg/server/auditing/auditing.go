@@ -0,0 +1,198 @@
+package auditing
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/klog"
+
+	"captain/pkg/server/request"
+)
+
+// mutatingVerbs are verbs whose request bodies are captured
+var mutatingVerbs = sets.NewString("create", "update", "patch", "delete", "deletecollection")
+
+// Auditor builds audit events of requests and sends them to sinks asynchronously
+type Auditor struct {
+	options *Options
+	sinks   []Sink
+	events  chan *Event
+}
+
+func New(options *Options) (*Auditor, error) {
+	a := &Auditor{
+		options: options,
+		events:  make(chan *Event, options.BufferSize),
+	}
+
+	if options.Stdout {
+		a.sinks = append(a.sinks, NewStdoutSink())
+	}
+
+	if options.FileOptions != nil && len(options.FileOptions.Path) != 0 {
+		a.sinks = append(a.sinks, NewFileSink(options.FileOptions))
+	}
+
+	if options.WebhookOptions != nil && len(options.WebhookOptions.URL) != 0 {
+		sink, err := NewWebhookSink(options.WebhookOptions)
+		if err != nil {
+			return nil, err
+		}
+		a.sinks = append(a.sinks, sink)
+	}
+
+	if len(a.sinks) == 0 {
+		klog.Warningf("Auditing is enabled but no sink is configured, audit events are dropped")
+	}
+
+	return a, nil
+}
+
+// NewEvent builds audit event from request, request body is captured if needed
+func (a *Auditor) NewEvent(req *http.Request) *Event {
+	ctx := req.Context()
+	now := metav1.NowMicro()
+
+	e := &Event{
+		Event: auditv1.Event{
+			Level:                    auditv1.LevelMetadata,
+			AuditID:                  uuid.NewUUID(),
+			Stage:                    auditv1.StageResponseComplete,
+			RequestURI:               req.URL.RequestURI(),
+			Verb:                     req.Method,
+			UserAgent:                req.UserAgent(),
+			RequestReceivedTimestamp: now,
+		},
+	}
+
+	if u, ok := request.UserFrom(ctx); ok {
+		e.SetUser(u)
+	}
+
+	info, ok := request.RequestInfoFrom(ctx)
+	if !ok {
+		return e
+	}
+
+	e.Verb = info.Verb
+	e.UserAgent = info.UserAgent
+	e.SourceIPs = []string{info.SourceIP}
+	e.Region = info.Region
+	e.Cluster = info.Cluster
+	if info.IsResourceRequest {
+		e.ObjectRef = &auditv1.ObjectReference{
+			Resource:    info.Resource,
+			Namespace:   info.Namespace,
+			Name:        info.Name,
+			APIGroup:    info.APIGroup,
+			APIVersion:  info.APIVersion,
+			Subresource: info.Subresource,
+		}
+	}
+
+	if a.options.CaptureBody && mutatingVerbs.Has(info.Verb) {
+		a.captureBody(req, e, info.Resource)
+	}
+
+	return e
+}
+
+// captureBody reads request body into event and puts it back to request
+func (a *Auditor) captureBody(req *http.Request, e *Event, resource string) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, a.options.MaxBodyBytes+1))
+	if err != nil {
+		klog.V(4).Infof("Failed to read request body of %s, %v", e.RequestURI, err)
+	}
+	req.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+
+	if err != nil || int64(len(body)) > a.options.MaxBodyBytes {
+		e.Annotations = map[string]string{"captain.io/body": "omitted"}
+		return
+	}
+
+	body, ok := redact(body, resource)
+	if !ok {
+		e.Annotations = map[string]string{"captain.io/body": "redacted"}
+		return
+	}
+
+	e.Level = auditv1.LevelRequest
+	e.RequestObject = &runtime.Unknown{Raw: body, ContentType: runtime.ContentTypeJSON}
+}
+
+// Process completes event with response status and queues it, event is dropped if queue is full
+func (a *Auditor) Process(e *Event, code int) {
+	e.StageTimestamp = metav1.NowMicro()
+	e.LatencyMilliseconds = e.StageTimestamp.Sub(e.RequestReceivedTimestamp.Time).Milliseconds()
+	e.ResponseStatus = &metav1.Status{Code: int32(code)}
+
+	select {
+	case a.events <- e:
+	default:
+		klog.Warningf("Audit event buffer is full, dropping event %s", e.AuditID)
+	}
+}
+
+// Run sends events to sinks in batch until stopCh is closed
+func (a *Auditor) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(a.options.BatchWait)
+	defer ticker.Stop()
+
+	batch := make([]*Event, 0, a.options.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		for _, sink := range a.sinks {
+			if err := sink.Write(batch); err != nil {
+				klog.Errorf("Failed to write %d audit events, %v", len(batch), err)
+			}
+		}
+		batch = make([]*Event, 0, a.options.BatchSize)
+	}
+
+	for {
+		select {
+		case e := <-a.events:
+			batch = append(batch, e)
+			if len(batch) >= a.options.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			// drain events queued already
+			for {
+				select {
+				case e := <-a.events:
+					batch = append(batch, e)
+					continue
+				default:
+				}
+				break
+			}
+			flush()
+			for _, sink := range a.sinks {
+				_ = sink.Close()
+			}
+			return
+		}
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
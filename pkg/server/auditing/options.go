@@ -0,0 +1,175 @@
+package auditing
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	DefaultMaxBodyBytes = 64 * 1024
+	DefaultBufferSize   = 10000
+	DefaultBatchSize    = 100
+	DefaultBatchWait    = 3 * time.Second
+)
+
+type Options struct {
+	// Enable records an audit event for every request
+	Enable bool `json:"enable" yaml:"enable"`
+
+	// CaptureBody records request bodies of mutating requests, data of Secrets are redacted
+	CaptureBody bool `json:"captureBody,omitempty" yaml:"captureBody,omitempty"`
+
+	// MaxBodyBytes is the max size of request body captured, larger bodies are omitted
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty"`
+
+	// BufferSize is the number of events buffered before sent to sinks, events are
+	// dropped if buffer is full
+	BufferSize int `json:"bufferSize,omitempty" yaml:"bufferSize,omitempty"`
+
+	// BatchSize is the max number of events sent to sinks at once
+	BatchSize int `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+
+	// BatchWait is the max time waited before sending a batch which is not full
+	BatchWait time.Duration `json:"batchWait,omitempty" yaml:"batchWait,omitempty"`
+
+	// Stdout writes events to standard output in json lines
+	Stdout bool `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+
+	// FileOptions writes events to a local file rotated by size
+	FileOptions *FileOptions `json:"file,omitempty" yaml:"file,omitempty" mapstructure:"file"`
+
+	// WebhookOptions posts events to a remote endpoint
+	WebhookOptions *WebhookOptions `json:"webhook,omitempty" yaml:"webhook,omitempty" mapstructure:"webhook"`
+}
+
+type FileOptions struct {
+	// Path of the audit log file, file sink is disabled if left empty
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// MaxSize is the max size in megabytes of audit log file before it gets rotated
+	MaxSize int `json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+
+	// MaxBackups is the max number of rotated files to retain
+	MaxBackups int `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"`
+
+	// MaxAge is the max number of days to retain rotated files
+	MaxAge int `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+
+	// Compress rotated files with gzip
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+}
+
+type WebhookOptions struct {
+	// URL events are posted to, webhook sink is disabled if left empty
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Timeout of each post
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// CAFile signs the certificate of webhook server
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+
+	// InsecureSkipTLSVerify skips verifying the certificate of webhook server
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty" yaml:"insecureSkipTLSVerify,omitempty"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		BufferSize:   DefaultBufferSize,
+		BatchSize:    DefaultBatchSize,
+		BatchWait:    DefaultBatchWait,
+		FileOptions: &FileOptions{
+			MaxSize:    100,
+			MaxBackups: 10,
+			MaxAge:     7,
+		},
+		WebhookOptions: &WebhookOptions{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if !o.Enable {
+		return errs
+	}
+
+	if o.BufferSize <= 0 || o.BatchSize <= 0 || o.BatchWait <= 0 {
+		errs = append(errs, fmt.Errorf("audit buffer size, batch size and batch wait must be positive"))
+	}
+
+	if o.WebhookOptions != nil && len(o.WebhookOptions.URL) != 0 {
+		if _, err := url.ParseRequestURI(o.WebhookOptions.URL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid audit webhook url %s, %v", o.WebhookOptions.URL, err))
+		}
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "audit-enable", s.Enable, "Record an audit event for every request.")
+
+	fs.BoolVar(&o.CaptureBody, "audit-capture-body", s.CaptureBody, "Record request bodies of mutating "+
+		"requests, data of Secrets are redacted.")
+
+	fs.Int64Var(&o.MaxBodyBytes, "audit-max-body-bytes", s.MaxBodyBytes, "Max size of request body captured, "+
+		"larger bodies are omitted.")
+
+	fs.IntVar(&o.BufferSize, "audit-buffer-size", s.BufferSize, "Number of events buffered before sent to "+
+		"sinks, events are dropped if buffer is full.")
+
+	fs.IntVar(&o.BatchSize, "audit-batch-size", s.BatchSize, "Max number of events sent to sinks at once.")
+
+	fs.DurationVar(&o.BatchWait, "audit-batch-wait", s.BatchWait, "Max time waited before sending a batch "+
+		"which is not full.")
+
+	fs.BoolVar(&o.Stdout, "audit-stdout", s.Stdout, "Write audit events to standard output.")
+
+	if o.FileOptions == nil {
+		o.FileOptions = &FileOptions{}
+	}
+	if s.FileOptions == nil {
+		s.FileOptions = &FileOptions{}
+	}
+
+	fs.StringVar(&o.FileOptions.Path, "audit-log-path", s.FileOptions.Path, "Path of the audit log file, "+
+		"file sink is disabled if left empty.")
+
+	fs.IntVar(&o.FileOptions.MaxSize, "audit-log-maxsize", s.FileOptions.MaxSize, "Max size in megabytes "+
+		"of audit log file before it gets rotated.")
+
+	fs.IntVar(&o.FileOptions.MaxBackups, "audit-log-maxbackup", s.FileOptions.MaxBackups, "Max number of "+
+		"rotated audit log files to retain.")
+
+	fs.IntVar(&o.FileOptions.MaxAge, "audit-log-maxage", s.FileOptions.MaxAge, "Max number of days to "+
+		"retain rotated audit log files.")
+
+	fs.BoolVar(&o.FileOptions.Compress, "audit-log-compress", s.FileOptions.Compress, "Compress rotated "+
+		"audit log files with gzip.")
+
+	if o.WebhookOptions == nil {
+		o.WebhookOptions = &WebhookOptions{}
+	}
+	if s.WebhookOptions == nil {
+		s.WebhookOptions = &WebhookOptions{}
+	}
+
+	fs.StringVar(&o.WebhookOptions.URL, "audit-webhook-url", s.WebhookOptions.URL, "URL audit events are "+
+		"posted to, webhook sink is disabled if left empty.")
+
+	fs.DurationVar(&o.WebhookOptions.Timeout, "audit-webhook-timeout", s.WebhookOptions.Timeout, "Timeout "+
+		"of each post to audit webhook.")
+
+	fs.StringVar(&o.WebhookOptions.CAFile, "audit-webhook-ca-file", s.WebhookOptions.CAFile, "CA signs the "+
+		"certificate of audit webhook server.")
+
+	fs.BoolVar(&o.WebhookOptions.InsecureSkipTLSVerify, "audit-webhook-insecure-skip-tls-verify",
+		s.WebhookOptions.InsecureSkipTLSVerify, "Skip verifying the certificate of audit webhook server.")
+}
@@ -0,0 +1,100 @@
+package auditing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const redactedValue = "******"
+
+// redact hides data of Secrets in request body. Secrets are told by kind whichever resource is
+// requested, and by resource for patches which carry no kind. Bodies in yaml are converted to
+// json, other bodies are dropped entirely as what's inside is unknown, false is returned in
+// that case.
+func redact(body []byte, resource string) ([]byte, bool) {
+	var docs []interface{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(body), 4096)
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, false
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+
+	isSecret := resource == "secrets"
+	redacted := false
+	for _, doc := range docs {
+		redacted = redactObject(doc, isSecret) || (isSecret && redactPatch(doc)) || redacted
+	}
+	if !redacted && (len(docs) == 0 || json.Valid(body)) {
+		return body, true
+	}
+
+	var obj interface{} = docs
+	if len(docs) == 1 {
+		obj = docs[0]
+	}
+	redactedBody, err := json.Marshal(obj)
+	if err != nil {
+		return nil, false
+	}
+	return redactedBody, true
+}
+
+// redactObject redacts Secrets in obj, lists included. isSecret tells obj is a Secret even
+// if kind is absent, patches for example. Returns true if anything is redacted.
+func redactObject(obj interface{}, isSecret bool) bool {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	redacted := false
+	if kind, _ := m["kind"].(string); kind == "Secret" || (isSecret && len(kind) == 0) {
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := m[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for k := range data {
+				data[k] = redactedValue
+			}
+			redacted = true
+		}
+	}
+
+	if items, ok := m["items"].([]interface{}); ok {
+		for _, item := range items {
+			redacted = redactObject(item, isSecret) || redacted
+		}
+	}
+
+	return redacted
+}
+
+// redactPatch redacts values of json patch operations
+func redactPatch(obj interface{}) bool {
+	operations, ok := obj.([]interface{})
+	if !ok {
+		return false
+	}
+
+	redacted := false
+	for _, operation := range operations {
+		if op, ok := operation.(map[string]interface{}); ok {
+			if _, ok := op["value"]; ok {
+				op["value"] = redactedValue
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
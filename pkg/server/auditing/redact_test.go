@@ -0,0 +1,84 @@
+package auditing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		resource string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "secret",
+			body:     `{"kind":"Secret","data":{"password":"cGFzc3dvcmQ="},"stringData":{"token":"abc"}}`,
+			resource: "secrets",
+			expected: `{"data":{"password":"******"},"kind":"Secret","stringData":{"token":"******"}}`,
+			ok:       true,
+		},
+		{
+			name:     "merge patch of secret",
+			body:     `{"data":{"password":"cGFzc3dvcmQ="}}`,
+			resource: "secrets",
+			expected: `{"data":{"password":"******"}}`,
+			ok:       true,
+		},
+		{
+			name:     "json patch of secret",
+			body:     `[{"op":"replace","path":"/data/password","value":"cGFzc3dvcmQ="}]`,
+			resource: "secrets",
+			expected: `[{"op":"replace","path":"/data/password","value":"******"}]`,
+			ok:       true,
+		},
+		{
+			name:     "secret in list",
+			body:     `{"kind":"List","items":[{"kind":"Secret","data":{"password":"cGFzc3dvcmQ="}}]}`,
+			resource: "",
+			expected: `{"items":[{"data":{"password":"******"},"kind":"Secret"}],"kind":"List"}`,
+			ok:       true,
+		},
+		{
+			name:     "configmap",
+			body:     `{"kind":"ConfigMap","data":{"key":"value"}}`,
+			resource: "configmaps",
+			expected: `{"kind":"ConfigMap","data":{"key":"value"}}`,
+			ok:       true,
+		},
+		{
+			name:     "secret in yaml",
+			body:     "kind: Secret\ndata:\n  password: cGFzc3dvcmQ=\n",
+			resource: "",
+			expected: `{"data":{"password":"******"},"kind":"Secret"}`,
+			ok:       true,
+		},
+		{
+			name:     "secrets in multiple yaml documents",
+			body:     "kind: ConfigMap\ndata:\n  key: value\n---\nkind: Secret\nstringData:\n  token: abc\n",
+			resource: "",
+			expected: `[{"data":{"key":"value"},"kind":"ConfigMap"},{"kind":"Secret","stringData":{"token":"******"}}]`,
+			ok:       true,
+		},
+		{
+			name:     "not json or yaml",
+			body:     "k8s\x00\n\x02v1\x12\x06Secret",
+			resource: "pods",
+			ok:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, ok := redact([]byte(test.body), test.resource)
+			if ok != test.ok {
+				t.Fatalf("expected ok %v, got %v", test.ok, ok)
+			}
+			if strings.TrimSpace(string(body)) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, body)
+			}
+		})
+	}
+}
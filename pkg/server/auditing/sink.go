@@ -0,0 +1,124 @@
+package auditing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Sink is where audit events go
+type Sink interface {
+	Write(events []*Event) error
+	Close() error
+}
+
+// writerSink writes events to writer in json lines
+type writerSink struct {
+	writer io.WriteCloser
+}
+
+func (s *writerSink) Write(events []*Event) error {
+	w := bufio.NewWriter(s.writer)
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (s *writerSink) Close() error {
+	return s.writer.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// NewStdoutSink writes events to standard output
+func NewStdoutSink() Sink {
+	return &writerSink{writer: nopCloser{os.Stdout}}
+}
+
+// NewFileSink writes events to file which is rotated by size
+func NewFileSink(options *FileOptions) Sink {
+	return &writerSink{writer: &lumberjack.Logger{
+		Filename:   options.Path,
+		MaxSize:    options.MaxSize,
+		MaxBackups: options.MaxBackups,
+		MaxAge:     options.MaxAge,
+		Compress:   options.Compress,
+	}}
+}
+
+// webhookSink posts events to remote endpoint in batch
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(options *WebhookOptions) (Sink, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.InsecureSkipTLSVerify}
+	if len(options.CAFile) != 0 {
+		ca, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &webhookSink{
+		url:    options.URL,
+		client: &http.Client{Transport: transport, Timeout: options.Timeout},
+	}, nil
+}
+
+func (s *webhookSink) Write(events []*Event) error {
+	body, err := json.Marshal(&EventList{Items: events})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("audit webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
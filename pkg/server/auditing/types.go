@@ -0,0 +1,51 @@
+package auditing
+
+import (
+	"context"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// Event is kubernetes audit event extended with region and cluster of requested resource
+type Event struct {
+	auditv1.Event
+
+	// Region of requested resource
+	Region string `json:"region,omitempty"`
+
+	// Cluster of requested resource
+	Cluster string `json:"cluster,omitempty"`
+
+	// LatencyMilliseconds is the time taken to serve the request
+	LatencyMilliseconds int64 `json:"latencyMilliseconds"`
+}
+
+// EventList is the body posted to webhook
+type EventList struct {
+	Items []*Event `json:"items"`
+}
+
+// SetUser sets user who sent the request
+func (e *Event) SetUser(u user.Info) {
+	e.User = authnv1.UserInfo{
+		Username: u.GetName(),
+		UID:      u.GetUID(),
+		Groups:   u.GetGroups(),
+	}
+}
+
+type eventKey struct{}
+
+// WithEvent returns a copy of parent carrying audit event of the request, requests are audited
+// before they are authenticated, the user is set once it's known
+func WithEvent(parent context.Context, e *Event) context.Context {
+	return context.WithValue(parent, eventKey{}, e)
+}
+
+// EventFrom returns audit event of the request carried by ctx
+func EventFrom(ctx context.Context) (*Event, bool) {
+	e, ok := ctx.Value(eventKey{}).(*Event)
+	return e, ok
+}
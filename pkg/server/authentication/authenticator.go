@@ -0,0 +1,92 @@
+package authentication
+
+import (
+	"fmt"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/group"
+	"k8s.io/apiserver/pkg/authentication/request/anonymous"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	"k8s.io/apiserver/pkg/authentication/request/union"
+	"k8s.io/apiserver/pkg/authentication/request/websocket"
+	"k8s.io/apiserver/pkg/authentication/token/tokenfile"
+	tokenunion "k8s.io/apiserver/pkg/authentication/token/union"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/apiserver/plugin/pkg/authenticator/token/oidc"
+
+	"captain/pkg/server/authentication/basicauth"
+)
+
+// NewAuthenticator builds request authenticator from options, the returned authenticator
+// accepts requests carrying any valid credential.
+func NewAuthenticator(stopCh <-chan struct{}, options *Options) (authenticator.Request, error) {
+	var authenticators []authenticator.Request
+	var tokenAuthenticators []authenticator.Token
+
+	if len(options.TokenAuthFile) != 0 {
+		tokenAuth, err := tokenfile.NewCSV(options.TokenAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load token auth file, %v", err)
+		}
+		tokenAuthenticators = append(tokenAuthenticators, tokenAuth)
+	}
+
+	if oidcOptions := options.OIDCOptions; oidcOptions != nil && len(oidcOptions.IssuerURL) != 0 {
+		opts := oidc.Options{
+			IssuerURL:            oidcOptions.IssuerURL,
+			ClientID:             oidcOptions.ClientID,
+			UsernameClaim:        oidcOptions.UsernameClaim,
+			UsernamePrefix:       oidcOptions.UsernamePrefix,
+			GroupsClaim:          oidcOptions.GroupsClaim,
+			GroupsPrefix:         oidcOptions.GroupsPrefix,
+			SupportedSigningAlgs: oidcOptions.SigningAlgs,
+			RequiredClaims:       oidcOptions.RequiredClaims,
+		}
+		if len(oidcOptions.CAFile) != 0 {
+			ca, err := dynamiccertificates.NewDynamicCAContentFromFile("oidc-authenticator", oidcOptions.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load oidc ca file, %v", err)
+			}
+			opts.CAContentProvider = ca
+		}
+
+		oidcAuth, err := oidc.New(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create oidc authenticator, %v", err)
+		}
+		go func() {
+			<-stopCh
+			oidcAuth.Close()
+		}()
+		tokenAuthenticators = append(tokenAuthenticators, oidcAuth)
+	}
+
+	if len(tokenAuthenticators) != 0 {
+		tokenAuth := tokenunion.New(tokenAuthenticators...)
+		// browsers can't set headers of websockets, tokens of terminals are sent as subprotocols
+		authenticators = append(authenticators, bearertoken.New(tokenAuth), websocket.NewProtocolAuthenticator(tokenAuth))
+	}
+
+	if len(options.BasicAuthFile) != 0 {
+		passwordAuth, err := basicauth.NewCSV(options.BasicAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load basic auth file, %v", err)
+		}
+		authenticators = append(authenticators, basicauth.New(passwordAuth))
+	}
+
+	if len(authenticators) == 0 && !options.AnonymousAccess {
+		return nil, fmt.Errorf("authentication is enabled but no authenticator is configured")
+	}
+
+	// authenticated users are put in system:authenticated group, so that permissions can be
+	// granted to all of them at once
+	auth := group.NewAuthenticatedGroupAdder(union.New(authenticators...))
+
+	if options.AnonymousAccess {
+		// anonymous goes last, only requests without credential reach it
+		return union.NewFailOnError(auth, anonymous.NewAuthenticator()), nil
+	}
+
+	return auth, nil
+}
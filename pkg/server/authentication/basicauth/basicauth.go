@@ -0,0 +1,119 @@
+package basicauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog"
+)
+
+var errInvalidAuth = errors.New("invalid username/password combination")
+
+// Password checks a username and password against a backing authentication store
+type Password interface {
+	AuthenticatePassword(ctx context.Context, username, password string) (*authenticator.Response, bool, error)
+}
+
+// Authenticator authenticates requests using basic auth
+type Authenticator struct {
+	auth Password
+}
+
+// New returns a request authenticator that validates credentials using the provided password authenticator
+func New(auth Password) *Authenticator {
+	return &Authenticator{auth: auth}
+}
+
+// AuthenticateRequest authenticates the request using the "Authorization: Basic" header in the request
+func (a *Authenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
+	username, password, found := req.BasicAuth()
+	if !found {
+		return nil, false, nil
+	}
+
+	resp, ok, err := a.auth.AuthenticatePassword(req.Context(), username, password)
+
+	// If the password authenticator didn't error, provide a default error
+	if !ok && err == nil {
+		err = errInvalidAuth
+	}
+
+	return resp, ok, err
+}
+
+type passwordUser struct {
+	password string
+	info     *user.DefaultInfo
+}
+
+// PasswordAuthenticator authenticates users from a static password file
+type PasswordAuthenticator struct {
+	users map[string]*passwordUser
+}
+
+// NewCSV returns a PasswordAuthenticator, populated from a CSV file.
+// The CSV file must contain records in the format "password,username,useruid"
+func NewCSV(path string) (*PasswordAuthenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	recordNum := 0
+	users := make(map[string]*passwordUser)
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("basic auth file '%s' must have at least 3 columns (password, user name, user uid), found %d", path, len(record))
+		}
+
+		recordNum++
+		if record[0] == "" || record[1] == "" {
+			klog.Warningf("empty password or user name has been found in basic auth file '%s', record number '%d'", path, recordNum)
+			continue
+		}
+
+		obj := &passwordUser{
+			password: record[0],
+			info: &user.DefaultInfo{
+				Name: record[1],
+				UID:  record[2],
+			},
+		}
+		if len(record) >= 4 {
+			obj.info.Groups = strings.Split(record[3], ",")
+		}
+		if _, exist := users[obj.info.Name]; exist {
+			klog.Warningf("duplicate user has been found in basic auth file '%s', record number '%d'", path, recordNum)
+		}
+		users[obj.info.Name] = obj
+	}
+
+	return &PasswordAuthenticator{users: users}, nil
+}
+
+func (a *PasswordAuthenticator) AuthenticatePassword(ctx context.Context, username, password string) (*authenticator.Response, bool, error) {
+	u, ok := a.users[username]
+	if !ok || subtle.ConstantTimeCompare([]byte(u.password), []byte(password)) != 1 {
+		return nil, false, nil
+	}
+	return &authenticator.Response{User: u.info}, true, nil
+}
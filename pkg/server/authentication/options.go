@@ -0,0 +1,146 @@
+package authentication
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// Options holds configuration of authenticators used by captain-server, requests are
+// authenticated by the first authenticator accepting them
+type Options struct {
+	// Enable requires every request to be authenticated before being served or proxied,
+	// all requests are served as is if authentication is disabled.
+	Enable bool `json:"enable" yaml:"enable"`
+
+	// AnonymousAccess serves requests carrying no credential as system:anonymous
+	// instead of rejecting them.
+	AnonymousAccess bool `json:"anonymousAccess,omitempty" yaml:"anonymousAccess,omitempty"`
+
+	// TokenAuthFile is a csv file of bearer tokens, every line in format
+	// token,user,uid,"group1,group2,group3"
+	TokenAuthFile string `json:"tokenAuthFile,omitempty" yaml:"tokenAuthFile,omitempty"`
+
+	// BasicAuthFile is a csv file of basic auth users, every line in format
+	// password,user,uid,"group1,group2,group3"
+	BasicAuthFile string `json:"basicAuthFile,omitempty" yaml:"basicAuthFile,omitempty"`
+
+	// OIDCOptions verifies bearer tokens as OpenID Connect ID tokens if issuer is set
+	OIDCOptions *OIDCOptions `json:"oidc,omitempty" yaml:"oidc,omitempty" mapstructure:"oidc"`
+}
+
+type OIDCOptions struct {
+	// IssuerURL is the URL of the OpenID issuer, only https scheme is accepted
+	IssuerURL string `json:"issuerURL,omitempty" yaml:"issuerURL,omitempty"`
+
+	// ClientID tokens must be issued for
+	ClientID string `json:"clientID,omitempty" yaml:"clientID,omitempty"`
+
+	// CAFile signs the certificate of issuer, host's root CA set is used if left empty
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+
+	// UsernameClaim is the claim used as user name, default to sub
+	UsernameClaim string `json:"usernameClaim,omitempty" yaml:"usernameClaim,omitempty"`
+
+	// UsernamePrefix is prepended to user names to prevent clashes with other authenticators
+	UsernamePrefix string `json:"usernamePrefix,omitempty" yaml:"usernamePrefix,omitempty"`
+
+	// GroupsClaim is the claim used as user groups
+	GroupsClaim string `json:"groupsClaim,omitempty" yaml:"groupsClaim,omitempty"`
+
+	// GroupsPrefix is prepended to group names to prevent clashes with other authenticators
+	GroupsPrefix string `json:"groupsPrefix,omitempty" yaml:"groupsPrefix,omitempty"`
+
+	// SigningAlgs are the accepted signing algorithms, default to RS256
+	SigningAlgs []string `json:"signingAlgs,omitempty" yaml:"signingAlgs,omitempty"`
+
+	// RequiredClaims must be present in tokens with the same values
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty" yaml:"requiredClaims,omitempty"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		OIDCOptions: &OIDCOptions{
+			UsernameClaim: "sub",
+			SigningAlgs:   []string{"RS256"},
+		},
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if !o.Enable {
+		return errs
+	}
+
+	for _, file := range []string{o.TokenAuthFile, o.BasicAuthFile} {
+		if len(file) == 0 {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if o.OIDCOptions != nil && len(o.OIDCOptions.IssuerURL) != 0 {
+		if len(o.OIDCOptions.ClientID) == 0 {
+			errs = append(errs, fmt.Errorf("oidc client id must not be empty if issuer url is set"))
+		}
+		if len(o.OIDCOptions.CAFile) != 0 {
+			if _, err := os.Stat(o.OIDCOptions.CAFile); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "authentication-enable", s.Enable, "Authenticate all requests before serving them.")
+
+	fs.BoolVar(&o.AnonymousAccess, "anonymous-access", s.AnonymousAccess, "Serve requests without "+
+		"credential as system:anonymous instead of rejecting them.")
+
+	fs.StringVar(&o.TokenAuthFile, "token-auth-file", s.TokenAuthFile, "Csv file of bearer tokens, "+
+		"every line in format token,user,uid,\"group1,group2\".")
+
+	fs.StringVar(&o.BasicAuthFile, "basic-auth-file", s.BasicAuthFile, "Csv file of basic auth users, "+
+		"every line in format password,user,uid,\"group1,group2\".")
+
+	if o.OIDCOptions == nil {
+		o.OIDCOptions = &OIDCOptions{}
+	}
+	if s.OIDCOptions == nil {
+		s.OIDCOptions = &OIDCOptions{}
+	}
+
+	fs.StringVar(&o.OIDCOptions.IssuerURL, "oidc-issuer-url", s.OIDCOptions.IssuerURL, "The URL of the "+
+		"OpenID issuer, only https scheme is accepted. Tokens are verified as OpenID Connect ID tokens if set.")
+
+	fs.StringVar(&o.OIDCOptions.ClientID, "oidc-client-id", s.OIDCOptions.ClientID, "The client ID "+
+		"tokens must be issued for.")
+
+	fs.StringVar(&o.OIDCOptions.CAFile, "oidc-ca-file", s.OIDCOptions.CAFile, "The CA signs the certificate "+
+		"of OpenID issuer, host's root CA set is used if left empty.")
+
+	fs.StringVar(&o.OIDCOptions.UsernameClaim, "oidc-username-claim", s.OIDCOptions.UsernameClaim, "The "+
+		"claim used as user name.")
+
+	fs.StringVar(&o.OIDCOptions.UsernamePrefix, "oidc-username-prefix", s.OIDCOptions.UsernamePrefix, "The "+
+		"prefix prepended to user names.")
+
+	fs.StringVar(&o.OIDCOptions.GroupsClaim, "oidc-groups-claim", s.OIDCOptions.GroupsClaim, "The "+
+		"claim used as user groups.")
+
+	fs.StringVar(&o.OIDCOptions.GroupsPrefix, "oidc-groups-prefix", s.OIDCOptions.GroupsPrefix, "The "+
+		"prefix prepended to group names.")
+
+	fs.StringSliceVar(&o.OIDCOptions.SigningAlgs, "oidc-signing-algs", s.OIDCOptions.SigningAlgs, "The "+
+		"accepted signing algorithms.")
+
+	fs.StringToStringVar(&o.OIDCOptions.RequiredClaims, "oidc-required-claim", s.OIDCOptions.RequiredClaims, "A "+
+		"key=value pair that must be present in tokens, repeat this flag to specify multiple claims.")
+}
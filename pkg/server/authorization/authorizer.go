@@ -0,0 +1,256 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"captain/pkg/server/request"
+)
+
+// captain resources api serves kinds in path /resources/{resources}/name/{name}, which
+// is parsed as resource "resources" named {resources} by RequestInfo
+const captainResourcesGroup = "resources.captain.io"
+
+// route maps a captain route to the kind, subresource and verb it's authorized as, parts of pattern
+// are matched with parts of path after namespace, {kind}, {name} and {action} match any part
+type route struct {
+	pattern     string
+	resource    string
+	subresource string
+	// verb replaces verb of request if it's not empty
+	verb string
+}
+
+// routes of resources.captain.io normalized to attributes like those of kube-apiserver, logs are
+// authorized as get log, terminals as create exec, workload actions as update of the action
+var routes = []route{
+	{pattern: "pods/{name}/logs", resource: "pods", subresource: "log", verb: "get"},
+	{pattern: "workloads/{kind}/{name}/logs", resource: "{kind}", subresource: "log", verb: "get"},
+	{pattern: "pods/{name}/exec", resource: "pods", subresource: "exec", verb: "create"},
+	{pattern: "nodes/{name}/exec", resource: "nodes", subresource: "exec", verb: "create"},
+	{pattern: "workloads/{kind}/{name}/actions/{action}", resource: "{kind}", subresource: "{action}", verb: "update"},
+	{pattern: "workloads/{kind}/{name}/revisions", resource: "{kind}", subresource: "revisions", verb: "get"},
+	{pattern: "resources/nodes/{name}/actions/{action}", resource: "nodes", subresource: "{action}", verb: "create"},
+	{pattern: "resources/nodes/{name}/drain", resource: "nodes", subresource: "drain"},
+	{pattern: "cronjobs/{name}/actions/{action}", resource: "cronjobs", subresource: "{action}", verb: "create"},
+	{pattern: "cronjobs/{name}/runs", resource: "cronjobs", subresource: "runs", verb: "get"},
+	{pattern: "jobs/{name}/actions/{action}", resource: "jobs", subresource: "{action}", verb: "create"},
+}
+
+// match returns values of variables in pattern if parts match it
+func (r route) match(parts []string) (map[string]string, bool) {
+	patterns := strings.Split(r.pattern, "/")
+	if len(patterns) != len(parts) {
+		return nil, false
+	}
+	variables := make(map[string]string)
+	for i, pattern := range patterns {
+		if strings.HasPrefix(pattern, "{") {
+			variables[pattern] = parts[i]
+		} else if pattern != parts[i] {
+			return nil, false
+		}
+	}
+	return variables, true
+}
+
+// Attributes is an interface used by an Authorizer to get information about a request
+// that is used to make an authorization decision. Region and cluster of requested
+// resource are included besides kubernetes authorizer attributes.
+type Attributes interface {
+	authorizer.Attributes
+
+	// GetRegion returns the region of requested resource
+	GetRegion() string
+
+	// GetCluster returns the cluster of requested resource
+	GetCluster() string
+}
+
+// Authorizer makes an authorization decision based on information gained by making
+// zero or more calls to methods of the Attributes interface.
+type Authorizer interface {
+	Authorize(ctx context.Context, a Attributes) (authorized authorizer.Decision, reason string, err error)
+}
+
+type authorizerKey struct{}
+
+// WithAuthorizer returns a copy of parent carrying auth, handlers serving many kinds or clusters in
+// one request authorize each of them with it
+func WithAuthorizer(parent context.Context, auth Authorizer) context.Context {
+	return context.WithValue(parent, authorizerKey{}, auth)
+}
+
+// AuthorizerFrom returns the authorizer carried by ctx, there is none if authorization is disabled
+func AuthorizerFrom(ctx context.Context) (Authorizer, bool) {
+	auth, ok := ctx.Value(authorizerKey{}).(Authorizer)
+	return auth, ok
+}
+
+// AttributesRecord implements Attributes interface
+type AttributesRecord struct {
+	authorizer.AttributesRecord
+
+	Region  string
+	Cluster string
+}
+
+func (a AttributesRecord) GetRegion() string {
+	return a.Region
+}
+
+func (a AttributesRecord) GetCluster() string {
+	return a.Cluster
+}
+
+// NewAttributes builds authorizer attributes from request info, requests without region or
+// cluster are served by host cluster, so they are treated as requests to host region and cluster
+func NewAttributes(u user.Info, info *request.RequestInfo, hostRegion, hostCluster string) *AttributesRecord {
+	attributes := &AttributesRecord{
+		AttributesRecord: authorizer.AttributesRecord{
+			User:            u,
+			Verb:            info.Verb,
+			Namespace:       info.Namespace,
+			APIGroup:        info.APIGroup,
+			APIVersion:      info.APIVersion,
+			Resource:        info.Resource,
+			Subresource:     info.Subresource,
+			Name:            info.Name,
+			ResourceRequest: info.IsResourceRequest,
+			Path:            info.Path,
+		},
+		Region:  info.Region,
+		Cluster: info.Cluster,
+	}
+
+	if len(attributes.Cluster) == 0 {
+		attributes.Region, attributes.Cluster = hostRegion, hostCluster
+	}
+
+	if info.IsResourceRequest && info.APIGroup == captainResourcesGroup {
+		for _, route := range routes {
+			variables, ok := route.match(info.Parts)
+			if !ok {
+				continue
+			}
+			attributes.Resource = expand(route.resource, variables)
+			attributes.Subresource = expand(route.subresource, variables)
+			attributes.Name = variables["{name}"]
+			if len(route.verb) != 0 {
+				attributes.Verb = route.verb
+			}
+			return attributes
+		}
+	}
+
+	// normalize .../resources/{resources}/name/{name} into the kind and name requested
+	if info.IsResourceRequest && info.APIGroup == captainResourcesGroup && info.Resource == "resources" {
+		attributes.Resource = info.Name
+		attributes.Subresource = ""
+		attributes.Name = ""
+		if info.Subresource == "name" {
+			parts := strings.Split(strings.TrimSuffix(info.Path, "/"), "/")
+			attributes.Name = parts[len(parts)-1]
+		}
+		// verbs of list requests are resolved as get since they look like getting a named object
+		if len(attributes.Name) == 0 && attributes.Verb == "get" {
+			attributes.Verb = "list"
+		}
+	}
+
+	return attributes
+}
+
+// AuthorizedPerCluster returns true if request lists resources across clusters, i.e.
+// .../fleet/resources/{resources}. Such requests are authorized by handlers for each cluster with
+// the authorizer in context, clusters not allowed are skipped.
+func AuthorizedPerCluster(info *request.RequestInfo) bool {
+	return info.IsResourceRequest && info.APIGroup == captainResourcesGroup && info.Resource == "fleet" && info.Name == "resources"
+}
+
+// expand replaces variable in s with its value
+func expand(s string, variables map[string]string) string {
+	if value, ok := variables[s]; ok {
+		return value
+	}
+	return s
+}
+
+// alwaysAllowAuthorizer allows all requests
+type alwaysAllowAuthorizer struct{}
+
+func (alwaysAllowAuthorizer) Authorize(ctx context.Context, a Attributes) (authorizer.Decision, string, error) {
+	return authorizer.DecisionAllow, "", nil
+}
+
+func NewAlwaysAllowAuthorizer() Authorizer {
+	return alwaysAllowAuthorizer{}
+}
+
+// pathAuthorizer allows requests to the configured non-resource paths, paths ending with
+// * are treated as prefixes
+type pathAuthorizer struct {
+	paths    map[string]bool
+	prefixes []string
+}
+
+func (p *pathAuthorizer) Authorize(ctx context.Context, a Attributes) (authorizer.Decision, string, error) {
+	if a.IsResourceRequest() {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+
+	path := a.GetPath()
+	if p.paths[path] {
+		return authorizer.DecisionAllow, "", nil
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return authorizer.DecisionAllow, "", nil
+		}
+	}
+	return authorizer.DecisionNoOpinion, "", nil
+}
+
+func NewPathAuthorizer(alwaysAllowPaths []string) (Authorizer, error) {
+	p := &pathAuthorizer{paths: make(map[string]bool)}
+	for _, path := range alwaysAllowPaths {
+		if strings.Contains(path, "*") && !strings.HasSuffix(path, "*") {
+			return nil, fmt.Errorf("only trailing * allowed in %q", path)
+		}
+		if strings.HasSuffix(path, "*") {
+			p.prefixes = append(p.prefixes, strings.TrimSuffix(path, "*"))
+		} else {
+			p.paths[path] = true
+		}
+	}
+	return p, nil
+}
+
+// unionAuthorizer authorizes requests against a chain of authorizers, the first
+// decision which is not DecisionNoOpinion wins
+type unionAuthorizer []Authorizer
+
+func (u unionAuthorizer) Authorize(ctx context.Context, a Attributes) (authorizer.Decision, string, error) {
+	var reasons []string
+	for _, auth := range u {
+		decision, reason, err := auth.Authorize(ctx, a)
+		if err != nil {
+			return authorizer.DecisionNoOpinion, reason, err
+		}
+		if decision != authorizer.DecisionNoOpinion {
+			return decision, reason, nil
+		}
+		if len(reason) != 0 {
+			reasons = append(reasons, reason)
+		}
+	}
+	return authorizer.DecisionNoOpinion, strings.Join(reasons, "\n"), nil
+}
+
+func NewUnionAuthorizer(authorizers ...Authorizer) Authorizer {
+	return unionAuthorizer(authorizers)
+}
@@ -0,0 +1,84 @@
+package authorization
+
+import (
+	"net/http"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"captain/pkg/server/request"
+)
+
+func TestNewAttributes(t *testing.T) {
+	resolver := &request.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis", "capis")}
+	prefix := "/capis/resources.captain.io/alpha1"
+	tests := []struct {
+		method string
+		path   string
+
+		verb        string
+		resource    string
+		subresource string
+		name        string
+		cluster     string
+	}{
+		{http.MethodGet, prefix + "/namespaces/default/resources/pods", "list", "pods", "", "", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/resources/pods/name/web-0", "get", "pods", "", "web-0", "host"},
+		{http.MethodGet, prefix + "/resources/nodes/aggregate", "list", "nodes", "", "", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/pods/web-0/logs", "get", "pods", "log", "web-0", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/workloads/deployments/web/logs", "get", "deployments", "log", "web", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/pods/web-0/exec", "create", "pods", "exec", "web-0", "host"},
+		{http.MethodGet, "/regions/wx/clusters/c1" + prefix + "/nodes/node-1/exec", "create", "nodes", "exec", "node-1", "c1"},
+		{http.MethodPost, prefix + "/namespaces/default/workloads/deployments/web/actions/scale", "update", "deployments", "scale", "web", "host"},
+		{http.MethodPost, prefix + "/namespaces/default/workloads/statefulsets/db/actions/restart", "update", "statefulsets", "restart", "db", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/workloads/deployments/web/revisions", "get", "deployments", "revisions", "web", "host"},
+		{http.MethodPost, prefix + "/resources/nodes/node-1/actions/cordon", "create", "nodes", "cordon", "node-1", "host"},
+		{http.MethodPost, prefix + "/resources/nodes/node-1/actions/uncordon", "create", "nodes", "uncordon", "node-1", "host"},
+		{http.MethodPost, "/regions/wx/clusters/c1" + prefix + "/resources/nodes/node-1/actions/drain", "create", "nodes", "drain", "node-1", "c1"},
+		{http.MethodGet, prefix + "/resources/nodes/node-1/drain", "get", "nodes", "drain", "node-1", "host"},
+		{http.MethodDelete, prefix + "/resources/nodes/node-1/drain", "delete", "nodes", "drain", "node-1", "host"},
+		{http.MethodPost, prefix + "/namespaces/default/cronjobs/backup/actions/trigger", "create", "cronjobs", "trigger", "backup", "host"},
+		{http.MethodPost, prefix + "/namespaces/default/cronjobs/backup/actions/suspend", "create", "cronjobs", "suspend", "backup", "host"},
+		{http.MethodGet, prefix + "/namespaces/default/cronjobs/backup/runs", "get", "cronjobs", "runs", "backup", "host"},
+		{http.MethodPost, prefix + "/namespaces/default/jobs/backup-1/actions/rerun", "create", "jobs", "rerun", "backup-1", "host"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.path, nil)
+		info, err := resolver.NewRequestInfo(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := NewAttributes(&user.DefaultInfo{Name: "alice"}, info, "host", "host")
+		if a.GetVerb() != test.verb || a.GetResource() != test.resource || a.GetSubresource() != test.subresource ||
+			a.GetName() != test.name || a.GetCluster() != test.cluster {
+			t.Errorf("%s %s: expected %s %s/%s %s in %s, got %s %s/%s %s in %s", test.method, test.path,
+				test.verb, test.resource, test.subresource, test.name, test.cluster,
+				a.GetVerb(), a.GetResource(), a.GetSubresource(), a.GetName(), a.GetCluster())
+		}
+	}
+}
+
+func TestAuthorizedPerCluster(t *testing.T) {
+	resolver := &request.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis", "capis")}
+	prefix := "/capis/resources.captain.io/alpha1"
+	tests := map[string]bool{
+		prefix + "/fleet/resources/pods":                              true,
+		prefix + "/namespaces/default/fleet/resources/pods":           true,
+		prefix + "/fleet/resources/deployments/aggregate":             true,
+		prefix + "/resources/pods":                                    false,
+		"/regions/wx/clusters/c1" + prefix + "/resources/fleet":       false,
+		"/regions/wx/clusters/c1" + prefix + "/namespaces/fleet/pods": false,
+	}
+	for path, expected := range tests {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		info, err := resolver.NewRequestInfo(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := AuthorizedPerCluster(info); got != expected {
+			t.Errorf("%s: expected %v, got %v", path, expected, got)
+		}
+	}
+}
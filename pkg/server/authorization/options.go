@@ -0,0 +1,62 @@
+package authorization
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// ModeAlwaysAllow allows all requests
+	ModeAlwaysAllow = "AlwaysAllow"
+
+	// ModeRBAC authorizes requests against roles and bindings in policy file
+	ModeRBAC = "RBAC"
+)
+
+type Options struct {
+	// Mode is the authorization mode, AlwaysAllow or RBAC
+	Mode string `json:"mode" yaml:"mode"`
+
+	// PolicyFile holds roles and bindings used in RBAC mode
+	PolicyFile string `json:"policyFile,omitempty" yaml:"policyFile,omitempty"`
+
+	// AlwaysAllowPaths are non-resource paths allowed for everyone, trailing * matches any suffix
+	AlwaysAllowPaths []string `json:"alwaysAllowPaths,omitempty" yaml:"alwaysAllowPaths,omitempty"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Mode:             ModeAlwaysAllow,
+		AlwaysAllowPaths: []string{"/capis/version", "/open-api*"},
+	}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	switch o.Mode {
+	case ModeAlwaysAllow:
+	case ModeRBAC:
+		if len(o.PolicyFile) == 0 {
+			errs = append(errs, fmt.Errorf("policy file must not be empty in %s authorization mode", ModeRBAC))
+		} else if _, err := os.Stat(o.PolicyFile); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported authorization mode %s, must be one of %s, %s", o.Mode, ModeAlwaysAllow, ModeRBAC))
+	}
+
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.StringVar(&o.Mode, "authorization-mode", s.Mode, "Authorization mode, one of AlwaysAllow, RBAC.")
+
+	fs.StringVar(&o.PolicyFile, "authorization-policy-file", s.PolicyFile, "File holds roles and bindings "+
+		"used in RBAC authorization mode.")
+
+	fs.StringSliceVar(&o.AlwaysAllowPaths, "authorization-always-allow-paths", s.AlwaysAllowPaths, "Non-resource "+
+		"paths allowed for everyone, trailing * matches any suffix.")
+}
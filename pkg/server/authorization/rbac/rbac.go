@@ -0,0 +1,259 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"sigs.k8s.io/yaml"
+
+	"captain/pkg/server/authorization"
+)
+
+// Policy is the set of roles and bindings loaded from policy file, for example
+//
+//	roles:
+//	- name: developer
+//	  rules:
+//	  - apiGroups: ["", "apps", "resources.captain.io"]
+//	    resources: ["pods", "pods/log", "deployments"]
+//	    verbs: ["get", "list", "watch"]
+//	bindings:
+//	- name: team-a-developers
+//	  role: developer
+//	  subjects:
+//	  - kind: Group
+//	    name: team-a
+//	  scope:
+//	    region: cn-east
+//	    cluster: prod-1
+//	    namespace: team-a
+type Policy struct {
+	Roles    []Role    `json:"roles"`
+	Bindings []Binding `json:"bindings"`
+}
+
+// Role is a set of rules granting permissions
+type Role struct {
+	Name  string              `json:"name"`
+	Rules []rbacv1.PolicyRule `json:"rules"`
+}
+
+// Binding grants permissions of role to subjects within scope
+type Binding struct {
+	Name     string           `json:"name"`
+	Role     string           `json:"role"`
+	Subjects []rbacv1.Subject `json:"subjects"`
+	Scope    Scope            `json:"scope,omitempty"`
+}
+
+// Scope limits where a binding takes effect. Empty scope is global, region, cluster and
+// namespace narrow it down level by level, fields left empty match everything at that level.
+type Scope struct {
+	Region    string `json:"region,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (s Scope) String() string {
+	var parts []string
+	if len(s.Region) != 0 {
+		parts = append(parts, "regions/"+s.Region)
+	}
+	if len(s.Cluster) != 0 {
+		parts = append(parts, "clusters/"+s.Cluster)
+	}
+	if len(s.Namespace) != 0 {
+		parts = append(parts, "namespaces/"+s.Namespace)
+	}
+	if len(parts) == 0 {
+		return "global"
+	}
+	return strings.Join(parts, "/")
+}
+
+// IsGlobal returns true if scope covers all regions and clusters
+func (s Scope) IsGlobal() bool {
+	return len(s.Region) == 0 && len(s.Cluster) == 0 && len(s.Namespace) == 0
+}
+
+// Covers returns true if requested resource locates in scope
+func (s Scope) Covers(a authorization.Attributes) bool {
+	if len(s.Region) != 0 && s.Region != a.GetRegion() {
+		return false
+	}
+	if len(s.Cluster) != 0 && s.Cluster != a.GetCluster() {
+		return false
+	}
+	if len(s.Namespace) != 0 {
+		// cluster scoped resources and non-resource urls are out of any namespace
+		return a.IsResourceRequest() && s.Namespace == a.GetNamespace()
+	}
+	// non-resource urls are not bound to any region or cluster
+	return a.IsResourceRequest() || s.IsGlobal()
+}
+
+// RuleAuthorizer authorizes requests against roles and bindings in policy
+type RuleAuthorizer struct {
+	roles    map[string]*Role
+	bindings []Binding
+}
+
+func New(policy *Policy) (*RuleAuthorizer, error) {
+	r := &RuleAuthorizer{roles: make(map[string]*Role)}
+
+	for i := range policy.Roles {
+		role := &policy.Roles[i]
+		if _, exist := r.roles[role.Name]; exist {
+			return nil, fmt.Errorf("duplicate role %s", role.Name)
+		}
+		r.roles[role.Name] = role
+	}
+
+	for _, binding := range policy.Bindings {
+		if _, exist := r.roles[binding.Role]; !exist {
+			return nil, fmt.Errorf("role %s of binding %s not found", binding.Role, binding.Name)
+		}
+		if len(binding.Scope.Namespace) != 0 && len(binding.Scope.Cluster) == 0 {
+			return nil, fmt.Errorf("cluster of binding %s must be specified along with namespace", binding.Name)
+		}
+	}
+	r.bindings = policy.Bindings
+
+	return r, nil
+}
+
+// NewFromFile loads policy from yaml or json file
+func NewFromFile(path string) (*RuleAuthorizer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if err = yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s, %v", path, err)
+	}
+
+	return New(policy)
+}
+
+func (r *RuleAuthorizer) Authorize(ctx context.Context, a authorization.Attributes) (authorizer.Decision, string, error) {
+	u := a.GetUser()
+	if u == nil {
+		return authorizer.DecisionNoOpinion, "no user", nil
+	}
+
+	for _, binding := range r.bindings {
+		if !binding.Scope.Covers(a) || !appliesTo(u, binding.Subjects) {
+			continue
+		}
+		role := r.roles[binding.Role]
+		for i := range role.Rules {
+			if ruleAllows(a, &role.Rules[i]) {
+				return authorizer.DecisionAllow, fmt.Sprintf("allowed by binding %q of role %q in %s scope",
+					binding.Name, binding.Role, binding.Scope), nil
+			}
+		}
+	}
+
+	return authorizer.DecisionNoOpinion, fmt.Sprintf("no binding grants user %q to %s", u.GetName(), describe(a)), nil
+}
+
+func describe(a authorization.Attributes) string {
+	if !a.IsResourceRequest() {
+		return fmt.Sprintf("%s %s", a.GetVerb(), a.GetPath())
+	}
+
+	resource := a.GetResource()
+	if len(a.GetSubresource()) != 0 {
+		resource += "/" + a.GetSubresource()
+	}
+	if len(a.GetAPIGroup()) != 0 {
+		resource += "." + a.GetAPIGroup()
+	}
+
+	scope := Scope{Region: a.GetRegion(), Cluster: a.GetCluster(), Namespace: a.GetNamespace()}
+	return fmt.Sprintf("%s %s in %s", a.GetVerb(), resource, scope)
+}
+
+func appliesTo(u user.Info, subjects []rbacv1.Subject) bool {
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.UserKind:
+			if u.GetName() == subject.Name {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range u.GetGroups() {
+				if group == subject.Name {
+					return true
+				}
+			}
+		case rbacv1.ServiceAccountKind:
+			if serviceaccount.MakeUsername(subject.Namespace, subject.Name) == u.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleAllows follows the semantics of kubernetes RBAC rules
+func ruleAllows(a authorization.Attributes, rule *rbacv1.PolicyRule) bool {
+	if !has(rule.Verbs, a.GetVerb()) {
+		return false
+	}
+
+	if !a.IsResourceRequest() {
+		return nonResourceURLMatches(rule, a.GetPath())
+	}
+
+	combinedResource := a.GetResource()
+	if len(a.GetSubresource()) != 0 {
+		combinedResource += "/" + a.GetSubresource()
+	}
+
+	return has(rule.APIGroups, a.GetAPIGroup()) &&
+		resourceMatches(rule, combinedResource, a.GetSubresource()) &&
+		(len(rule.ResourceNames) == 0 || has(rule.ResourceNames, a.GetName()))
+}
+
+func has(set []string, value string) bool {
+	for _, s := range set {
+		if s == rbacv1.VerbAll || s == value {
+			return true
+		}
+	}
+	return false
+}
+
+func resourceMatches(rule *rbacv1.PolicyRule, combinedResource, subresource string) bool {
+	for _, resource := range rule.Resources {
+		switch {
+		case resource == rbacv1.ResourceAll, resource == combinedResource:
+			return true
+		// */subresource matches the subresource of any resource
+		case len(subresource) != 0 && resource == "*/"+subresource:
+			return true
+		}
+	}
+	return false
+}
+
+func nonResourceURLMatches(rule *rbacv1.PolicyRule, path string) bool {
+	for _, url := range rule.NonResourceURLs {
+		if url == rbacv1.NonResourceAll || url == path {
+			return true
+		}
+		if strings.HasSuffix(url, "*") && strings.HasPrefix(path, strings.TrimRight(url, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"captain/pkg/server/authorization"
+)
+
+func TestRuleAuthorizer(t *testing.T) {
+	policy := &Policy{
+		Roles: []Role{
+			{
+				Name: "viewer",
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{"", "resources.captain.io"}, Resources: []string{"pods", "pods/log"}, Verbs: []string{"get", "list"}},
+				},
+			},
+			{
+				Name: "admin",
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+					{NonResourceURLs: []string{"/capis/*"}, Verbs: []string{"*"}},
+				},
+			},
+		},
+		Bindings: []Binding{
+			{
+				Name:     "team-a",
+				Role:     "viewer",
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+				Scope:    Scope{Region: "cn-east", Cluster: "prod-1", Namespace: "team-a"},
+			},
+			{
+				Name:     "ops",
+				Role:     "admin",
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "ops"}},
+				Scope:    Scope{Region: "cn-east"},
+			},
+			{
+				Name:     "root",
+				Role:     "admin",
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "root"}},
+			},
+		},
+	}
+
+	auth, err := New(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	teamA := &user.DefaultInfo{Name: "alice", Groups: []string{"team-a"}}
+	ops := &user.DefaultInfo{Name: "ops"}
+	root := &user.DefaultInfo{Name: "root"}
+
+	resource := func(u user.Info, verb, region, cluster, namespace, group, resource, subresource string) authorization.Attributes {
+		return authorization.AttributesRecord{
+			AttributesRecord: authorizer.AttributesRecord{
+				User:            u,
+				Verb:            verb,
+				Namespace:       namespace,
+				APIGroup:        group,
+				Resource:        resource,
+				Subresource:     subresource,
+				ResourceRequest: true,
+			},
+			Region:  region,
+			Cluster: cluster,
+		}
+	}
+	nonResource := func(u user.Info, path string) authorization.Attributes {
+		return authorization.AttributesRecord{
+			AttributesRecord: authorizer.AttributesRecord{User: u, Verb: "get", Path: path},
+			Region:           "host",
+			Cluster:          "host",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		attributes authorization.Attributes
+		allowed    bool
+	}{
+		{"namespace scope", resource(teamA, "list", "cn-east", "prod-1", "team-a", "", "pods", ""), true},
+		{"namespace scope subresource", resource(teamA, "get", "cn-east", "prod-1", "team-a", "", "pods", "log"), true},
+		{"namespace scope verb not granted", resource(teamA, "delete", "cn-east", "prod-1", "team-a", "", "pods", ""), false},
+		{"other namespace", resource(teamA, "list", "cn-east", "prod-1", "team-b", "", "pods", ""), false},
+		{"other cluster", resource(teamA, "list", "cn-east", "prod-2", "team-a", "", "pods", ""), false},
+		{"cluster scoped resource", resource(teamA, "list", "cn-east", "prod-1", "", "", "nodes", ""), false},
+		{"region scope", resource(ops, "delete", "cn-east", "prod-2", "", "apps", "deployments", ""), true},
+		{"other region", resource(ops, "list", "cn-west", "prod-1", "", "", "pods", ""), false},
+		{"non resource out of global scope", nonResource(ops, "/capis/version"), false},
+		{"global scope", resource(root, "delete", "cn-west", "prod-1", "", "", "nodes", ""), true},
+		{"global scope non resource", nonResource(root, "/capis/version"), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision, reason, err := auth.Authorize(context.TODO(), test.attributes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if allowed := decision == authorizer.DecisionAllow; allowed != test.allowed {
+				t.Errorf("expected allowed %v, got %v, reason: %s", test.allowed, allowed, reason)
+			}
+		})
+	}
+}
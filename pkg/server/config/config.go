@@ -12,7 +12,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 
+	"captain/pkg/bussiness/kube-resources/alpha1/terminal"
 	"captain/pkg/constants"
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/authentication"
+	"captain/pkg/server/authorization"
 	"captain/pkg/simple/client/cache"
 	"captain/pkg/simple/client/k8s"
 	"captain/pkg/simple/client/monitoring/prometheus"
@@ -107,19 +111,27 @@ func defaultConfig() *config {
 
 // Config defines everything needed for captain-server to deal with external services
 type Config struct {
-	KubernetesOptions   *k8s.KubernetesOptions `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
-	RedisOptions        *cache.Options         `json:"redis,omitempty" yaml:"redis,omitempty" mapstructure:"redis"`
-	MultiClusterOptions *multicluster.Options  `json:"multicluster,omitempty" yaml:"multicluster,omitempty" mapstructure:"multicluster"`
-	MonitoringOptions   *prometheus.Options    `json:"monitoring,omitempty" yaml:"monitoring,omitempty" mapstructure:"monitoring"`
+	KubernetesOptions     *k8s.KubernetesOptions  `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
+	RedisOptions          *cache.Options          `json:"redis,omitempty" yaml:"redis,omitempty" mapstructure:"redis"`
+	MultiClusterOptions   *multicluster.Options   `json:"multicluster,omitempty" yaml:"multicluster,omitempty" mapstructure:"multicluster"`
+	MonitoringOptions     *prometheus.Options     `json:"monitoring,omitempty" yaml:"monitoring,omitempty" mapstructure:"monitoring"`
+	AuthenticationOptions *authentication.Options `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	AuthorizationOptions  *authorization.Options  `json:"authorization,omitempty" yaml:"authorization,omitempty" mapstructure:"authorization"`
+	AuditingOptions       *auditing.Options       `json:"auditing,omitempty" yaml:"auditing,omitempty" mapstructure:"auditing"`
+	TerminalOptions       *terminal.Options       `json:"terminal,omitempty" yaml:"terminal,omitempty" mapstructure:"terminal"`
 }
 
 // newConfig creates a default non-empty Config
 func New() *Config {
 	return &Config{
-		KubernetesOptions:   k8s.NewKubernetesOptions(),
-		RedisOptions:        cache.NewRedisOptions(),
-		MultiClusterOptions: multicluster.NewOptions(),
-		MonitoringOptions:   prometheus.NewPrometheusOptions(),
+		KubernetesOptions:     k8s.NewKubernetesOptions(),
+		RedisOptions:          cache.NewRedisOptions(),
+		MultiClusterOptions:   multicluster.NewOptions(),
+		MonitoringOptions:     prometheus.NewPrometheusOptions(),
+		AuthenticationOptions: authentication.NewOptions(),
+		AuthorizationOptions:  authorization.NewOptions(),
+		AuditingOptions:       auditing.NewOptions(),
+		TerminalOptions:       terminal.NewOptions(),
 	}
 }
 
@@ -173,6 +185,14 @@ func (conf *Config) stripEmptyOptions() {
 		conf.MonitoringOptions = nil
 	}
 
+	if conf.AuthenticationOptions != nil && !conf.AuthenticationOptions.Enable {
+		conf.AuthenticationOptions = nil
+	}
+
+	if conf.AuditingOptions != nil && !conf.AuditingOptions.Enable {
+		conf.AuditingOptions = nil
+	}
+
 }
 
 // GetFromConfigMap returns KubeSphere ruuning config by the given ConfigMap.
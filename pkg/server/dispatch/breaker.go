@@ -0,0 +1,103 @@
+package dispatch
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	// breakerClosed lets all requests through
+	breakerClosed breakerState = iota
+	// breakerOpen rejects all requests until open duration passes
+	breakerOpen
+	// breakerHalfOpen lets a single probe through, the result of which closes or reopens the breaker
+	breakerHalfOpen
+)
+
+// circuitBreaker stops requests to a cluster after consecutive transport failures, so that a dead
+// cluster doesn't hold server goroutines until transport times out.
+type circuitBreaker struct {
+	sync.Mutex
+
+	threshold    int
+	openDuration time.Duration
+
+	state    breakerState
+	failures int
+	// openedAt is when breaker opened or the last probe was let through
+	openedAt time.Time
+
+	now func() time.Time
+}
+
+func newCircuitBreaker(threshold int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:    threshold,
+		openDuration: openDuration,
+		now:          time.Now,
+	}
+}
+
+// Allow returns true if request is allowed, otherwise how long to wait before retrying.
+// A probe which never reports its result is given up after open duration, and another
+// probe is let through.
+func (b *circuitBreaker) Allow() (bool, time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.state == breakerClosed {
+		return true, 0
+	}
+
+	if elapsed := b.now().Sub(b.openedAt); elapsed < b.openDuration {
+		return false, b.openDuration - elapsed
+	}
+
+	b.state = breakerHalfOpen
+	b.openedAt = b.now()
+	return true, 0
+}
+
+// Success closes the breaker
+func (b *circuitBreaker) Success() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// Failure opens the breaker if threshold is reached or probe fails
+func (b *circuitBreaker) Failure() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// breakerRoundTripper reports results of requests to breaker, only transport errors count
+// as failures since any response proves the cluster is reachable
+type breakerRoundTripper struct {
+	breaker *circuitBreaker
+	rt      http.RoundTripper
+}
+
+func (b *breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := b.rt.RoundTrip(req)
+	if err != nil {
+		// requests canceled by clients say nothing about the cluster
+		if req.Context().Err() == nil {
+			b.breaker.Failure()
+		}
+		return nil, err
+	}
+	b.breaker.Success()
+	return resp, nil
+}
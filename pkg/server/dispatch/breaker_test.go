@@ -0,0 +1,54 @@
+package dispatch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(3, 10*time.Second)
+	b.now = func() time.Time { return now }
+
+	allow := func(expected bool) {
+		t.Helper()
+		if allowed, _ := b.Allow(); allowed != expected {
+			t.Fatalf("expected allowed %v, got %v", expected, allowed)
+		}
+	}
+
+	// failures below threshold and interrupted by success keep breaker closed
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	allow(true)
+
+	b.Failure()
+	allow(false)
+	if _, retryAfter := b.Allow(); retryAfter != 10*time.Second {
+		t.Fatalf("expected retry after 10s, got %v", retryAfter)
+	}
+
+	// a single probe is let through after open period
+	now = now.Add(10 * time.Second)
+	allow(true)
+	allow(false)
+
+	// failed probe opens breaker again
+	b.Failure()
+	now = now.Add(5 * time.Second)
+	allow(false)
+
+	// probe never reporting is given up after open period
+	now = now.Add(5 * time.Second)
+	allow(true)
+	now = now.Add(10 * time.Second)
+	allow(true)
+
+	// successful probe closes breaker
+	b.Success()
+	allow(true)
+	allow(true)
+}
@@ -2,8 +2,11 @@ package dispatch
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
 	clusterinformer "captain/pkg/client/informers/externalversions/cluster/v1alpha1"
@@ -12,10 +15,11 @@ import (
 	"captain/pkg/simple/client/multicluster"
 	"captain/pkg/utils/clusterclient"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/apimachinery/pkg/util/proxy"
-	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/klog"
 )
 
@@ -30,10 +34,20 @@ type Dispatcher interface {
 
 type clusterDispatch struct {
 	clusterclient.ClusterClients
+
+	options *multicluster.Options
+
+	// circuit breakers of member clusters, keyed by cluster name
+	breakers     map[string]*circuitBreaker
+	breakersLock sync.Mutex
 }
 
 func NewClusterDispatch(clusterInformer clusterinformer.ClusterInformer, options *multicluster.Options) Dispatcher {
-	return &clusterDispatch{ClusterClients: clusterclient.NewClusterClients(clusterInformer, options)}
+	return &clusterDispatch{
+		ClusterClients: clusterclient.NewClusterClients(clusterInformer, options),
+		options:        options,
+		breakers:       make(map[string]*circuitBreaker),
+	}
 }
 
 // Dispatch dispatch requests to designated cluster
@@ -42,17 +56,14 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 
 	if len(info.Cluster) == 0 {
 		klog.Warningf("Request with empty cluster, %v", req.URL)
-		http.Error(w, "Bad request, empty cluster", http.StatusBadRequest)
+		request.WriteError(w, req, errors.NewBadRequest("empty cluster"))
 		return
 	}
 
 	cluster, err := c.Get(info.Region, info.Cluster)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			http.Error(w, fmt.Sprintf("cluster %s not found", info.Cluster), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		// clusters not found are responded with 404
+		request.WriteError(w, req, err)
 		return
 	}
 
@@ -64,17 +75,35 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 		return
 	}
 
-	/*if !c.IsClusterReady(cluster) {
-		http.Error(w, fmt.Sprintf("cluster %s is not ready", cluster.Name), http.StatusBadRequest)
+	if !cluster.Spec.Enable {
+		c.unavailable(w, req, cluster, "ClusterDisabled", "cluster is disabled", 0)
+		return
+	}
+
+	if ready := readyCondition(cluster); ready == nil || ready.Status != corev1.ConditionTrue {
+		reason, message := "ClusterNotReady", "cluster is not ready"
+		if ready != nil {
+			reason, message = ready.Reason, ready.Message
+		}
+		c.unavailable(w, req, cluster, reason, message, 0)
 		return
-	}*/
+	}
 
 	innCluster := c.GetInnerCluster(cluster.Name)
 	if innCluster == nil {
-		http.Error(w, fmt.Sprintf("cluster %s is not ready", cluster.Name), http.StatusBadRequest)
+		c.unavailable(w, req, cluster, "ClusterNotConnected", "connection of cluster is not initialized", 0)
 		return
 	}
 
+	breaker := c.breaker(cluster.Name)
+	if breaker != nil {
+		if allowed, retryAfter := breaker.Allow(); !allowed {
+			c.unavailable(w, req, cluster, "CircuitBreakerOpen", "too many consecutive transport failures, requests to "+
+				"cluster are rejected for a while", retryAfter)
+			return
+		}
+	}
+
 	transport := http.DefaultTransport
 
 	// change request host to actually cluster hosts
@@ -128,13 +157,70 @@ func (c *clusterDispatch) Dispatch(w http.ResponseWriter, req *http.Request, han
 
 		u.Host = innCluster.CaptainURL.Host
 		u.Scheme = innCluster.CaptainURL.Scheme
+
+		// cluster proxy authenticates callers with service account tokens of host cluster, which are
+		// added by transport of the kubeconfig. Credentials of users go in X-Captain-Authorization.
+		if cluster.Spec.Connection.Type == clusterv1alpha1.ConnectionTypeProxy {
+			transport = innCluster.Transport
+			req.Header.Set("X-Captain-Authorization", req.Header.Get("Authorization"))
+			req.Header.Del("Authorization")
+		}
 	}
 
-	httpProxy := proxy.NewUpgradeAwareHandler(&u, transport, false, false, c)
+	// upgrade transport dials connections itself, so upgrade requests are not counted by breaker
+	var rt http.RoundTripper = transport
+	if breaker != nil {
+		rt = &breakerRoundTripper{breaker: breaker, rt: transport}
+	}
+	httpProxy := proxy.NewUpgradeAwareHandler(&u, rt, false, false, c)
 	httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
 	httpProxy.ServeHTTP(w, req)
 }
 
+// breaker returns circuit breaker of cluster, nil if circuit breaker is disabled
+func (c *clusterDispatch) breaker(name string) *circuitBreaker {
+	if c.options.ClusterBreakerThreshold <= 0 {
+		return nil
+	}
+
+	c.breakersLock.Lock()
+	defer c.breakersLock.Unlock()
+	b, ok := c.breakers[name]
+	if !ok {
+		b = newCircuitBreaker(c.options.ClusterBreakerThreshold, c.options.ClusterBreakerOpenPeriod)
+		c.breakers[name] = b
+	}
+	return b
+}
+
+// unavailable responds a 503 status telling why the cluster can't serve requests
+func (c *clusterDispatch) unavailable(w http.ResponseWriter, req *http.Request, cluster *clusterv1alpha1.Cluster, reason, message string, retryAfter time.Duration) {
+	err := errors.NewServiceUnavailable(fmt.Sprintf("cluster %s is unavailable: %s", cluster.Name, message))
+	err.ErrStatus.Details = &metav1.StatusDetails{
+		Name:  cluster.Name,
+		Group: clusterv1alpha1.SchemeGroupVersion.Group,
+		Kind:  clusterv1alpha1.ResourcesPluralCluster,
+		Causes: []metav1.StatusCause{{
+			Type:    metav1.CauseType(reason),
+			Message: message,
+		}},
+	}
+	if retryAfter > 0 {
+		err.ErrStatus.Details.RetryAfterSeconds = int32(math.Ceil(retryAfter.Seconds()))
+	}
+	request.WriteError(w, req, err)
+}
+
+func readyCondition(cluster *clusterv1alpha1.Cluster) *clusterv1alpha1.ClusterCondition {
+	for i := range cluster.Status.Conditions {
+		if cluster.Status.Conditions[i].Type == clusterv1alpha1.ClusterReady {
+			return &cluster.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Error responds errors of proxying requests to member clusters, member clusters are unreachable
 func (c *clusterDispatch) Error(w http.ResponseWriter, req *http.Request, err error) {
-	responsewriters.InternalError(w, req, err)
+	request.WriteStatus(w, req, http.StatusServiceUnavailable, err)
 }
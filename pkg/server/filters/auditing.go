@@ -0,0 +1,71 @@
+package filters
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+
+	"k8s.io/apiserver/pkg/endpoints/responsewriter"
+	"k8s.io/klog"
+
+	"captain/pkg/server/auditing"
+)
+
+// WithAuditing records an audit event for every request, requests dispatched to member
+// clusters and requests failed to be authenticated included
+func WithAuditing(handler http.Handler, auditor *auditing.Auditor) http.Handler {
+	if auditor == nil {
+		klog.V(4).Infof("Auditing is disabled")
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		event := auditor.NewEvent(req)
+
+		d := &auditResponseWriter{ResponseWriter: w}
+		defer func() {
+			auditor.Process(event, d.statusCode())
+		}()
+
+		req = req.WithContext(auditing.WithEvent(req.Context(), event))
+		handler.ServeHTTP(responsewriter.WrapForHTTP1Or2(d), req)
+	})
+}
+
+// auditResponseWriter records status code of response
+type auditResponseWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (a *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
+}
+
+func (a *auditResponseWriter) WriteHeader(code int) {
+	if a.code == 0 {
+		a.code = code
+	}
+	a.ResponseWriter.WriteHeader(code)
+}
+
+func (a *auditResponseWriter) Write(b []byte) (int, error) {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	return a.ResponseWriter.Write(b)
+}
+
+// Hijack is called by upgrade requests, like exec and port-forward
+func (a *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if a.code == 0 {
+		a.code = http.StatusSwitchingProtocols
+	}
+	return a.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (a *auditResponseWriter) statusCode() int {
+	if a.code == 0 {
+		return http.StatusOK
+	}
+	return a.code
+}
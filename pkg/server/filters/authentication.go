@@ -0,0 +1,40 @@
+package filters
+
+import (
+	"errors"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/klog"
+
+	"captain/pkg/server/auditing"
+	"captain/pkg/server/request"
+)
+
+// WithAuthentication authenticates requests with the given authenticator and attaches the
+// resolved user to request context. Requests failed to be authenticated are rejected.
+func WithAuthentication(handler http.Handler, auth authenticator.Request) http.Handler {
+	if auth == nil {
+		klog.Warningf("Authentication is disabled")
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp, ok, err := auth.AuthenticateRequest(req)
+		if err != nil || !ok {
+			if err != nil {
+				klog.V(4).Infof("Unable to authenticate the request from %s, %v", req.RemoteAddr, err)
+			} else {
+				err = errors.New("no credential provided")
+			}
+			request.WriteError(w, req, apierrors.NewUnauthorized(err.Error()))
+			return
+		}
+
+		if event, ok := auditing.EventFrom(req.Context()); ok {
+			event.SetUser(resp.User)
+		}
+		req = req.WithContext(request.WithUser(req.Context(), resp.User))
+		handler.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,68 @@
+package filters
+
+import (
+	"errors"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/klog"
+
+	"captain/pkg/server/authorization"
+	"captain/pkg/server/request"
+)
+
+// WithAuthorization passes requests allowed by authorizer to handler, requests to member
+// clusters are authorized here before being dispatched. Requests across clusters are passed
+// with the authorizer, handlers authorize each cluster.
+func WithAuthorization(handler http.Handler, auth authorization.Authorizer, hostRegion, hostCluster string) http.Handler {
+	if auth == nil {
+		klog.Warningf("Authorization is disabled")
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		info, ok := request.RequestInfoFrom(ctx)
+		if !ok {
+			request.WriteError(w, req, errors.New("no RequestInfo found in the context"))
+			return
+		}
+
+		if authorization.AuthorizedPerCluster(info) {
+			handler.ServeHTTP(w, req.WithContext(authorization.WithAuthorizer(ctx, auth)))
+			return
+		}
+
+		u, ok := request.UserFrom(ctx)
+		if !ok {
+			u = &user.DefaultInfo{Name: user.Anonymous, Groups: []string{user.AllUnauthenticated}}
+		}
+
+		attributes := authorization.NewAttributes(u, info, hostRegion, hostCluster)
+		decision, reason, err := auth.Authorize(ctx, attributes)
+		if err != nil {
+			request.WriteError(w, req, err)
+			return
+		}
+		if decision != authorizer.DecisionAllow {
+			klog.V(4).Infof("Forbidden %q, reason: %q", req.RequestURI, reason)
+			forbidden(w, req, attributes, reason)
+			return
+		}
+
+		handler.ServeHTTP(w, req.WithContext(authorization.WithAuthorizer(ctx, auth)))
+	})
+}
+
+func forbidden(w http.ResponseWriter, req *http.Request, attributes authorization.Attributes, reason string) {
+	var err error
+	if attributes.IsResourceRequest() {
+		gr := schema.GroupResource{Group: attributes.GetAPIGroup(), Resource: attributes.GetResource()}
+		err = apierrors.NewForbidden(gr, attributes.GetName(), errors.New(reason))
+	} else {
+		err = apierrors.NewForbidden(schema.GroupResource{}, "", errors.New(reason))
+	}
+	request.WriteError(w, req, err)
+}
@@ -1,10 +1,9 @@
 package filters
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
-	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/klog"
 
 	"captain/pkg/server/dispatch"
@@ -22,11 +21,11 @@ func WithMultipleClusterDispatcher(handler http.Handler, dispatch dispatch.Dispa
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		info, ok := request.RequestInfoFrom(req.Context())
 		if !ok {
-			responsewriters.InternalError(w, req, fmt.Errorf(""))
+			request.WriteError(w, req, errors.New("no RequestInfo found in the context"))
 			return
 		}
 
-		if info.Cluster == "" || len(info.APIPrefix) == 0 || (info.APIPrefix == runtime.ApiRoot && !proxyAPIGroup(info.APIGroup)) {
+		if info.Cluster == "" || len(info.APIPrefix) == 0 || (info.APIPrefix == runtime.ApiRoot && !proxyCaptainRequest(info)) {
 			handler.ServeHTTP(w, req)
 		} else {
 			dispatch.Dispatch(w, req, handler)
@@ -38,7 +37,14 @@ var proxyAPIGroups = map[string]bool{
 	"monitoring.captain.io": true,
 }
 
+// watchAPIGroups are groups watch requests of which are served by captain-server of member clusters,
+// member clusters are watched by their own informers
+var watchAPIGroups = map[string]bool{
+	"resources.captain.io": true,
+}
+
 // .../capis/monitoring.captain.io/v1alpha1/...
-func proxyAPIGroup(group string) bool {
-	return proxyAPIGroups[group]
+// .../capis/resources.captain.io/alpha1/watch/...
+func proxyCaptainRequest(info *request.RequestInfo) bool {
+	return proxyAPIGroups[info.APIGroup] || (info.Verb == "watch" && watchAPIGroups[info.APIGroup])
 }
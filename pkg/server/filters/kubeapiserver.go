@@ -7,7 +7,6 @@ import (
 	"net/url"
 
 	"k8s.io/apimachinery/pkg/util/proxy"
-	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
 
@@ -28,8 +27,8 @@ func WithKubeAPIServer(handler http.Handler, config *rest.Config, failed proxy.E
 		info, ok := request.RequestInfoFrom(req.Context())
 		if !ok {
 			err := errors.New("Unable to retrieve request info from request")
-			klog.Error(err)
-			responsewriters.InternalError(w, req, err)
+			request.WriteError(w, req, err)
+			return
 		}
 
 		if info.IsKubernetesRequest {
@@ -7,8 +7,6 @@ import (
 	"net/http"
 	"strings"
 
-	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
-
 	"captain/pkg/server/request"
 )
 
@@ -47,7 +45,7 @@ func WithRequestInfo(handler http.Handler, resolver request.RequestInfoResolver)
 		ctx := req.Context()
 		info, err := resolver.NewRequestInfo(req)
 		if err != nil {
-			responsewriters.InternalError(w, req, fmt.Errorf("failed to crate RequestInfo: %v", err))
+			request.WriteError(w, req, fmt.Errorf("failed to crate RequestInfo: %v", err))
 			return
 		}
 
@@ -0,0 +1,27 @@
+package request
+
+import (
+	"net/http"
+
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+)
+
+// WriteStatus writes err as api.Status with code, region, cluster, namespace, resource and name
+// of the request are filled in details unless err tells them
+func WriteStatus(w http.ResponseWriter, req *http.Request, code int, err error) {
+	if code >= http.StatusInternalServerError {
+		klog.Errorf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	status := api.NewStatus(code, err)
+	if info, ok := RequestInfoFrom(req.Context()); ok {
+		status.WithResource(info.Region, info.Cluster, info.Namespace, info.Resource, info.Name)
+	}
+	api.WriteStatus(w, status)
+}
+
+// WriteError writes err as api.Status with code of err, see api.ErrorCode
+func WriteError(w http.ResponseWriter, req *http.Request, err error) {
+	WriteStatus(w, req, api.ErrorCode(err), err)
+}
@@ -0,0 +1,97 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"captain/pkg/api"
+)
+
+func TestWriteError(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("cluster c1 is unavailable")
+	unavailable.ErrStatus.Details = &metav1.StatusDetails{Name: "c1", Kind: "clusters", RetryAfterSeconds: 30}
+
+	tests := []struct {
+		err        error
+		code       int
+		reason     metav1.StatusReason
+		details    api.StatusDetails
+		retryAfter string
+	}{
+		{
+			err:     apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web"),
+			code:    http.StatusNotFound,
+			reason:  metav1.StatusReasonNotFound,
+			details: api.StatusDetails{Name: "web", Kind: "pods", Region: "wx", Cluster: "c1", Namespace: "default", Resource: "pods"},
+		},
+		{
+			err:        unavailable,
+			code:       http.StatusServiceUnavailable,
+			reason:     metav1.StatusReasonServiceUnavailable,
+			details:    api.StatusDetails{Name: "c1", Kind: "clusters", RetryAfterSeconds: 30, Region: "wx", Cluster: "c1", Namespace: "default", Resource: "pods"},
+			retryAfter: "30",
+		},
+		{
+			err:     restful.NewError(http.StatusMethodNotAllowed, "405: Method Not Allowed"),
+			code:    http.StatusMethodNotAllowed,
+			reason:  metav1.StatusReasonMethodNotAllowed,
+			details: api.StatusDetails{Name: "web", Region: "wx", Cluster: "c1", Namespace: "default", Resource: "pods"},
+		},
+		{
+			err:     errors.New("something is wrong"),
+			code:    http.StatusInternalServerError,
+			reason:  metav1.StatusReasonInternalError,
+			details: api.StatusDetails{Name: "web", Region: "wx", Cluster: "c1", Namespace: "default", Resource: "pods"},
+		},
+	}
+
+	info := &RequestInfo{
+		RequestInfo: &k8srequest.RequestInfo{Namespace: "default", Resource: "pods", Name: "web"},
+		Region:      "wx",
+		Cluster:     "c1",
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/default/pods/web", nil)
+		req = req.WithContext(WithRequestInfo(req.Context(), info))
+		recorder := httptest.NewRecorder()
+		WriteError(recorder, req, test.err)
+
+		if recorder.Code != test.code || recorder.Header().Get("Content-Type") != restful.MIME_JSON {
+			t.Errorf("%v: expected %d of json, got %d of %s", test.err, test.code, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+		if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != test.retryAfter {
+			t.Errorf("%v: expected Retry-After %q, got %q", test.err, test.retryAfter, retryAfter)
+		}
+
+		// responses are decoded as Status of Kubernetes
+		var status metav1.Status
+		if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+			t.Errorf("%v: %v", test.err, err)
+			continue
+		}
+		if status.Kind != "Status" || status.Status != metav1.StatusFailure || status.Code != int32(test.code) ||
+			status.Reason != test.reason || status.Message != test.err.Error() {
+			t.Errorf("%v: unexpected status %+v", test.err, status)
+		}
+
+		var captainStatus api.Status
+		if err := json.Unmarshal(recorder.Body.Bytes(), &captainStatus); err != nil || captainStatus.Details == nil {
+			t.Errorf("%v: unexpected details %s", test.err, recorder.Body.String())
+			continue
+		}
+		if details := *captainStatus.Details; details.Name != test.details.Name || details.Kind != test.details.Kind ||
+			details.RetryAfterSeconds != test.details.RetryAfterSeconds || details.Region != test.details.Region ||
+			details.Cluster != test.details.Cluster || details.Namespace != test.details.Namespace || details.Resource != test.details.Resource {
+			t.Errorf("%v: expected details %+v, got %+v", test.err, test.details, details)
+		}
+	}
+}
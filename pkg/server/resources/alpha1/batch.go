@@ -0,0 +1,71 @@
+package alpha1
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	captainruntime "captain/pkg/server/runtime"
+	"captain/pkg/unify/response"
+)
+
+// handleCronJobAction triggers, suspends or resumes a cron job, the job triggered is created with 201
+func (h *Handler) handleCronJobAction(request *restful.Request, resp *restful.Response) {
+	action := request.PathParameter("action")
+	result, err := h.resourceProviderAlpha1.CronJobAction(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("name"), action)
+	if err != nil {
+		api.HandleError(resp, request, err)
+		return
+	}
+	if action == resource.ActionTrigger {
+		resp.WriteHeaderAndEntity(http.StatusCreated, result)
+		return
+	}
+	resp.WriteEntity(result)
+}
+
+// handleCronJobHistory retrieves runs of a cron job with paths of logs of their pods
+func (h *Handler) handleCronJobHistory(request *restful.Request, resp *restful.Response) {
+	region, cluster, namespace := request.PathParameter("region"), request.PathParameter("cluster"), request.PathParameter("namespace")
+	result, err := h.resourceProviderAlpha1.CronJobHistory(request.Request.Context(), region, cluster, namespace, request.PathParameter("name"))
+	if err != nil {
+		api.HandleError(resp, request, err)
+		return
+	}
+	setLogPaths(result, apiPath(region, cluster)+"/namespaces/"+namespace)
+	resp.WriteEntity(result)
+}
+
+// handleJobAction reruns a job, the job cloned is created with 201
+func (h *Handler) handleJobAction(request *restful.Request, resp *restful.Response) {
+	result, err := h.resourceProviderAlpha1.JobAction(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("name"), request.PathParameter("action"))
+	if err != nil {
+		api.HandleError(resp, request, err)
+		return
+	}
+	resp.WriteHeaderAndEntity(http.StatusCreated, result)
+}
+
+// apiPath returns root path of the API of cluster, the host cluster if region is empty
+func apiPath(region, cluster string) string {
+	path := captainruntime.ApiRootPath + "/" + GroupVersion.String()
+	if len(region) == 0 {
+		return path
+	}
+	return "/regions/" + region + "/clusters/" + cluster + path
+}
+
+// setLogPaths sets paths of logs of jobs and pods in history, prefix is path of the namespace
+func setLogPaths(history *response.JobRunHistory, prefix string) {
+	for i := range history.Items {
+		run := &history.Items[i]
+		run.Logs = prefix + "/workloads/jobs/" + run.Name + "/logs"
+		for j := range run.Pods {
+			run.Pods[j].Logs = prefix + "/pods/" + run.Pods[j].Name + "/logs"
+		}
+	}
+}
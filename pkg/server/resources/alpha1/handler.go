@@ -1,42 +1,90 @@
 package alpha1
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/klog"
+
 	"captain/pkg/api"
 	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	"captain/pkg/bussiness/kube-resources/alpha1/terminal"
+	"captain/pkg/server/authorization"
+	captainrequest "captain/pkg/server/request"
+	"captain/pkg/simple/client/multicluster"
+	"captain/pkg/unify/export"
 	"captain/pkg/unify/query"
+)
 
-	"github.com/emicklei/go-restful"
-	"k8s.io/klog"
+const (
+	queryClusterSelector   = query.ParameterClusterSelector
+	queryParameterSearch   = query.ParameterSearch
+	queryParameterKinds    = query.ParameterKinds
+	queryParameterPage     = query.ParameterPage
+	queryParameterPageSize = query.ParameterPageSize
+	queryParameterLimit    = query.ParameterLimit
+	queryParameterContinue = query.ParameterContinue
 )
 
 type Handler struct {
 	resourceProviderAlpha1 *resource.ResourceProcessor
+
+	// upgrader upgrades terminal requests to websocket
+	upgrader *websocket.Upgrader
+
+	// hostRegion and hostCluster name the host cluster in records of terminal sessions
+	hostRegion  string
+	hostCluster string
 }
 
-func New(kubeResProcessor *resource.ResourceProcessor) *Handler {
+func New(kubeResProcessor *resource.ResourceProcessor, options *multicluster.Options) *Handler {
 	return &Handler{
 		resourceProviderAlpha1: kubeResProcessor,
+		upgrader:               terminal.NewUpgrader(kubeResProcessor.TerminalOptions()),
+		hostRegion:             options.HostRegionName,
+		hostCluster:            options.HostClusterName,
 	}
 }
 
 // handleListResources retrieves resources
 func (h *Handler) handleListResources(request *restful.Request, response *restful.Response) {
-	query := query.ParseQueryParameter(request)
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	format, err := export.Negotiate(request.Request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	query = export.Query(request.Request, format, query)
 	region := request.PathParameter("region")
 	cluster := request.PathParameter("cluster")
 	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
+	if err := h.resourceProviderAlpha1.ValidateSortBy(resourceType, query.SortBy); err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
 
 	result, err := h.resourceProviderAlpha1.List(region, cluster, resourceType, namespace, query)
 	if err == nil {
-		response.WriteEntity(result)
+		h.writeList(request, response, resourceType, format, result)
 		return
 	}
 
 	klog.Error(err)
 	if err != resource.ErrResourceNotSupported {
 		klog.Error(err, resourceType)
-		api.HandleInternalError(response, request, err)
+		api.HandleError(response, request, err)
 		return
 	} else {
 		api.HandleNotFound(response, request, err)
@@ -60,13 +108,210 @@ func (h *Handler) handleListResources(request *restful.Request, response *restfu
 func (h *Handler) handleGetResource(request *restful.Request, response *restful.Response) {
 	region := request.PathParameter("region")
 	cluster := request.PathParameter("cluster")
-	resource := request.PathParameter("resources")
+	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
 	name := request.PathParameter("name")
-	result, err := h.resourceProviderAlpha1.Get(region, cluster, resource, namespace, name)
+	result, err := h.resourceProviderAlpha1.Get(region, cluster, resourceType, namespace, name)
+	if err == resource.ErrResourceNotSupported {
+		api.HandleNotFound(response, request, err)
+		return
+	} else if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// handleFleetListResources retrieves resources in all clusters matching cluster selector
+func (h *Handler) handleFleetListResources(request *restful.Request, response *restful.Response) {
+	query, err := query.ParseQueryParameter(request)
 	if err != nil {
-		response.WriteEntity(result)
+		api.HandleError(response, request, err)
+		return
+	}
+	format, err := export.Negotiate(request.Request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	query = export.Query(request.Request, format, query)
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+
+	selector, err := labels.Parse(request.QueryParameter(queryClusterSelector))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.resourceProviderAlpha1.ValidateSortBy(resourceType, query.SortBy); err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+
+	result, err := h.resourceProviderAlpha1.FleetList(selector, resourceType, namespace, query, h.listAllowed(request.Request))
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	h.writeFleetList(request, response, resourceType, format, result)
+}
+
+// handleAggregateResources counts resources by values of groupBy fields
+func (h *Handler) handleAggregateResources(request *restful.Request, response *restful.Response) {
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	groupBy, sumRequests, err := parseAggregateParameter(request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	region := request.PathParameter("region")
+	cluster := request.PathParameter("cluster")
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+
+	result, err := h.resourceProviderAlpha1.Aggregate(region, cluster, resourceType, namespace, query, groupBy, sumRequests)
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// handleFleetAggregateResources counts resources in all clusters matching cluster selector by values of groupBy fields
+func (h *Handler) handleFleetAggregateResources(request *restful.Request, response *restful.Response) {
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	groupBy, sumRequests, err := parseAggregateParameter(request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+
+	selector, err := labels.Parse(request.QueryParameter(queryClusterSelector))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	result, err := h.resourceProviderAlpha1.FleetAggregate(selector, resourceType, namespace, query, groupBy, sumRequests, h.listAllowed(request.Request))
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
 		return
 	}
 	response.WriteEntity(result)
 }
+
+func parseAggregateParameter(request *restful.Request) ([]query.Field, bool, error) {
+	var groupBy []query.Field
+	for _, field := range strings.Split(request.QueryParameter(query.ParameterGroupBy), ",") {
+		if field = strings.TrimSpace(field); len(field) != 0 {
+			groupBy = append(groupBy, query.Field(field))
+		}
+	}
+	if len(groupBy) == 0 {
+		return nil, false, fmt.Errorf("%s is required", query.ParameterGroupBy)
+	}
+
+	sumRequests := false
+	if value := request.QueryParameter(query.ParameterSumRequests); len(value) != 0 {
+		var err error
+		if sumRequests, err = strconv.ParseBool(value); err != nil {
+			return nil, false, fmt.Errorf("invalid %s %s", query.ParameterSumRequests, value)
+		}
+	}
+	return groupBy, sumRequests, nil
+}
+
+// handleSearch searches resources of kinds matching search text across clusters
+func (h *Handler) handleSearch(request *restful.Request, response *restful.Response) {
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	text := strings.TrimSpace(request.QueryParameter(queryParameterSearch))
+	if len(text) == 0 {
+		api.HandleBadRequest(response, request, fmt.Errorf("%s is required", queryParameterSearch))
+		return
+	}
+	// hits are ranked by score rather than sorted by keys, so there is no cursor to resume from
+	if query.CursorMode() {
+		api.HandleBadRequest(response, request, fmt.Errorf("%s and %s are not supported by search, use %s and %s instead",
+			queryParameterLimit, queryParameterContinue, queryParameterPage, queryParameterPageSize))
+		return
+	}
+	var kinds []string
+	for _, kind := range strings.Split(request.QueryParameter(queryParameterKinds), ",") {
+		if kind = strings.TrimSpace(kind); len(kind) != 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	selector, err := labels.Parse(request.QueryParameter(queryClusterSelector))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	response.WriteEntity(h.resourceProviderAlpha1.Search(text, kinds, selector, query, h.listAllowed(request.Request)))
+}
+
+// listAllowed returns whether user of req is allowed to list resources in namespace of cluster,
+// everything is allowed if authorization is disabled
+func (h *Handler) listAllowed(req *http.Request) func(region, cluster, resource, namespace string) bool {
+	auth, ok := authorization.AuthorizerFrom(req.Context())
+	if !ok {
+		return func(string, string, string, string) bool { return true }
+	}
+	u, ok := captainrequest.UserFrom(req.Context())
+	if !ok {
+		u = &user.DefaultInfo{Name: user.Anonymous, Groups: []string{user.AllUnauthenticated}}
+	}
+	return func(region, cluster, resource, namespace string) bool {
+		if len(cluster) == 0 {
+			region, cluster = h.hostRegion, h.hostCluster
+		}
+		decision, _, err := auth.Authorize(req.Context(), &authorization.AttributesRecord{
+			AttributesRecord: authorizer.AttributesRecord{
+				User:            u,
+				Verb:            "list",
+				Namespace:       namespace,
+				APIGroup:        GroupVersion.Group,
+				APIVersion:      GroupVersion.Version,
+				Resource:        resource,
+				ResourceRequest: true,
+			},
+			Region:  region,
+			Cluster: cluster,
+		})
+		return err == nil && decision == authorizer.DecisionAllow
+	}
+}
+
+// handleClusterCacheStatus reports sync status of informers of member clusters
+func (h *Handler) handleClusterCacheStatus(request *restful.Request, response *restful.Response) {
+	response.WriteEntity(h.resourceProviderAlpha1.ClusterCacheStatus())
+}
@@ -146,7 +146,8 @@ func TestHandleListResources(t *testing.T) {
 		t.Fatalf(err.Error())
 	}
 
-	handler := New(resource.NewResourceProcessor(factory, nil, config.New()))
+	c := config.New()
+	handler := New(resource.NewResourceProcessor(factory, nil, nil, c), c.MultiClusterOptions)
 
 	for _, test := range tests {
 		res, err := handler.resourceProviderAlpha1.List("", "", test.resource, test.namespace, test.query)
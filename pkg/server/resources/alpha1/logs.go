@@ -0,0 +1,124 @@
+package alpha1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/pod"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+)
+
+const (
+	parameterContainer    = "container"
+	parameterFollow       = "follow"
+	parameterTailLines    = "tailLines"
+	parameterSinceSeconds = "sinceSeconds"
+	parameterPrevious     = "previous"
+	parameterTimestamps   = "timestamps"
+
+	mimeTextPlain = "text/plain"
+)
+
+// handlePodLogs streams logs of a pod, logs of all containers are merged with lines prefixed by
+// container names if container is not specified
+func (h *Handler) handlePodLogs(request *restful.Request, response *restful.Response) {
+	options, err := parseLogOptions(request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	streams, err := h.resourceProviderAlpha1.PodLogs(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("name"), options)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	writeLogs(response, streams)
+}
+
+// handleWorkloadLogs streams logs of all pods owned by a workload, lines are prefixed by pod/container
+func (h *Handler) handleWorkloadLogs(request *restful.Request, response *restful.Response) {
+	options, err := parseLogOptions(request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	streams, err := h.resourceProviderAlpha1.WorkloadLogs(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("workload"), request.PathParameter("name"), options)
+	if err == resource.ErrResourceNotSupported {
+		api.HandleNotFound(response, request, err)
+		return
+	} else if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	writeLogs(response, streams)
+}
+
+func parseLogOptions(request *restful.Request) (*v1.PodLogOptions, error) {
+	options := &v1.PodLogOptions{Container: request.QueryParameter(parameterContainer)}
+	for name, value := range map[string]*bool{
+		parameterFollow:     &options.Follow,
+		parameterPrevious:   &options.Previous,
+		parameterTimestamps: &options.Timestamps,
+	} {
+		if s := request.QueryParameter(name); len(s) != 0 {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s", name, s)
+			}
+			*value = b
+		}
+	}
+	if s := request.QueryParameter(parameterTailLines); len(s) != 0 {
+		lines, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || lines < 0 {
+			return nil, fmt.Errorf("invalid %s %s", parameterTailLines, s)
+		}
+		options.TailLines = &lines
+	}
+	if s := request.QueryParameter(parameterSinceSeconds); len(s) != 0 {
+		seconds, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid %s %s", parameterSinceSeconds, s)
+		}
+		options.SinceSeconds = &seconds
+	}
+	return options, nil
+}
+
+func writeLogs(response *restful.Response, streams *pod.LogStreams) {
+	response.Header().Set("Content-Type", mimeTextPlain+"; charset=utf-8")
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+	response.WriteHeader(http.StatusOK)
+
+	var w io.Writer = response
+	if flusher, ok := response.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+		w = &flushWriter{w: response, flusher: flusher}
+	}
+	if err := streams.CopyTo(w); err != nil {
+		klog.V(4).Infof("write logs failed, %v", err)
+	}
+}
+
+// flushWriter flushes each write, so that followed logs reach clients as they come
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flusher.Flush()
+	return n, err
+}
@@ -0,0 +1,166 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/node"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	captainruntime "captain/pkg/server/runtime"
+)
+
+const (
+	parameterGracePeriodSeconds = "gracePeriodSeconds"
+	parameterForce              = "force"
+	parameterDeleteEmptyDirData = "deleteEmptyDirData"
+	parameterWatch              = "watch"
+)
+
+// handleNodeAction cordons, uncordons or drains a node, drains run in background and are accepted
+// with the initial progress
+func (h *Handler) handleNodeAction(request *restful.Request, response *restful.Response) {
+	action := request.PathParameter("action")
+	var options node.DrainOptions
+	if action == resource.ActionDrain {
+		var err error
+		if options, err = parseDrainOptions(request); err != nil {
+			api.HandleBadRequest(response, request, err)
+			return
+		}
+	}
+
+	result, err := h.resourceProviderAlpha1.NodeAction(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("name"), action, options)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	if action == resource.ActionDrain {
+		response.WriteHeaderAndEntity(http.StatusAccepted, result)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+func parseDrainOptions(request *restful.Request) (node.DrainOptions, error) {
+	var options node.DrainOptions
+	if s := request.QueryParameter(parameterGracePeriodSeconds); len(s) != 0 {
+		seconds, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || seconds < 0 {
+			return options, fmt.Errorf("invalid %s %s", parameterGracePeriodSeconds, s)
+		}
+		options.GracePeriodSeconds = &seconds
+	}
+	if s := request.QueryParameter(parameterTimeoutSeconds); len(s) != 0 {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds <= 0 {
+			return options, fmt.Errorf("invalid %s %s", parameterTimeoutSeconds, s)
+		}
+		options.Timeout = time.Duration(seconds) * time.Second
+	}
+	for parameter, value := range map[string]*bool{parameterForce: &options.Force, parameterDeleteEmptyDirData: &options.DeleteEmptyDirData} {
+		if s := request.QueryParameter(parameter); len(s) != 0 {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return options, fmt.Errorf("invalid %s %s", parameter, s)
+			}
+			*value = b
+		}
+	}
+	return options, nil
+}
+
+// handleNodeDrain retrieves progress of the latest drain of a node, progress is streamed until the drain
+// finishes if watch is true, as newline delimited json by default or server-sent events if client
+// accepts text/event-stream
+func (h *Handler) handleNodeDrain(request *restful.Request, response *restful.Response) {
+	region, cluster, name := request.PathParameter("region"), request.PathParameter("cluster"), request.PathParameter("name")
+	if watch, _ := strconv.ParseBool(request.QueryParameter(parameterWatch)); !watch {
+		result, err := h.resourceProviderAlpha1.NodeDrain(region, cluster, name)
+		if err != nil {
+			api.HandleError(response, request, err)
+			return
+		}
+		response.WriteEntity(result)
+		return
+	}
+
+	status, changed, err := h.resourceProviderAlpha1.WatchNodeDrain(region, cluster, name)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		api.HandleInternalError(response, request, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	sse := strings.Contains(request.HeaderParameter("Accept"), captainruntime.MimeEventStream)
+	if sse {
+		response.Header().Set("Content-Type", captainruntime.MimeEventStream)
+		response.Header().Set("Cache-Control", "no-cache")
+	} else {
+		response.Header().Set("Content-Type", restful.MIME_JSON)
+	}
+	response.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		data, err := json.Marshal(status)
+		if err != nil {
+			klog.Errorf("encode drain of node %s failed, %v", name, err)
+			return
+		}
+		if sse {
+			_, err = fmt.Fprintf(response, "event: %s\ndata: %s\n\n", status.Phase, data)
+		} else {
+			_, err = fmt.Fprintf(response, "%s\n", data)
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+		if status.Phase != node.DrainRunning {
+			return
+		}
+
+	wait:
+		for {
+			select {
+			case <-request.Request.Context().Done():
+				return
+			case <-heartbeat.C:
+				if sse {
+					if _, err := fmt.Fprint(response, ": heartbeat\n\n"); err != nil {
+						return
+					}
+					flusher.Flush()
+				}
+			case <-changed:
+				break wait
+			}
+		}
+		if status, changed, err = h.resourceProviderAlpha1.WatchNodeDrain(region, cluster, name); err != nil {
+			return
+		}
+	}
+}
+
+// handleCancelNodeDrain cancels the running drain of a node, the node is left unschedulable
+func (h *Handler) handleCancelNodeDrain(request *restful.Request, response *restful.Response) {
+	result, err := h.resourceProviderAlpha1.CancelNodeDrain(request.PathParameter("region"), request.PathParameter("cluster"), request.PathParameter("name"))
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteEntity(result)
+}
@@ -6,7 +6,11 @@ import (
 	"captain/pkg/informers"
 	"captain/pkg/server/config"
 	"captain/pkg/server/runtime"
+	"captain/pkg/simple/client/k8s"
+	"captain/pkg/unify/export"
 	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+	"captain/pkg/utils/clusterclient"
 	"net/http"
 
 	"github.com/emicklei/go-restful"
@@ -21,6 +25,13 @@ const (
 	GroupName            = "resources.captain.io"
 	ok                   = "success"
 	tagClusteredResource = "Resources in cluster scope"
+	tagFleetResource     = "Resources across clusters"
+	tagSearch            = "Search"
+	tagLogs              = "Logs"
+	tagTerminal          = "Terminal"
+	tagRollout           = "Rollout"
+	tagNodeMaintenance   = "Node maintenance"
+	tagJob               = "Job"
 )
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "alpha1"}
@@ -29,38 +40,54 @@ func Resource(resource string) schema.GroupResource {
 	return GroupVersion.WithResource(resource).GroupResource()
 }
 
-func AddToContainer(c *restful.Container, factory informers.CapInformerFactory, cache cache.Cache, config *config.Config) error {
+func AddToContainer(c *restful.Container, factory informers.CapInformerFactory, client k8s.Client, cache cache.Cache, config *config.Config) error {
 	webservice := runtime.NewWebService(GroupVersion)
-	handler := New(resource.NewResourceProcessor(factory, cache, config))
+	sortByDoc := "sort key, name, creationTimestamp, or a JSONPath such as .spec.replicas whose values are compared as numbers, quantities, timestamps or strings, " +
+		"missing values are the smallest. Keys specific to resources are " + resource.SortKeysDescription() + ", other keys are rejected"
+	handler := New(resource.NewResourceProcessor(factory, client, cache, config), config.MultiClusterOptions)
 
 	webservice.Route(webservice.GET("/namespaces/{namespace}/resources/{resources}").
 		To(handler.handleListResources).
+		Produces(export.Produces...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
 		Param(webservice.PathParameter("namespace", "namespace")).
 		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
 		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 	webservice.Route(webservice.GET("resources/{resources}").
 		To(handler.handleListResources).
+		Produces(export.Produces...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("core level resources").
-		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
 		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
 		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 	webservice.Route(webservice.GET("/namespaces/{namespace}/resources/{resources}/name/{name}").
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
 		Param(webservice.PathParameter("namespace", "namespace of resources")).
 		Param(webservice.PathParameter("name", "name of resources")).
 		Returns(http.StatusOK, ok, api.ListResult{}))
@@ -68,9 +95,258 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 		To(handler.handleGetResource).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
-		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
 		Param(webservice.PathParameter("name", "name of resources")).
 		Returns(http.StatusOK, ok, api.ListResult{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/resources/{resources}/aggregate").
+		To(handler.handleAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Count namespace scope resources by values of groupBy fields").
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
+		Param(webservice.PathParameter("namespace", "namespace")).
+		Param(webservice.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.AggregateResult{}))
+	webservice.Route(webservice.GET("resources/{resources}/aggregate").
+		To(handler.handleAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Count resources by values of groupBy fields").
+		Param(webservice.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
+		Param(webservice.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.AggregateResult{}))
+	webservice.Route(webservice.GET("/watch/namespaces/{namespace}/resources/{resources}").
+		To(handler.handleWatchResources).
+		Produces(restful.MIME_JSON, runtime.MimeEventStream).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Watch namespace scope resources, events are streamed as newline delimited json, or server-sent events if text/event-stream is accepted. "+
+			"Watch resources of member clusters with prefix /regions/{region}/clusters/{cluster}").
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
+		Param(webservice.PathParameter("namespace", "namespace")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "label selector").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "timeout of watch, default 1800").Required(false)).
+		Returns(http.StatusOK, ok, watchEvent{}))
+	webservice.Route(webservice.GET("/watch/resources/{resources}").
+		To(handler.handleWatchResources).
+		Produces(restful.MIME_JSON, runtime.MimeEventStream).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Watch resources, events are streamed as newline delimited json, or server-sent events if text/event-stream is accepted. "+
+			"Watch resources of member clusters with prefix /regions/{region}/clusters/{cluster}").
+		Param(webservice.PathParameter("resources", "resource type, e.g: pods,namespaces,nodes.")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "label selector").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "timeout of watch, default 1800").Required(false)).
+		Returns(http.StatusOK, ok, watchEvent{}))
+	webservice.Route(webservice.GET("/fleet/resources/{resources}").
+		To(handler.handleFleetListResources).
+		Produces(export.Produces...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagFleetResource}).
+		Doc("Resources in all clusters matching cluster selector, items are annotated with region and cluster they come from").
+		Param(webservice.PathParameter("resources", "resource type, e.g: pods,deployments,namespaces,nodes.")).
+		Param(webservice.QueryParameter(query.ParameterClusterSelector, "label selector of clusters, e.g. cluster.captain.io/region=wx, all clusters if empty").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
+		Param(webservice.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
+		Returns(http.StatusOK, ok, response.FleetListResult{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/fleet/resources/{resources}").
+		To(handler.handleFleetListResources).
+		Produces(export.Produces...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagFleetResource}).
+		Doc("Namespace scope resources in all clusters matching cluster selector, items are annotated with region and cluster they come from").
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
+		Param(webservice.PathParameter("namespace", "namespace")).
+		Param(webservice.QueryParameter(query.ParameterClusterSelector, "label selector of clusters, e.g. cluster.captain.io/region=wx, all clusters if empty").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
+		Param(webservice.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
+		Returns(http.StatusOK, ok, response.FleetListResult{}))
+	webservice.Route(webservice.GET("/fleet/resources/{resources}/aggregate").
+		To(handler.handleFleetAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagFleetResource}).
+		Doc("Count resources in all clusters matching cluster selector by values of groupBy fields").
+		Param(webservice.PathParameter("resources", "resource resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
+		Param(webservice.QueryParameter(query.ParameterClusterSelector, "label selector of clusters, e.g. cluster.captain.io/region=wx, all clusters if empty").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are region, cluster, filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.FleetAggregateResult{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/fleet/resources/{resources}/aggregate").
+		To(handler.handleFleetAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagFleetResource}).
+		Doc("Count namespace scope resources in all clusters matching cluster selector by values of groupBy fields").
+		Param(webservice.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
+		Param(webservice.PathParameter("namespace", "namespace")).
+		Param(webservice.QueryParameter(query.ParameterClusterSelector, "label selector of clusters, e.g. cluster.captain.io/region=wx, all clusters if empty").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are region, cluster, filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.FleetAggregateResult{}))
+	webservice.Route(webservice.GET("/search").
+		To(handler.handleSearch).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagSearch}).
+		Doc("Search resources of all kinds concurrently, in all clusters matching cluster selector if multi-cluster is enabled. "+
+			"Hits are ranked by matches of names, labels and annotations.").
+		Param(webservice.QueryParameter(query.ParameterSearch, "search terms separated by spaces, all terms must match. key=value matches labels or annotations, other terms match names, labels and annotations, * and ? are wildcards, e.g. payment-* team=checkout").Required(true)).
+		Param(webservice.QueryParameter(query.ParameterKinds, "comma separated resource types to search, e.g. pods,deployments, all resources with dedicated providers if empty").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterClusterSelector, "label selector of clusters, e.g. cluster.captain.io/region=wx, all clusters if empty").Required(false)).
+		Param(webservice.QueryParameter(query.FieldNamespace, "namespace used to do filtering").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Returns(http.StatusOK, ok, response.SearchResult{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/pods/{name}/logs").
+		To(handler.handlePodLogs).
+		Produces(mimeTextPlain, restful.MIME_JSON).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagLogs}).
+		Doc("Stream logs of a pod, logs of all containers are merged with lines prefixed by [container] if container is not specified").
+		Param(webservice.PathParameter("namespace", "namespace of the pod")).
+		Param(webservice.PathParameter("name", "name of the pod")).
+		Param(webservice.QueryParameter(parameterContainer, "container to stream logs of, all containers if empty").Required(false)).
+		Param(webservice.QueryParameter(parameterFollow, "follow logs until the client disconnects").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(parameterTailLines, "number of lines from the end of logs of each container").Required(false)).
+		Param(webservice.QueryParameter(parameterSinceSeconds, "stream logs of the last seconds").Required(false)).
+		Param(webservice.QueryParameter(parameterPrevious, "stream logs of previous terminated containers").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(parameterTimestamps, "prefix lines with RFC3339 timestamps").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, ""))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/workloads/{workload}/{name}/logs").
+		To(handler.handleWorkloadLogs).
+		Produces(mimeTextPlain, restful.MIME_JSON).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagLogs}).
+		Doc("Stream merged logs of all pods owned by a workload, lines are prefixed by [pod/container]").
+		Param(webservice.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice.PathParameter("workload", "workload type, one of deployments, statefulsets, daemonsets, replicasets and jobs")).
+		Param(webservice.PathParameter("name", "name of the workload")).
+		Param(webservice.QueryParameter(parameterContainer, "container to stream logs of, all containers if empty").Required(false)).
+		Param(webservice.QueryParameter(parameterFollow, "follow logs until the client disconnects").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(parameterTailLines, "number of lines from the end of logs of each container").Required(false)).
+		Param(webservice.QueryParameter(parameterSinceSeconds, "stream logs of the last seconds").Required(false)).
+		Param(webservice.QueryParameter(parameterPrevious, "stream logs of previous terminated containers").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(parameterTimestamps, "prefix lines with RFC3339 timestamps").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, ""))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/pods/{name}/exec").
+		To(handler.handlePodTerminal).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagTerminal}).
+		Doc("Open a terminal in a container of a pod over websocket, messages are json of op stdin, resize, stdout and toast, e.g. {\"op\":\"stdin\",\"data\":\"ls\\r\"} and {\"op\":\"resize\",\"cols\":120,\"rows\":40}").
+		Param(webservice.PathParameter("namespace", "namespace of the pod")).
+		Param(webservice.PathParameter("name", "name of the pod")).
+		Param(webservice.QueryParameter(parameterContainer, "container to open the terminal in, the default container of the pod if empty").Required(false)).
+		Param(webservice.QueryParameter(parameterShell, "shell to run, e.g. /bin/bash, bash or sh whichever is available if empty").Required(false)).
+		Param(webservice.QueryParameter(parameterCols, "initial columns of the terminal").Required(false).DefaultValue("80")).
+		Param(webservice.QueryParameter(parameterRows, "initial rows of the terminal").Required(false).DefaultValue("24")).
+		Returns(http.StatusSwitchingProtocols, ok, nil))
+	webservice.Route(webservice.GET("/nodes/{name}/exec").
+		To(handler.handleNodeTerminal).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagTerminal}).
+		Doc("Open a shell of a node over websocket, the shell runs in a privileged pod on the node which is deleted when the session ends").
+		Param(webservice.PathParameter("name", "name of the node")).
+		Param(webservice.QueryParameter(parameterCols, "initial columns of the terminal").Required(false).DefaultValue("80")).
+		Param(webservice.QueryParameter(parameterRows, "initial rows of the terminal").Required(false).DefaultValue("24")).
+		Returns(http.StatusSwitchingProtocols, ok, nil))
+	webservice.Route(webservice.POST("/namespaces/{namespace}/workloads/{workload}/{name}/actions/{action}").
+		To(handler.handleRolloutWorkload).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagRollout}).
+		Doc("Roll out a workload, the updated workload is returned. Deployments support all actions, statefulsets support restart, scale and rollback, daemonsets support restart and rollback").
+		Param(webservice.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice.PathParameter("workload", "workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice.PathParameter("name", "name of the workload")).
+		Param(webservice.PathParameter("action", "one of restart, scale, pause, resume and rollback")).
+		Param(webservice.QueryParameter(parameterReplicas, "replicas to scale to, required by scale").Required(false)).
+		Param(webservice.QueryParameter(parameterRevision, "revision to roll back to, the previous revision if it's 0 or empty").Required(false)).
+		Returns(http.StatusOK, ok, nil))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/workloads/{workload}/{name}/revisions").
+		To(handler.handleRevisionHistory).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagRollout}).
+		Doc("Revision history of a workload built from ReplicaSets or ControllerRevisions, each revision has the unified diff of pod template from the previous revision").
+		Param(webservice.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice.PathParameter("workload", "workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice.PathParameter("name", "name of the workload")).
+		Returns(http.StatusOK, ok, response.RevisionHistory{}))
+	webservice.Route(webservice.POST("/resources/nodes/{name}/actions/{action}").
+		To(handler.handleNodeAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Cordon, uncordon or drain a node. Drain cordons the node and evicts pods on it through the Eviction API in background, DaemonSet and mirror pods are skipped, "+
+			"evictions disallowed by PodDisruptionBudgets are retried until timeout, the initial progress is returned with 202").
+		Param(webservice.PathParameter("name", "name of the node")).
+		Param(webservice.PathParameter("action", "one of cordon, uncordon and drain")).
+		Param(webservice.QueryParameter(parameterGracePeriodSeconds, "grace period of evicted pods, those of pods are used if it's not set").Required(false)).
+		Param(webservice.QueryParameter(parameterTimeoutSeconds, "timeout of drain in seconds").Required(false).DefaultValue("600")).
+		Param(webservice.QueryParameter(parameterForce, "evict pods not managed by controllers too").Required(false).DefaultValue("false")).
+		Param(webservice.QueryParameter(parameterDeleteEmptyDirData, "evict pods using emptyDir volumes too, data of the volumes is deleted").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, nil).
+		Returns(http.StatusAccepted, ok, response.NodeDrain{}))
+	webservice.Route(webservice.GET("/resources/nodes/{name}/drain").
+		To(handler.handleNodeDrain).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Progress of the latest drain of a node, it's kept in memory of the server running the drain").
+		Param(webservice.PathParameter("name", "name of the node")).
+		Param(webservice.QueryParameter(parameterWatch, "stream progress until the drain finishes, as newline delimited json or server-sent events if text/event-stream is accepted").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, response.NodeDrain{}))
+	webservice.Route(webservice.DELETE("/resources/nodes/{name}/drain").
+		To(handler.handleCancelNodeDrain).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Cancel the running drain of a node, pods evicted are not restored and the node is left unschedulable").
+		Param(webservice.PathParameter("name", "name of the node")).
+		Returns(http.StatusOK, ok, response.NodeDrain{}))
+	webservice.Route(webservice.POST("/namespaces/{namespace}/cronjobs/{name}/actions/{action}").
+		To(handler.handleCronJobAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Trigger, suspend or resume a cron job of batch/v1, or batch/v1beta1 if batch/v1 is not served. Trigger creates a job from the job template as kubectl create job --from does, "+
+			"the job is returned with 201, suspend and resume return the updated cron job").
+		Param(webservice.PathParameter("namespace", "namespace of the cron job")).
+		Param(webservice.PathParameter("name", "name of the cron job")).
+		Param(webservice.PathParameter("action", "one of trigger, suspend and resume")).
+		Returns(http.StatusOK, ok, nil).
+		Returns(http.StatusCreated, ok, nil))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/cronjobs/{name}/runs").
+		To(handler.handleCronJobHistory).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Runs of a cron job, which are jobs controlled by it, newest first, with outcomes, durations and paths of logs of their pods").
+		Param(webservice.PathParameter("namespace", "namespace of the cron job")).
+		Param(webservice.PathParameter("name", "name of the cron job")).
+		Returns(http.StatusOK, ok, response.JobRunHistory{}))
+	webservice.Route(webservice.POST("/namespaces/{namespace}/jobs/{name}/actions/{action}").
+		To(handler.handleJobAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Rerun a job, it's cloned with a new name and a new selector, the job created is returned with 201").
+		Param(webservice.PathParameter("namespace", "namespace of the job")).
+		Param(webservice.PathParameter("name", "name of the job")).
+		Param(webservice.PathParameter("action", "rerun")).
+		Returns(http.StatusCreated, ok, nil))
+	webservice.Route(webservice.GET("/clustercaches").
+		To(handler.handleClusterCacheStatus).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Sync status of informers of member clusters, informers are started on first request to the cluster").
+		Returns(http.StatusOK, ok, []clusterclient.ClusterCacheStatus{}))
 
 	c.Add(webservice)
 
@@ -81,30 +357,44 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 
 	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}").
 		To(handler.handleListResources).
+		Produces(export.Produces...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
 		Param(webservice2.PathParameter("namespace", "namespace")).
 		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
 		Param(webservice2.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
 		Param(webservice2.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice2.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
 		Param(webservice2.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice2.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice2.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice2.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 	webservice2.Route(webservice2.GET(urlPrefix+"/resources/{resources}").
 		To(handler.handleListResources).
+		Produces(export.Produces...).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
 		Doc("core level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
 		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
 		Param(webservice2.QueryParameter(query.ParameterPage, "page, which is started with 1 not 0, default value is 1.").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
 		Param(webservice2.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
+		Param(webservice2.QueryParameter(query.ParameterLimit, "max number of items in cursor mode, page and pageSize are ignored if limit or continue is set").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterContinue, "continue token returned by the previous page in cursor mode").Required(false)).
 		Param(webservice2.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
-		Param(webservice2.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice2.QueryParameter(query.ParameterOrderBy, sortByDoc)).
+		Param(webservice2.QueryParameter(query.ParameterFields, "comma separated JSONPath fields items are projected to, e.g. metadata.name,status.phase, they are columns if Accept is application/json;as=Table").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set, columns of csv are the same as table output").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}/name/{name}").
 		To(handler.handleGetResource).
@@ -112,7 +402,7 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services.")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
 		Param(webservice2.PathParameter("namespace", "namespace of resources")).
 		Param(webservice2.PathParameter("name", "name of resources")).
 		Returns(http.StatusOK, ok, api.ListResult{}))
@@ -122,10 +412,179 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 		Doc("Cluster level resources").
 		Param(webservice2.PathParameter("region", "region id of cluster")).
 		Param(webservice2.PathParameter("cluster", "name of cluster")).
-		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes.")).
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
 		Param(webservice2.PathParameter("name", "name of resources")).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/resources/{resources}/aggregate").
+		To(handler.handleAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Count namespace scope resources by values of groupBy fields").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("resources", "namespace scope resource type, e.g: pods,jobs,configmaps,services, any other resource served by the cluster in the form of resource[.version][.group], e.g: crontabs.stable.example.com.")).
+		Param(webservice2.PathParameter("namespace", "namespace")).
+		Param(webservice2.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice2.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.AggregateResult{}))
+	webservice2.Route(webservice2.GET(urlPrefix+"/resources/{resources}/aggregate").
+		To(handler.handleAggregateResources).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Count resources by values of groupBy fields").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("resources", "core scope resource type, e.g: namespaces,nodes, any other resource served by the cluster in the form of resource[.version][.group], e.g: customresourcedefinitions.apiextensions.k8s.io.")).
+		Param(webservice2.QueryParameter(query.ParameterGroupBy, "comma separated fields to group by, which are filter fields of the resource such as status, namespace, label:<key>, annotation:<key> or paths of fields such as spec.nodeName").Required(true)).
+		Param(webservice2.QueryParameter(query.ParameterSumRequests, "sum cpu and memory requests of pods of grouped items, supported by pods, deployments, statefulsets, daemonsets and jobs").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(query.ParameterName, "name used to do filtering").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFieldSelector, "field selector, e.g. status.phase=Running,spec.nodeName!=node-1").Required(false)).
+		Param(webservice2.QueryParameter(query.ParameterFilter, "filter expression of and, or, not and parentheses, e.g. (status=Running or status=Pending) and label=app=web").Required(false)).
+		Returns(http.StatusOK, ok, response.AggregateResult{}))
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/pods/{name}/logs").
+		To(handler.handlePodLogs).
+		Produces(mimeTextPlain, restful.MIME_JSON).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagLogs}).
+		Doc("Stream logs of a pod, logs of all containers are merged with lines prefixed by [container] if container is not specified").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the pod")).
+		Param(webservice2.PathParameter("name", "name of the pod")).
+		Param(webservice2.QueryParameter(parameterContainer, "container to stream logs of, all containers if empty").Required(false)).
+		Param(webservice2.QueryParameter(parameterFollow, "follow logs until the client disconnects").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(parameterTailLines, "number of lines from the end of logs of each container").Required(false)).
+		Param(webservice2.QueryParameter(parameterSinceSeconds, "stream logs of the last seconds").Required(false)).
+		Param(webservice2.QueryParameter(parameterPrevious, "stream logs of previous terminated containers").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(parameterTimestamps, "prefix lines with RFC3339 timestamps").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, ""))
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/workloads/{workload}/{name}/logs").
+		To(handler.handleWorkloadLogs).
+		Produces(mimeTextPlain, restful.MIME_JSON).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagLogs}).
+		Doc("Stream merged logs of all pods owned by a workload, lines are prefixed by [pod/container]").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice2.PathParameter("workload", "workload type, one of deployments, statefulsets, daemonsets, replicasets and jobs")).
+		Param(webservice2.PathParameter("name", "name of the workload")).
+		Param(webservice2.QueryParameter(parameterContainer, "container to stream logs of, all containers if empty").Required(false)).
+		Param(webservice2.QueryParameter(parameterFollow, "follow logs until the client disconnects").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(parameterTailLines, "number of lines from the end of logs of each container").Required(false)).
+		Param(webservice2.QueryParameter(parameterSinceSeconds, "stream logs of the last seconds").Required(false)).
+		Param(webservice2.QueryParameter(parameterPrevious, "stream logs of previous terminated containers").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(parameterTimestamps, "prefix lines with RFC3339 timestamps").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, ""))
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/pods/{name}/exec").
+		To(handler.handlePodTerminal).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagTerminal}).
+		Doc("Open a terminal in a container of a pod over websocket, messages are json of op stdin, resize, stdout and toast, e.g. {\"op\":\"stdin\",\"data\":\"ls\\r\"} and {\"op\":\"resize\",\"cols\":120,\"rows\":40}").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the pod")).
+		Param(webservice2.PathParameter("name", "name of the pod")).
+		Param(webservice2.QueryParameter(parameterContainer, "container to open the terminal in, the default container of the pod if empty").Required(false)).
+		Param(webservice2.QueryParameter(parameterShell, "shell to run, e.g. /bin/bash, bash or sh whichever is available if empty").Required(false)).
+		Param(webservice2.QueryParameter(parameterCols, "initial columns of the terminal").Required(false).DefaultValue("80")).
+		Param(webservice2.QueryParameter(parameterRows, "initial rows of the terminal").Required(false).DefaultValue("24")).
+		Returns(http.StatusSwitchingProtocols, ok, nil))
+	webservice2.Route(webservice2.GET(urlPrefix+"/nodes/{name}/exec").
+		To(handler.handleNodeTerminal).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagTerminal}).
+		Doc("Open a shell of a node over websocket, the shell runs in a privileged pod on the node which is deleted when the session ends").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("name", "name of the node")).
+		Param(webservice2.QueryParameter(parameterCols, "initial columns of the terminal").Required(false).DefaultValue("80")).
+		Param(webservice2.QueryParameter(parameterRows, "initial rows of the terminal").Required(false).DefaultValue("24")).
+		Returns(http.StatusSwitchingProtocols, ok, nil))
+	webservice2.Route(webservice2.POST(urlPrefix+"/namespaces/{namespace}/workloads/{workload}/{name}/actions/{action}").
+		To(handler.handleRolloutWorkload).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagRollout}).
+		Doc("Roll out a workload, the updated workload is returned. Deployments support all actions, statefulsets support restart, scale and rollback, daemonsets support restart and rollback").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice2.PathParameter("workload", "workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice2.PathParameter("name", "name of the workload")).
+		Param(webservice2.PathParameter("action", "one of restart, scale, pause, resume and rollback")).
+		Param(webservice2.QueryParameter(parameterReplicas, "replicas to scale to, required by scale").Required(false)).
+		Param(webservice2.QueryParameter(parameterRevision, "revision to roll back to, the previous revision if it's 0 or empty").Required(false)).
+		Returns(http.StatusOK, ok, nil))
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/workloads/{workload}/{name}/revisions").
+		To(handler.handleRevisionHistory).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagRollout}).
+		Doc("Revision history of a workload built from ReplicaSets or ControllerRevisions, each revision has the unified diff of pod template from the previous revision").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the workload")).
+		Param(webservice2.PathParameter("workload", "workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice2.PathParameter("name", "name of the workload")).
+		Returns(http.StatusOK, ok, response.RevisionHistory{}))
+	webservice2.Route(webservice2.POST(urlPrefix+"/resources/nodes/{name}/actions/{action}").
+		To(handler.handleNodeAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Cordon, uncordon or drain a node. Drain cordons the node and evicts pods on it through the Eviction API in background, DaemonSet and mirror pods are skipped, "+
+			"evictions disallowed by PodDisruptionBudgets are retried until timeout, the initial progress is returned with 202").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("name", "name of the node")).
+		Param(webservice2.PathParameter("action", "one of cordon, uncordon and drain")).
+		Param(webservice2.QueryParameter(parameterGracePeriodSeconds, "grace period of evicted pods, those of pods are used if it's not set").Required(false)).
+		Param(webservice2.QueryParameter(parameterTimeoutSeconds, "timeout of drain in seconds").Required(false).DefaultValue("600")).
+		Param(webservice2.QueryParameter(parameterForce, "evict pods not managed by controllers too").Required(false).DefaultValue("false")).
+		Param(webservice2.QueryParameter(parameterDeleteEmptyDirData, "evict pods using emptyDir volumes too, data of the volumes is deleted").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, nil).
+		Returns(http.StatusAccepted, ok, response.NodeDrain{}))
+	webservice2.Route(webservice2.GET(urlPrefix+"/resources/nodes/{name}/drain").
+		To(handler.handleNodeDrain).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Progress of the latest drain of a node, it's kept in memory of the server running the drain").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("name", "name of the node")).
+		Param(webservice2.QueryParameter(parameterWatch, "stream progress until the drain finishes, as newline delimited json or server-sent events if text/event-stream is accepted").Required(false).DefaultValue("false")).
+		Returns(http.StatusOK, ok, response.NodeDrain{}))
+	webservice2.Route(webservice2.DELETE(urlPrefix+"/resources/nodes/{name}/drain").
+		To(handler.handleCancelNodeDrain).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNodeMaintenance}).
+		Doc("Cancel the running drain of a node, pods evicted are not restored and the node is left unschedulable").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("name", "name of the node")).
+		Returns(http.StatusOK, ok, response.NodeDrain{}))
+	webservice2.Route(webservice2.POST(urlPrefix+"/namespaces/{namespace}/cronjobs/{name}/actions/{action}").
+		To(handler.handleCronJobAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Trigger, suspend or resume a cron job of batch/v1, or batch/v1beta1 if batch/v1 is not served. Trigger creates a job from the job template as kubectl create job --from does, "+
+			"the job is returned with 201, suspend and resume return the updated cron job").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the cron job")).
+		Param(webservice2.PathParameter("name", "name of the cron job")).
+		Param(webservice2.PathParameter("action", "one of trigger, suspend and resume")).
+		Returns(http.StatusOK, ok, nil).
+		Returns(http.StatusCreated, ok, nil))
+	webservice2.Route(webservice2.GET(urlPrefix+"/namespaces/{namespace}/cronjobs/{name}/runs").
+		To(handler.handleCronJobHistory).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Runs of a cron job, which are jobs controlled by it, newest first, with outcomes, durations and paths of logs of their pods").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the cron job")).
+		Param(webservice2.PathParameter("name", "name of the cron job")).
+		Returns(http.StatusOK, ok, response.JobRunHistory{}))
+	webservice2.Route(webservice2.POST(urlPrefix+"/namespaces/{namespace}/jobs/{name}/actions/{action}").
+		To(handler.handleJobAction).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagJob}).
+		Doc("Rerun a job, it's cloned with a new name and a new selector, the job created is returned with 201").
+		Param(webservice2.PathParameter("region", "region id of cluster")).
+		Param(webservice2.PathParameter("cluster", "name of cluster")).
+		Param(webservice2.PathParameter("namespace", "namespace of the job")).
+		Param(webservice2.PathParameter("name", "name of the job")).
+		Param(webservice2.PathParameter("action", "rerun")).
+		Returns(http.StatusCreated, ok, nil))
 	c.Add(webservice2)
 
 	return nil
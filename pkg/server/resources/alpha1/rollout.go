@@ -0,0 +1,64 @@
+package alpha1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+)
+
+const (
+	parameterReplicas = "replicas"
+	parameterRevision = "revision"
+)
+
+// handleRolloutWorkload runs action of restart, scale, pause, resume or rollback on a workload
+func (h *Handler) handleRolloutWorkload(request *restful.Request, response *restful.Response) {
+	action := resource.RolloutAction{Name: request.PathParameter("action")}
+	switch action.Name {
+	case resource.ActionScale:
+		replicas, err := strconv.ParseInt(request.QueryParameter(parameterReplicas), 10, 32)
+		if err != nil || replicas < 0 {
+			api.HandleBadRequest(response, request, fmt.Errorf("invalid %s %q", parameterReplicas, request.QueryParameter(parameterReplicas)))
+			return
+		}
+		action.Replicas = int32(replicas)
+	case resource.ActionRollback:
+		if s := request.QueryParameter(parameterRevision); len(s) != 0 {
+			revision, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || revision < 0 {
+				api.HandleBadRequest(response, request, fmt.Errorf("invalid %s %s", parameterRevision, s))
+				return
+			}
+			action.Revision = revision
+		}
+	}
+
+	result, err := h.resourceProviderAlpha1.Rollout(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("workload"), request.PathParameter("name"), action)
+	if err == resource.ErrResourceNotSupported {
+		api.HandleNotFound(response, request, err)
+		return
+	} else if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// handleRevisionHistory retrieves revisions of a workload with diffs of pod templates
+func (h *Handler) handleRevisionHistory(request *restful.Request, response *restful.Response) {
+	result, err := h.resourceProviderAlpha1.RevisionHistory(request.Request.Context(), request.PathParameter("region"), request.PathParameter("cluster"),
+		request.PathParameter("namespace"), request.PathParameter("workload"), request.PathParameter("name"))
+	if err == resource.ErrResourceNotSupported {
+		api.HandleNotFound(response, request, err)
+		return
+	} else if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	response.WriteEntity(result)
+}
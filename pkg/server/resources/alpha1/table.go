@@ -0,0 +1,98 @@
+package alpha1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+	kuberesources "captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/server/runtime"
+	"captain/pkg/unify/export"
+	"captain/pkg/unify/query"
+	"captain/pkg/unify/response"
+)
+
+// writeList writes result exported in format, or rendered as table if requested by
+// Accept: application/json;as=Table, otherwise items of result are projected to fields if any
+func (h *Handler) writeList(request *restful.Request, resp *restful.Response, resourceType string, format export.Format, result *response.ListResult) {
+	fields := kuberesources.ParseFields(request.QueryParameter(query.ParameterFields))
+	if format != export.FormatJSON {
+		h.exportList(request, resp, resourceType, format, fields, result, h.resourceProviderAlpha1.TableColumns(resourceType, fields), nil)
+		return
+	}
+	if runtime.AcceptsTable(request.Request) {
+		table, err := kuberesources.RenderTable(result, h.resourceProviderAlpha1.TableColumns(resourceType, fields))
+		if err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+		resp.WriteHeaderAndJson(http.StatusOK, table, runtime.ContentTypeTable)
+		return
+	}
+
+	if len(fields) != 0 {
+		if err := kuberesources.ProjectFields(result, fields); err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+	}
+	resp.WriteEntity(result)
+}
+
+// writeFleetList is writeList of fleet requests, tables and CSV have region and cluster columns
+func (h *Handler) writeFleetList(request *restful.Request, resp *restful.Response, resourceType string, format export.Format, result *response.FleetListResult) {
+	fields := kuberesources.ParseFields(request.QueryParameter(query.ParameterFields))
+	if format != export.FormatJSON {
+		var warnings []string
+		for _, clusterError := range result.Errors {
+			warnings = append(warnings, fmt.Sprintf("cluster %s/%s: %s", clusterError.Region, clusterError.Cluster, clusterError.Error))
+		}
+		h.exportList(request, resp, resourceType, format, fields, &result.ListResult, h.resourceProviderAlpha1.FleetTableColumns(resourceType, fields), warnings)
+		return
+	}
+	if runtime.AcceptsTable(request.Request) {
+		table, err := kuberesources.RenderTable(&result.ListResult, h.resourceProviderAlpha1.FleetTableColumns(resourceType, fields))
+		if err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+		resp.WriteHeaderAndJson(http.StatusOK, &response.FleetTableResult{TableResult: *table, Errors: result.Errors}, runtime.ContentTypeTable)
+		return
+	}
+
+	if len(fields) != 0 {
+		if err := kuberesources.ProjectFields(&result.ListResult, fields); err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+	}
+	resp.WriteEntity(result)
+}
+
+// exportList writes items of result in format, columns of CSV are columns of table output, items of
+// other formats are projected to fields if any
+func (h *Handler) exportList(request *restful.Request, resp *restful.Response, resourceType string, format export.Format, fields []string,
+	result *response.ListResult, columns []kuberesources.TableColumn, warnings []string) {
+	list := &export.List{Name: resourceType, Continue: result.Continue, ResourceVersion: result.ResourceVersion, Warnings: warnings}
+	if format == export.FormatCSV {
+		printer, err := kuberesources.NewColumnPrinter(columns)
+		if err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+		list.Columns = printer
+	} else if len(fields) != 0 {
+		if err := kuberesources.ProjectFields(result, fields); err != nil {
+			api.HandleBadRequest(resp, request, err)
+			return
+		}
+	}
+	list.Items = result.Items
+
+	if err := export.Write(resp.ResponseWriter, format, list); err != nil {
+		klog.Errorf("export %s as %s: %v", resourceType, format, err)
+	}
+}
@@ -0,0 +1,119 @@
+package alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/terminal"
+	"captain/pkg/server/request"
+)
+
+const (
+	parameterShell = "shell"
+	parameterCols  = "cols"
+	parameterRows  = "rows"
+)
+
+// handlePodTerminal opens a tty in container of pod over websocket, see terminal.Message for the protocol
+func (h *Handler) handlePodTerminal(req *restful.Request, resp *restful.Response) {
+	width, height, err := parseTerminalSize(req)
+	if err != nil {
+		api.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	t, err := h.resourceProviderAlpha1.PodTerminal(req.Request.Context(), req.PathParameter("region"), req.PathParameter("cluster"),
+		req.PathParameter("namespace"), req.PathParameter("name"), req.QueryParameter(parameterContainer), req.QueryParameter(parameterShell))
+	if err != nil {
+		api.HandleError(resp, req, err)
+		return
+	}
+	h.runTerminal(req, resp, t, width, height)
+}
+
+// handleNodeTerminal opens a shell of node over websocket, the shell runs in a privileged pod on the node
+func (h *Handler) handleNodeTerminal(req *restful.Request, resp *restful.Response) {
+	width, height, err := parseTerminalSize(req)
+	if err != nil {
+		api.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	t, err := h.resourceProviderAlpha1.NodeTerminal(req.Request.Context(), req.PathParameter("region"), req.PathParameter("cluster"),
+		req.PathParameter("name"))
+	if err != nil {
+		api.HandleError(resp, req, err)
+		return
+	}
+	h.runTerminal(req, resp, t, width, height)
+}
+
+// runTerminal upgrades request to websocket and runs terminal until it exits or the session is closed
+func (h *Handler) runTerminal(req *restful.Request, resp *restful.Response, t *terminal.Terminal, width, height uint16) {
+	region, cluster := req.PathParameter("region"), req.PathParameter("cluster")
+	if len(cluster) == 0 {
+		region, cluster = h.hostRegion, h.hostCluster
+	}
+	if len(region) == 0 || len(cluster) == 0 {
+		api.HandleInternalError(resp, req, fmt.Errorf("region and cluster of terminal are unknown, host region and cluster must be configured"))
+		return
+	}
+	username := "anonymous"
+	if u, ok := request.UserFrom(req.Request.Context()); ok {
+		username = u.GetName()
+	}
+	name := fmt.Sprintf("%s/%s/%s", region, cluster, t.Name())
+
+	// sessions are recorded for auditing unless RecordDir is set to empty, they are not opened if recording fails
+	options := h.resourceProviderAlpha1.TerminalOptions()
+	var recorder *terminal.Recorder
+	if len(options.RecordDir) != 0 {
+		var err error
+		recorder, err = terminal.CreateRecorder(options.RecordDir, username+"_"+strings.ReplaceAll(name, "/", "_"),
+			fmt.Sprintf("%s@%s", username, name), width, height)
+		if err != nil {
+			api.HandleInternalError(resp, req, fmt.Errorf("record terminal session failed, %v", err))
+			return
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(resp.ResponseWriter, req.Request, terminal.ResponseHeader(req.Request))
+	if err != nil {
+		// upgrader has responded the error
+		klog.V(4).Infof("upgrade terminal request failed, %v", err)
+		if recorder != nil {
+			recorder.Close()
+		}
+		return
+	}
+
+	klog.Infof("user %s opens terminal of %s", username, name)
+	session := terminal.NewSession(conn, recorder, width, height, options)
+	if err := t.Run(session); err != nil {
+		klog.V(4).Infof("terminal of %s exits, %v", name, err)
+		session.Toast("%v", err)
+		session.Close(websocket.CloseInternalServerErr, err.Error())
+		return
+	}
+	session.Close(websocket.CloseNormalClosure, "session ended")
+}
+
+func parseTerminalSize(req *restful.Request) (uint16, uint16, error) {
+	size := map[string]uint16{parameterCols: terminal.DefaultWidth, parameterRows: terminal.DefaultHeight}
+	for name := range size {
+		if s := req.QueryParameter(name); len(s) != 0 {
+			n, err := strconv.ParseUint(s, 10, 16)
+			if err != nil || n == 0 {
+				return 0, 0, fmt.Errorf("invalid %s %s", name, s)
+			}
+			size[name] = uint16(n)
+		}
+	}
+	return size[parameterCols], size[parameterRows], nil
+}
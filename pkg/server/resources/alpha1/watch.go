@@ -0,0 +1,123 @@
+package alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog"
+
+	"captain/pkg/api"
+	"captain/pkg/bussiness/kube-resources/alpha1/resource"
+	captainruntime "captain/pkg/server/runtime"
+	"captain/pkg/unify/query"
+)
+
+const (
+	parameterTimeoutSeconds = query.ParameterTimeoutSeconds
+
+	defaultWatchTimeout = 30 * time.Minute
+	// heartbeatPeriod keeps idle event streams from being closed by proxies in between
+	heartbeatPeriod = 30 * time.Second
+)
+
+type watchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object runtime.Object  `json:"object"`
+}
+
+// handleWatchResources streams changes of resources, events are newline delimited json by default,
+// or server-sent events if client accepts text/event-stream
+func (h *Handler) handleWatchResources(request *restful.Request, response *restful.Response) {
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	resourceType := request.PathParameter("resources")
+	namespace := request.PathParameter("namespace")
+
+	timeout := defaultWatchTimeout
+	if value := request.QueryParameter(parameterTimeoutSeconds); len(value) != 0 {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			api.HandleBadRequest(response, request, fmt.Errorf("invalid %s %s", parameterTimeoutSeconds, value))
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		api.HandleInternalError(response, request, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	watcher, err := h.resourceProviderAlpha1.Watch(resourceType, namespace, query)
+	if err != nil {
+		klog.Error(err, resourceType)
+		if err == resource.ErrResourceNotSupported {
+			api.HandleNotFound(response, request, err)
+			return
+		}
+		api.HandleError(response, request, err)
+		return
+	}
+	defer watcher.Stop()
+
+	sse := strings.Contains(request.HeaderParameter("Accept"), captainruntime.MimeEventStream)
+	if sse {
+		response.Header().Set("Content-Type", captainruntime.MimeEventStream)
+		response.Header().Set("Cache-Control", "no-cache")
+	} else {
+		response.Header().Set("Content-Type", restful.MIME_JSON)
+	}
+	response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(heartbeatPeriod)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-request.Request.Context().Done():
+			return
+		case <-timer.C:
+			return
+		case <-heartbeat.C:
+			if sse {
+				// lines starting with colon are comments ignored by clients
+				if _, err := fmt.Fprint(response, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(watchEvent{Type: event.Type, Object: event.Object})
+			if err != nil {
+				klog.Errorf("encode watch event of %s failed, %v", resourceType, err)
+				continue
+			}
+			if sse {
+				_, err = fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.Type, data)
+			} else {
+				_, err = fmt.Fprintf(response, "%s\n", data)
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package v1alpha1
 
 import (
+	goerrors "errors"
+
 	"captain/apis/cluster/v1alpha1"
 	"captain/pkg/api"
 	"captain/pkg/bussiness/captain-resources/v1alpha1/resource"
+	"captain/pkg/bussiness/kube-resources/alpha1"
+	"captain/pkg/unify/export"
 	"captain/pkg/unify/query"
 
 	"github.com/emicklei/go-restful"
@@ -24,12 +28,38 @@ func New(kubeResProcessor *resource.ResourceProcessor) *Handler {
 
 // handleListResources retrieves resources
 func (h *Handler) handleListResources(request *restful.Request, response *restful.Response) {
-	query := query.ParseQueryParameter(request)
+	query, err := query.ParseQueryParameter(request)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+	format, err := export.Negotiate(request.Request)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	query = export.Query(request.Request, format, query)
 	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
 
 	result, err := h.resourceProvider.List(resourceType, namespace, query)
-	handleResponse(request, response, result, err)
+	if err != nil || format == export.FormatJSON {
+		handleResponse(request, response, result, err)
+		return
+	}
+
+	list := &export.List{Name: resourceType, Items: result.Items, Continue: result.Continue, ResourceVersion: result.ResourceVersion}
+	if format == export.FormatCSV {
+		printer, err := alpha1.NewColumnPrinter(h.resourceProvider.TableColumns(resourceType))
+		if err != nil {
+			api.HandleInternalError(response, request, err)
+			return
+		}
+		list.Columns = printer
+	}
+	if err := export.Write(response.ResponseWriter, format, list); err != nil {
+		klog.Errorf("export %s as %s: %v", resourceType, format, err)
+	}
 }
 
 func (h *Handler) handleGetResource(request *restful.Request, response *restful.Response) {
@@ -92,11 +122,13 @@ func handleResponse(req *restful.Request, resp *restful.Response, obj interface{
 
 	if err != nil {
 		klog.Error(err)
-		if errors.IsNotFound(err) {
+		var status errors.APIStatus
+		if err == resource.ErrResourceNotSupported {
 			api.HandleNotFound(resp, req, err)
 			return
-		} else if errors.IsConflict(err) {
-			api.HandleConflict(resp, req, err)
+		} else if goerrors.As(err, &status) {
+			// errors of Kubernetes tell their codes, e.g. NotFound, Conflict and Invalid
+			api.HandleError(resp, req, err)
 			return
 		}
 		api.HandleBadRequest(resp, req, err)
@@ -12,6 +12,7 @@ import (
 	"captain/pkg/server/runtime"
 	"captain/pkg/simple/client/k8s"
 	"captain/pkg/simple/server/errors"
+	"captain/pkg/unify/export"
 	"captain/pkg/unify/query"
 	"captain/pkg/utils/clusterclient"
 
@@ -47,6 +48,7 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 		// no namespace scoped
 		webservice.Route(webservice.GET("/{resources}").
 			To(handler.handleListResources).
+			Produces(export.Produces...).
 			Metadata(restfulspec.KeyOpenAPITags, []string{resource.Name}).
 			Doc("list "+strings.Join(resource.Resources, ", ")).
 			Param(webservice.PathParameter("resources", "known values include "+strings.Join(resource.Resources, ", "))).
@@ -55,6 +57,7 @@ func AddToContainer(c *restful.Container, factory informers.CapInformerFactory,
 			Param(webservice.QueryParameter(query.ParameterPageSize, "pageSize").Required(false).DataFormat("pageSize=%d").DefaultValue("pageSize=10")).
 			Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
 			Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+			Param(webservice.QueryParameter(query.ParameterFormat, "export format, one of json, csv, yaml and ndjson, it takes precedence over Accept of text/csv, application/yaml and application/x-ndjson. Exports are not paginated unless pagination parameters are set").Required(false)).
 			Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{}}))
 
 		webservice.Route(webservice.GET("/{resources}/{name}").
@@ -1,6 +1,10 @@
 package runtime
 
 import (
+	"mime"
+	"net/http"
+	"strings"
+
 	"github.com/emicklei/go-restful"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -18,12 +22,27 @@ type ContainerBuilder []func(c *restful.Container) error
 const MimeMergePatchJson = "application/merge-patch+json"
 const MimeJsonPatchJson = "application/json-patch+json"
 const MimeMultipartFormData = "multipart/form-data"
+const MimeEventStream = "text/event-stream"
+
+// ContentTypeTable is content type of lists rendered as table, the same as kube-apiserver's
+const ContentTypeTable = "application/json;as=Table;v=v1;g=meta.k8s.io"
 
 func init() {
 	restful.RegisterEntityAccessor(MimeMergePatchJson, restful.NewEntityAccessorJSON(restful.MIME_JSON))
 	restful.RegisterEntityAccessor(MimeJsonPatchJson, restful.NewEntityAccessorJSON(restful.MIME_JSON))
 }
 
+// AcceptsTable returns true if request asks for lists rendered as table by Accept: application/json;as=Table
+func AcceptsTable(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == restful.MIME_JSON && params["as"] == "Table" {
+			return true
+		}
+	}
+	return false
+}
+
 func NewWebService(gv schema.GroupVersion) *restful.WebService {
 	webservice := restful.WebService{}
 	webservice.Path(ApiRootPath + "/" + gv.String()).
@@ -6,6 +6,7 @@ import (
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -20,6 +21,7 @@ type Client interface {
 	Snapshot() snapshotclient.Interface
 	ApiExtensions() apiextensionsclient.Interface
 	Discovery() discovery.DiscoveryInterface
+	Dynamic() dynamic.Interface
 	Prometheus() promresourcesclient.Interface
 	Master() string
 	Config() *rest.Config
@@ -35,6 +37,9 @@ type kubernetesClient struct {
 	// discovery client
 	discoveryClient *discovery.DiscoveryClient
 
+	// dynamic client for resources without typed clients
+	dynamicClient dynamic.Interface
+
 	istio istioclient.Interface
 
 	snapshot snapshotclient.Interface
@@ -69,6 +74,11 @@ func NewKubernetesClient(options *KubernetesOptions) (Client, error) {
 		return nil, err
 	}
 
+	k.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	k.istio, err = istioclient.NewForConfig(config)
 	if err != nil {
 		return nil, err
@@ -113,6 +123,10 @@ func (k *kubernetesClient) Discovery() discovery.DiscoveryInterface {
 	return k.discoveryClient
 }
 
+func (k *kubernetesClient) Dynamic() dynamic.Interface {
+	return k.dynamicClient
+}
+
 func (k *kubernetesClient) Istio() istioclient.Interface {
 	return k.istio
 }
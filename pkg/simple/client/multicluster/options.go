@@ -2,16 +2,25 @@ package multicluster
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
-	DefaultResyncPeriod      = 120 * time.Second
-	DefaultHostClusterName   = "host"
-	DefaultRegionClusterName = "host"
+	DefaultResyncPeriod       = 120 * time.Second
+	DefaultHostClusterName    = "host"
+	DefaultRegionClusterName  = "host"
+	DefaultProxyBindAddress   = "0.0.0.0:8081"
+	DefaultProxyPortRange     = "10000-10999"
+	DefaultClusterClientQPS   = 50
+	DefaultClusterClientBurst = 100
+	DefaultClusterCacheIdle   = 30 * time.Minute
+	DefaultBreakerThreshold   = 5
+	DefaultBreakerOpenPeriod  = 30 * time.Second
 )
 
 type Options struct {
@@ -26,6 +35,49 @@ type Options struct {
 
 	// HostRegionName is the region name of the control plane cluster, default set to host.
 	HostRegionName string `json:"hostRegionName,omitempty" yaml:"hostRegionName"`
+
+	// ProxyServiceName is the name of the service in captain-system namespace which exposes
+	// cluster proxy, agents of proxy connection clusters connect to it.
+	// Cluster proxy is disabled if left empty.
+	ProxyServiceName string `json:"proxyServiceName,omitempty" yaml:"proxyServiceName"`
+
+	// ProxyBindAddress is the address cluster proxy listens on for agent connections.
+	ProxyBindAddress string `json:"proxyBindAddress,omitempty" yaml:"proxyBindAddress"`
+
+	// ProxyTLSCertFile and ProxyTLSKeyFile are the certificate and key cluster proxy serves agent
+	// connections with, both are required unless ProxyInsecureServing is set.
+	ProxyTLSCertFile string `json:"proxyTLSCertFile,omitempty" yaml:"proxyTLSCertFile"`
+	ProxyTLSKeyFile  string `json:"proxyTLSKeyFile,omitempty" yaml:"proxyTLSKeyFile"`
+
+	// ProxyInsecureServing serves agent connections without TLS, tokens of agents and requests to
+	// member clusters are sent in plain text then, don't enable this except for testing.
+	ProxyInsecureServing bool `json:"proxyInsecureServing,omitempty" yaml:"proxyInsecureServing"`
+
+	// ProxyPortRange is the range of ports allocated to proxy connection clusters, each
+	// cluster takes two of them, one for kube-apiserver and one for captain-server.
+	ProxyPortRange string `json:"proxyPortRange,omitempty" yaml:"proxyPortRange"`
+
+	// ClusterClientQPS is the qps of clients talking to member clusters, shared by all
+	// requests to the same cluster.
+	ClusterClientQPS float32 `json:"clusterClientQPS,omitempty" yaml:"clusterClientQPS"`
+
+	// ClusterClientBurst is the burst of clients talking to member clusters.
+	ClusterClientBurst int `json:"clusterClientBurst,omitempty" yaml:"clusterClientBurst"`
+
+	// ClusterCacheEnabled makes resources of member clusters served from informers, which are
+	// started on first request to the cluster.
+	ClusterCacheEnabled bool `json:"clusterCacheEnabled" yaml:"clusterCacheEnabled"`
+
+	// ClusterCacheIdleTimeout is how long informers of a member cluster are kept without
+	// requests, they are never stopped if set to 0.
+	ClusterCacheIdleTimeout time.Duration `json:"clusterCacheIdleTimeout,omitempty" yaml:"clusterCacheIdleTimeout"`
+
+	// ClusterBreakerThreshold is the number of consecutive transport failures after which requests
+	// dispatched to the cluster fail fast, circuit breaker is disabled if set to 0.
+	ClusterBreakerThreshold int `json:"clusterBreakerThreshold,omitempty" yaml:"clusterBreakerThreshold"`
+
+	// ClusterBreakerOpenPeriod is how long requests fail fast before a probe request is let through.
+	ClusterBreakerOpenPeriod time.Duration `json:"clusterBreakerOpenPeriod,omitempty" yaml:"clusterBreakerOpenPeriod"`
 }
 
 // NewOptions returns a default nil options
@@ -35,12 +87,44 @@ func NewOptions() *Options {
 		ClusterControllerResyncPeriod: DefaultResyncPeriod,
 		HostClusterName:               DefaultHostClusterName,
 		HostRegionName:                DefaultRegionClusterName,
+		ProxyBindAddress:              DefaultProxyBindAddress,
+		ProxyPortRange:                DefaultProxyPortRange,
+		ClusterClientQPS:              DefaultClusterClientQPS,
+		ClusterClientBurst:            DefaultClusterClientBurst,
+		ClusterCacheEnabled:           true,
+		ClusterCacheIdleTimeout:       DefaultClusterCacheIdle,
+		ClusterBreakerThreshold:       DefaultBreakerThreshold,
+		ClusterBreakerOpenPeriod:      DefaultBreakerOpenPeriod,
 	}
 }
 
 func (o *Options) Validate() []error {
 	var err []error
 
+	if len(o.ProxyServiceName) != 0 {
+		if _, e := utilnet.ParsePortRange(o.ProxyPortRange); e != nil {
+			err = append(err, fmt.Errorf("invalid proxy port range %s, %v", o.ProxyPortRange, e))
+		}
+		if (len(o.ProxyTLSCertFile) == 0) != (len(o.ProxyTLSKeyFile) == 0) {
+			err = append(err, fmt.Errorf("proxy tls cert file and key file must be set together"))
+		} else if len(o.ProxyTLSCertFile) == 0 && !o.ProxyInsecureServing {
+			err = append(err, fmt.Errorf("cluster proxy requires proxy tls cert file and key file, "+
+				"set proxy insecure serving to serve agents without TLS"))
+		}
+	}
+
+	if o.ClusterClientQPS < 0 || o.ClusterClientBurst < 0 {
+		err = append(err, fmt.Errorf("cluster client qps and burst must not be negative"))
+	}
+
+	if o.ClusterCacheIdleTimeout < 0 {
+		err = append(err, fmt.Errorf("cluster cache idle timeout must not be negative"))
+	}
+
+	if o.ClusterBreakerThreshold < 0 || o.ClusterBreakerOpenPeriod < 0 {
+		err = append(err, fmt.Errorf("cluster breaker threshold and open period must not be negative"))
+	}
+
 	res := validation.IsQualifiedName(o.HostClusterName)
 	if len(res) == 0 {
 		return err
@@ -52,6 +136,11 @@ func (o *Options) Validate() []error {
 	return err
 }
 
+// ProxyEnabled returns true if host cluster serves agents of proxy connection clusters
+func (o *Options) ProxyEnabled() bool {
+	return o.Enable && len(o.ProxyServiceName) != 0
+}
+
 func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
 	fs.BoolVar(&o.Enable, "multiple-clusters", s.Enable, ""+
 		"This field instructs Captain to enter multiple-cluster mode or not.")
@@ -64,4 +153,40 @@ func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
 
 	fs.StringVar(&o.HostRegionName, "host-region-name", s.HostRegionName, "the region name of the control plane"+
 		" cluster, default set to host")
+
+	fs.StringVar(&o.ProxyServiceName, "proxy-service-name", s.ProxyServiceName, "the name of the service which "+
+		"exposes cluster proxy in captain-system namespace, cluster proxy is disabled if left empty")
+
+	fs.StringVar(&o.ProxyBindAddress, "proxy-bind-address", s.ProxyBindAddress, "the address cluster proxy "+
+		"listens on for agent connections")
+
+	fs.StringVar(&o.ProxyTLSCertFile, "proxy-tls-cert-file", s.ProxyTLSCertFile, "the certificate cluster proxy "+
+		"serves agent connections with")
+
+	fs.StringVar(&o.ProxyTLSKeyFile, "proxy-tls-private-key-file", s.ProxyTLSKeyFile, "the private key of "+
+		"proxy-tls-cert-file")
+
+	fs.BoolVar(&o.ProxyInsecureServing, "proxy-insecure-serving", s.ProxyInsecureServing, "serve agent "+
+		"connections without TLS, don't enable this except for testing")
+
+	fs.StringVar(&o.ProxyPortRange, "proxy-port-range", s.ProxyPortRange, "the range of ports allocated to "+
+		"proxy connection clusters, e.g. 10000-10999")
+
+	fs.Float32Var(&o.ClusterClientQPS, "cluster-client-qps", s.ClusterClientQPS, "the qps of clients talking to "+
+		"member clusters")
+
+	fs.IntVar(&o.ClusterClientBurst, "cluster-client-burst", s.ClusterClientBurst, "the burst of clients talking to "+
+		"member clusters")
+
+	fs.BoolVar(&o.ClusterCacheEnabled, "cluster-cache", s.ClusterCacheEnabled, "serve resources of member "+
+		"clusters from informers started on demand")
+
+	fs.DurationVar(&o.ClusterCacheIdleTimeout, "cluster-cache-idle-timeout", s.ClusterCacheIdleTimeout, "how long "+
+		"informers of a member cluster are kept without requests, 0 means never stopped")
+
+	fs.IntVar(&o.ClusterBreakerThreshold, "cluster-breaker-threshold", s.ClusterBreakerThreshold, "number of "+
+		"consecutive transport failures after which requests to the cluster fail fast, 0 disables circuit breaker")
+
+	fs.DurationVar(&o.ClusterBreakerOpenPeriod, "cluster-breaker-open-period", s.ClusterBreakerOpenPeriod, "how "+
+		"long requests to a broken cluster fail fast before a probe request is let through")
 }
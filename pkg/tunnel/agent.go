@@ -0,0 +1,218 @@
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/apimachinery/pkg/util/proxy"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+
+	"captain/pkg/server/request"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Agent runs in member cluster, keeps a tunnel to cluster proxy in host cluster and
+// serves requests coming through it with kube-apiserver and captain-server of member cluster
+type Agent struct {
+	options *AgentOptions
+
+	kubernetesHandler http.Handler
+	captainHandler    http.Handler
+}
+
+func NewAgent(options *AgentOptions, config *rest.Config) (*Agent, error) {
+	kubernetesURL, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	// requests from host cluster carry no credential, they are sent with the service account of
+	// agent impersonating callers in host cluster
+	kubernetesTransport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	captainURL, err := url.Parse(options.CaptainService)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{
+		options:           options,
+		kubernetesHandler: newProxyHandler(kubernetesURL, kubernetesTransport, true),
+		captainHandler:    newProxyHandler(captainURL, http.DefaultTransport, false),
+	}, nil
+}
+
+// Run connects to cluster proxy and reconnects whenever tunnel is broken, until ctx is done
+func (a *Agent) Run(ctx context.Context) error {
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		klog.V(0).Infof("Connecting to cluster proxy %s", a.options.ProxyServer)
+		if err := a.connectAndServe(ctx); err != nil {
+			klog.Errorf("Tunnel to cluster proxy %s is broken, %v", a.options.ProxyServer, err)
+		}
+	}, a.options.ReconnectPeriod, 0.5, true)
+	return nil
+}
+
+func (a *Agent) connectAndServe(ctx context.Context) error {
+	conn, err := a.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	kubernetesListener := newStreamListener(tunnelAddr(TargetKubernetes))
+	captainListener := newStreamListener(tunnelAddr(TargetCaptain))
+	listeners := map[string]*streamListener{
+		TargetKubernetes: kubernetesListener,
+		TargetCaptain:    captainListener,
+	}
+
+	// streams may arrive before NewServerConnection returns
+	var tunnel httpstream.Connection
+	ready := make(chan struct{})
+	tunnel, err = spdy.NewServerConnection(conn, func(stream httpstream.Stream, replySent <-chan struct{}) error {
+		target := stream.Headers().Get(HeaderTarget)
+		listener, ok := listeners[target]
+		if !ok {
+			return fmt.Errorf("unknown tunnel target %q", target)
+		}
+		go func() {
+			<-replySent
+			<-ready
+			listener.push(newStreamConn(stream, tunnel, tunnelAddr(target), tunnelAddr(a.options.ProxyServer)))
+		}()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	close(ready)
+	defer tunnel.Close()
+
+	kubernetesServer := &http.Server{Handler: a.kubernetesHandler}
+	captainServer := &http.Server{Handler: a.captainHandler}
+	go func() { _ = kubernetesServer.Serve(kubernetesListener) }()
+	go func() { _ = captainServer.Serve(captainListener) }()
+	defer kubernetesServer.Close()
+	defer captainServer.Close()
+
+	klog.V(0).Infof("Tunnel to cluster proxy %s established", a.options.ProxyServer)
+
+	select {
+	case <-tunnel.CloseChan():
+		return fmt.Errorf("connection closed by cluster proxy")
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// connect dials cluster proxy and upgrades the connection to tunnel
+func (a *Agent) connect(ctx context.Context) (net.Conn, error) {
+	u, err := url.Parse(a.options.ProxyServer)
+	if err != nil {
+		return nil, err
+	}
+
+	host := u.Host
+	if len(u.Port()) == 0 {
+		if u.Scheme == "https" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: pingPeriod}
+	var conn net.Conn
+	if u.Scheme == "https" {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{
+			ServerName:         u.Hostname(),
+			InsecureSkipVerify: a.options.InsecureSkipTLSVerify,
+		}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = ConnectPath
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", UpgradeProtocol)
+	req.Header.Set("Authorization", "Bearer "+a.options.Token)
+	req.Header.Set(HeaderCluster, a.options.Cluster)
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer conn.Close()
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("cluster proxy refused connection, %s: %s", resp.Status, message)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return newBufferedConn(conn, reader), nil
+}
+
+// newProxyHandler forwards requests to target, upgrade requests like exec and port-forward included.
+// If impersonation is true, requests must impersonate users of host cluster and carry no credential,
+// they are sent with the service account of agent which is only allowed to impersonate.
+func newProxyHandler(target *url.URL, transport http.RoundTripper, impersonation bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u := *req.URL
+		u.Host = target.Host
+		u.Scheme = target.Scheme
+
+		if impersonation {
+			req.Header.Del("Authorization")
+			if !strings.HasPrefix(req.Header.Get(authenticationv1.ImpersonateUserHeader), ImpersonationPrefix) {
+				request.WriteStatus(w, req, http.StatusForbidden, errors.New("requests must impersonate users of host cluster"))
+				return
+			}
+		}
+
+		httpProxy := proxy.NewUpgradeAwareHandler(&u, transport, true, false, &errorResponder{})
+		httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
+		httpProxy.ServeHTTP(w, req)
+	})
+}
+
+type errorResponder struct{}
+
+func (e *errorResponder) Error(w http.ResponseWriter, req *http.Request, err error) {
+	request.WriteStatus(w, req, http.StatusServiceUnavailable, err)
+}
@@ -0,0 +1,241 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+)
+
+const (
+	// maxRetries is the number of times a cluster will be retried before it is dropped out of the queue.
+	maxRetries = 15
+
+	tokenLength = 32
+)
+
+func (s *Server) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	s.queue.Add(key)
+}
+
+func (s *Server) worker(ctx context.Context) {
+	for s.processNextItem() {
+	}
+}
+
+func (s *Server) processNextItem() bool {
+	key, quit := s.queue.Get()
+	if quit {
+		return false
+	}
+	defer s.queue.Done(key)
+
+	err := s.initializeCluster(key.(string))
+	if err == nil {
+		s.queue.Forget(key)
+		return true
+	}
+
+	if s.queue.NumRequeues(key) < maxRetries {
+		klog.V(2).Infof("Error initializing cluster %s for proxy, retrying, %v", key, err)
+		s.queue.AddRateLimited(key)
+		return true
+	}
+
+	klog.Errorf("Dropping cluster %s out of the proxy queue, %v", key, err)
+	s.queue.Forget(key)
+	return true
+}
+
+// initializeCluster generates token and allocates ports for proxy connection cluster,
+// ports of deleted clusters are released from proxy service
+func (s *Server) initializeCluster(name string) error {
+	cluster, err := s.clusterLister.Get(name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err != nil || !cluster.DeletionTimestamp.IsZero() || cluster.Spec.Connection.Type != clusterv1alpha1.ConnectionTypeProxy {
+		return s.syncServicePorts()
+	}
+
+	connection := cluster.Spec.Connection
+	if len(connection.Token) != 0 && connection.KubernetesAPIServerPort != 0 && connection.CaptainAPIServerPort != 0 &&
+		isConditionTrue(cluster, clusterv1alpha1.ClusterInitialized) {
+		return s.syncServicePorts()
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cluster, err := s.clusterClient.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		connection := &cluster.Spec.Connection
+		if len(connection.Token) == 0 {
+			if connection.Token, err = generateToken(); err != nil {
+				return err
+			}
+		}
+
+		if connection.KubernetesAPIServerPort == 0 || connection.CaptainAPIServerPort == 0 {
+			ports, err := s.allocatePorts(name, 2)
+			if err != nil {
+				return err
+			}
+			connection.KubernetesAPIServerPort, connection.CaptainAPIServerPort = ports[0], ports[1]
+		}
+
+		setClusterCondition(cluster, clusterv1alpha1.ClusterCondition{
+			Type:               clusterv1alpha1.ClusterInitialized,
+			Status:             v1.ConditionTrue,
+			LastUpdateTime:     metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+			Reason:             string(clusterv1alpha1.ClusterInitialized),
+			Message:            "Cluster token and proxy ports are allocated",
+		})
+
+		_, err = s.clusterClient.Update(context.TODO(), cluster, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	klog.V(0).Infof("Cluster %s is initialized for proxy", name)
+	// service ports are synced when the update comes back from informer
+	return nil
+}
+
+// allocatePorts picks free ports in port range, ports taken by other clusters are skipped
+func (s *Server) allocatePorts(name string, count int) ([]uint16, error) {
+	portRange, err := utilnet.ParsePortRange(s.options.ProxyPortRange)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.usedPorts(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make([]uint16, 0, count)
+	for port := portRange.Base; port < portRange.Base+portRange.Size && len(ports) < count; port++ {
+		if !used.Has(port) {
+			ports = append(ports, uint16(port))
+		}
+	}
+
+	if len(ports) < count {
+		return nil, fmt.Errorf("no free ports left in proxy port range %s", s.options.ProxyPortRange)
+	}
+	return ports, nil
+}
+
+// usedPorts returns ports allocated to proxy connection clusters except the given one
+func (s *Server) usedPorts(except string) (sets.Int, error) {
+	clusters, err := s.clusterLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	used := sets.NewInt()
+	for _, cluster := range clusters {
+		if cluster.Name == except || cluster.Spec.Connection.Type != clusterv1alpha1.ConnectionTypeProxy {
+			continue
+		}
+		if port := cluster.Spec.Connection.KubernetesAPIServerPort; port != 0 {
+			used.Insert(int(port))
+		}
+		if port := cluster.Spec.Connection.CaptainAPIServerPort; port != 0 {
+			used.Insert(int(port))
+		}
+	}
+	return used, nil
+}
+
+// syncServicePorts makes proxy service expose exactly the ports allocated to clusters,
+// ports not managed by proxy, the one agents connect to for example, are left untouched
+func (s *Server) syncServicePorts() error {
+	used, err := s.usedPorts("")
+	if err != nil {
+		return err
+	}
+
+	portRange, err := utilnet.ParsePortRange(s.options.ProxyPortRange)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		service, err := s.client.CoreV1().Services(ProxyNamespace).Get(context.TODO(), s.options.ProxyServiceName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		ports := make([]v1.ServicePort, 0, len(service.Spec.Ports)+used.Len())
+		exposed := sets.NewInt()
+		for _, port := range service.Spec.Ports {
+			if portRange.Contains(int(port.Port)) {
+				if !used.Has(int(port.Port)) {
+					continue
+				}
+				exposed.Insert(int(port.Port))
+			}
+			ports = append(ports, port)
+		}
+
+		for _, port := range used.Difference(exposed).List() {
+			ports = append(ports, v1.ServicePort{
+				Name:       fmt.Sprintf("tunnel-%d", port),
+				Protocol:   v1.ProtocolTCP,
+				Port:       int32(port),
+				TargetPort: intstr.FromInt(port),
+			})
+		}
+
+		if len(ports) == len(service.Spec.Ports) && exposed.Len() == used.Len() {
+			return nil
+		}
+
+		service.Spec.Ports = ports
+		_, err = s.client.CoreV1().Services(ProxyNamespace).Update(context.TODO(), service, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isConditionTrue(cluster *clusterv1alpha1.Cluster, conditionType clusterv1alpha1.ClusterConditionType) bool {
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
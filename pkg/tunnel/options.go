@@ -0,0 +1,90 @@
+package tunnel
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	DefaultCaptainService  = "http://captain-server.captain-system.svc:9090"
+	DefaultReconnectPeriod = 5 * time.Second
+)
+
+// AgentOptions holds everything captain-agent needs to connect to cluster proxy in host cluster
+type AgentOptions struct {
+	// Address of cluster proxy in host cluster, e.g. https://proxy.example.com:8081
+	ProxyServer string `json:"proxyServer" yaml:"proxyServer"`
+
+	// Name of the member cluster, same as the name of Cluster object in host cluster
+	Cluster string `json:"cluster" yaml:"cluster"`
+
+	// Token used to authenticate with cluster proxy, which is populated to
+	// Cluster.Spec.Connection.Token by host cluster
+	Token string `json:"token" yaml:"token"`
+
+	// Captain server address in member cluster
+	CaptainService string `json:"captainService" yaml:"captainService"`
+
+	// Skip verifying the certificate of cluster proxy, for testing only
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty" yaml:"insecureSkipTLSVerify,omitempty"`
+
+	// Interval between reconnections when tunnel is broken
+	ReconnectPeriod time.Duration `json:"reconnectPeriod,omitempty" yaml:"reconnectPeriod,omitempty"`
+}
+
+func NewAgentOptions() *AgentOptions {
+	return &AgentOptions{
+		CaptainService:  DefaultCaptainService,
+		ReconnectPeriod: DefaultReconnectPeriod,
+	}
+}
+
+func (o *AgentOptions) Validate() []error {
+	var errs []error
+
+	if len(o.ProxyServer) == 0 {
+		errs = append(errs, fmt.Errorf("proxy server address must not be empty"))
+	} else if u, err := url.Parse(o.ProxyServer); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("invalid proxy server address %s", o.ProxyServer))
+	}
+
+	if len(o.Cluster) == 0 {
+		errs = append(errs, fmt.Errorf("cluster name must not be empty"))
+	}
+
+	if len(o.Token) == 0 {
+		errs = append(errs, fmt.Errorf("token must not be empty"))
+	}
+
+	if _, err := url.Parse(o.CaptainService); err != nil {
+		errs = append(errs, fmt.Errorf("invalid captain service address %s", o.CaptainService))
+	}
+
+	if o.ReconnectPeriod <= 0 {
+		errs = append(errs, fmt.Errorf("reconnect period must be positive"))
+	}
+
+	return errs
+}
+
+func (o *AgentOptions) AddFlags(fs *pflag.FlagSet, s *AgentOptions) {
+	fs.StringVar(&o.ProxyServer, "proxy-server", s.ProxyServer, "Address of cluster proxy in host cluster, "+
+		"e.g. https://proxy.example.com:8081, http is only for cluster proxy serving without TLS.")
+
+	fs.StringVar(&o.Cluster, "cluster", s.Cluster, "Name of the cluster this agent serves, "+
+		"same as the name of Cluster object in host cluster.")
+
+	fs.StringVar(&o.Token, "token", s.Token, "Token used to authenticate with cluster proxy, "+
+		"which is populated to spec.connection.token of Cluster object in host cluster.")
+
+	fs.StringVar(&o.CaptainService, "captain-service", s.CaptainService, "Captain server address in this cluster.")
+
+	fs.BoolVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", s.InsecureSkipTLSVerify, ""+
+		"Skip verifying the certificate of cluster proxy, don't enable this except for testing.")
+
+	fs.DurationVar(&o.ReconnectPeriod, "reconnect-period", s.ReconnectPeriod, "Interval between reconnections "+
+		"when tunnel is broken.")
+}
@@ -0,0 +1,461 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/apimachinery/pkg/util/proxy"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	captainapi "captain/pkg/api"
+	clusterclient "captain/pkg/client/clientset/versioned/typed/cluster/v1alpha1"
+	clusterinformer "captain/pkg/client/informers/externalversions/cluster/v1alpha1"
+	clusterlister "captain/pkg/client/listers/cluster/v1alpha1"
+	"captain/pkg/simple/client/multicluster"
+)
+
+// Server is the cluster proxy running in host cluster. It accepts tunnels from agents and
+// listens on the ports allocated to each cluster, connections to those ports are forwarded
+// to member cluster through the tunnel.
+type Server struct {
+	options *multicluster.Options
+
+	client           kubernetes.Interface
+	clusterClient    clusterclient.ClusterInterface
+	clusterLister    clusterlister.ClusterLister
+	clusterHasSynced cache.InformerSynced
+
+	// clusters waiting for token and ports allocation
+	queue workqueue.RateLimitingInterface
+
+	// tokens caches users of tokens reviewed, keyed by sha256 of tokens
+	tokens *utilcache.LRUExpireCache
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewServer(
+	client kubernetes.Interface,
+	clusterInformer clusterinformer.ClusterInformer,
+	clusterClient clusterclient.ClusterInterface,
+	options *multicluster.Options,
+) *Server {
+	s := &Server{
+		options:          options,
+		client:           client,
+		clusterClient:    clusterClient,
+		clusterLister:    clusterInformer.Lister(),
+		clusterHasSynced: clusterInformer.Informer().HasSynced,
+		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "cluster-proxy"),
+		tokens:           utilcache.NewLRUExpireCache(tokenCacheSize),
+		sessions:         make(map[string]*session),
+	}
+
+	clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: s.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCluster := oldObj.(*clusterv1alpha1.Cluster)
+			newCluster := newObj.(*clusterv1alpha1.Cluster)
+			s.enqueue(newObj)
+			// token rotated or ports reallocated, agent needs to reconnect
+			if oldCluster.Spec.Connection.Token != newCluster.Spec.Connection.Token ||
+				oldCluster.Spec.Connection.Type != newCluster.Spec.Connection.Type ||
+				oldCluster.Spec.Connection.KubernetesAPIServerPort != newCluster.Spec.Connection.KubernetesAPIServerPort ||
+				oldCluster.Spec.Connection.CaptainAPIServerPort != newCluster.Spec.Connection.CaptainAPIServerPort {
+				s.closeSession(newCluster.Name, nil)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if cluster, ok := obj.(*clusterv1alpha1.Cluster); ok {
+				s.closeSession(cluster.Name, nil)
+			} else if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				if cluster, ok := tombstone.Obj.(*clusterv1alpha1.Cluster); ok {
+					s.closeSession(cluster.Name, nil)
+				}
+			}
+			s.enqueue(obj)
+		},
+	})
+
+	return s
+}
+
+// Start serves agents until ctx is done, it implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	defer s.queue.ShutDown()
+
+	// tokens of agents and requests to member clusters go through connections of agents
+	tlsEnabled := len(s.options.ProxyTLSCertFile) != 0 && len(s.options.ProxyTLSKeyFile) != 0
+	if !tlsEnabled && !s.options.ProxyInsecureServing {
+		return fmt.Errorf("cluster proxy requires tls cert and key files unless insecure serving is enabled")
+	}
+
+	if !cache.WaitForCacheSync(ctx.Done(), s.clusterHasSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	// allocations must be serialized, so there is only one worker
+	go wait.UntilWithContext(ctx, s.worker, time.Second)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ConnectPath, s.handleConnect)
+	server := &http.Server{Addr: s.options.ProxyBindAddress, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+		s.mu.Lock()
+		for name, sess := range s.sessions {
+			sess.close()
+			delete(s.sessions, name)
+		}
+		s.mu.Unlock()
+	}()
+
+	var err error
+	if tlsEnabled {
+		klog.V(0).Infof("Cluster proxy listening on %s", s.options.ProxyBindAddress)
+		err = server.ListenAndServeTLS(s.options.ProxyTLSCertFile, s.options.ProxyTLSKeyFile)
+	} else {
+		klog.Warningf("Cluster proxy listening on %s without TLS, tokens of agents are sent in plain text", s.options.ProxyBindAddress)
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, req *http.Request) {
+	if !isTunnelUpgrade(req) {
+		reject(w, http.StatusBadRequest, "", fmt.Errorf("expect upgrading to %s", UpgradeProtocol))
+		return
+	}
+
+	name := req.Header.Get(HeaderCluster)
+	cluster, err := s.clusterLister.Get(name)
+	if err != nil {
+		reject(w, http.StatusNotFound, name, fmt.Errorf("cluster %s not found", name))
+		return
+	}
+
+	connection := cluster.Spec.Connection
+	if connection.Type != clusterv1alpha1.ConnectionTypeProxy {
+		reject(w, http.StatusBadRequest, name, fmt.Errorf("cluster %s is not a proxy connection cluster", name))
+		return
+	}
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if len(connection.Token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(connection.Token)) != 1 {
+		klog.Warningf("Agent of cluster %s from %s rejected, invalid token", name, req.RemoteAddr)
+		reject(w, http.StatusUnauthorized, name, errors.New("invalid token"))
+		return
+	}
+
+	if connection.KubernetesAPIServerPort == 0 || connection.CaptainAPIServerPort == 0 {
+		reject(w, http.StatusServiceUnavailable, name, fmt.Errorf("cluster %s is not initialized yet", name))
+		return
+	}
+
+	// only one agent is served for each cluster, the newer one wins
+	s.closeSession(name, nil)
+
+	sess, err := s.listen(name, connection.KubernetesAPIServerPort, connection.CaptainAPIServerPort)
+	if err != nil {
+		klog.Errorf("Failed to listen for cluster %s, %v", name, err)
+		reject(w, http.StatusInternalServerError, name, err)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		sess.close()
+		reject(w, http.StatusInternalServerError, name, errors.New("connection upgrading is not supported"))
+		return
+	}
+	conn, bufrw, err := hijacker.Hijack()
+	if err != nil {
+		sess.close()
+		klog.Errorf("Failed to hijack connection of cluster %s, %v", name, err)
+		return
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + UpgradeProtocol + "\r\n\r\n"
+	if _, err = conn.Write([]byte(response)); err != nil {
+		sess.close()
+		conn.Close()
+		return
+	}
+
+	sess.tunnel, err = spdy.NewClientConnectionWithPings(newBufferedConn(conn, bufrw.Reader), pingPeriod)
+	if err != nil {
+		sess.close()
+		klog.Errorf("Failed to create tunnel for cluster %s, %v", name, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[name] = sess
+	s.mu.Unlock()
+
+	go s.serve(sess, sess.kubernetesListener, TargetKubernetes)
+	go s.serve(sess, sess.captainListener, TargetCaptain)
+
+	klog.V(0).Infof("Agent of cluster %s connected from %s", name, req.RemoteAddr)
+	s.updateClusterConnection(name, true)
+
+	go func() {
+		<-sess.tunnel.CloseChan()
+		s.closeSession(name, sess)
+		klog.V(0).Infof("Agent of cluster %s disconnected", name)
+
+		// agent may have reconnected already
+		s.mu.Lock()
+		_, reconnected := s.sessions[name]
+		s.mu.Unlock()
+		if !reconnected {
+			s.updateClusterConnection(name, false)
+		}
+	}()
+}
+
+// serve serves requests to target of cluster on listener until the listener is closed
+func (s *Server) serve(sess *session, listener net.Listener, target string) {
+	server := &http.Server{Handler: s.newTunnelHandler(sess, target)}
+	err := server.Serve(listener)
+	klog.V(4).Infof("Stop serving %s of cluster %s, %v", target, sess.cluster, err)
+}
+
+// newTunnelHandler forwards requests to target of cluster through tunnel. Callers are authenticated
+// with tokens of host cluster, requests to kube-apiserver are sent on behalf of callers by
+// impersonating them with ImpersonationPrefix, so they are authorized by RBAC of member cluster.
+// Requests to captain-server carry credentials of end users in X-Captain-Authorization, which
+// are authenticated by captain-server of member cluster.
+func (s *Server) newTunnelHandler(sess *session, target string) http.Handler {
+	transport := &http.Transport{
+		DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+			return sess.dial(target)
+		},
+		IdleConnTimeout: 90 * time.Second,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, err := s.authenticate(req)
+		if err != nil {
+			klog.V(4).Infof("Request to %s of cluster %s from %s rejected, %v", target, sess.cluster, req.RemoteAddr, err)
+			reject(w, http.StatusUnauthorized, sess.cluster, err)
+			return
+		}
+
+		req.Header.Del("Authorization")
+		for key := range req.Header {
+			if strings.HasPrefix(key, "Impersonate-") {
+				req.Header.Del(key)
+			}
+		}
+		if target == TargetKubernetes {
+			req.Header.Set(authenticationv1.ImpersonateUserHeader, ImpersonationPrefix+user.Username)
+		}
+
+		// streams are carried by the connection of agent, which is encrypted by cluster proxy
+		u := *req.URL
+		u.Scheme, u.Host = "http", target
+		httpProxy := proxy.NewUpgradeAwareHandler(&u, transport, true, false, &errorResponder{})
+		httpProxy.UpgradeTransport = proxy.NewUpgradeRequestRoundTripper(transport, transport)
+		httpProxy.ServeHTTP(w, req)
+	})
+}
+
+// authenticate returns user of the bearer token of req, tokens are reviewed by host cluster and
+// results are cached for a while
+func (s *Server) authenticate(req *http.Request) (*authenticationv1.UserInfo, error) {
+	authorization := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, errors.New("bearer token is required")
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	if user, ok := s.tokens.Get(key); ok {
+		return user.(*authenticationv1.UserInfo), nil
+	}
+
+	review, err := s.client.AuthenticationV1().TokenReviews().Create(req.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("invalid token, %s", review.Status.Error)
+	}
+	s.tokens.Add(key, &review.Status.User, tokenCacheTTL)
+	return &review.Status.User, nil
+}
+
+// reject responds agents of cluster with Status of err
+func reject(w http.ResponseWriter, code int, cluster string, err error) {
+	captainapi.WriteStatus(w, captainapi.NewStatus(code, err).WithResource("", cluster, "", "", ""))
+}
+
+func (s *Server) listen(name string, kubernetesPort, captainPort uint16) (*session, error) {
+	host, _, err := net.SplitHostPort(s.options.ProxyBindAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetesListener, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(kubernetesPort)))
+	if err != nil {
+		return nil, err
+	}
+
+	captainListener, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprint(captainPort)))
+	if err != nil {
+		kubernetesListener.Close()
+		return nil, err
+	}
+
+	return &session{
+		cluster:            name,
+		kubernetesListener: kubernetesListener,
+		captainListener:    captainListener,
+	}, nil
+}
+
+// closeSession closes session of cluster, if expected is not nil, session is closed
+// only when it is still the current one
+func (s *Server) closeSession(name string, expected *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[name]
+	if !ok || (expected != nil && sess != expected) {
+		return
+	}
+	delete(s.sessions, name)
+	sess.close()
+}
+
+// updateClusterConnection populates connection endpoints and agent condition of cluster
+func (s *Server) updateClusterConnection(name string, connected bool) {
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cluster, err := s.clusterClient.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		condition := clusterv1alpha1.ClusterCondition{
+			Type:               clusterv1alpha1.ClusterAgentAvailable,
+			Status:             v1.ConditionFalse,
+			LastUpdateTime:     metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+			Reason:             "AgentDisconnected",
+			Message:            "Cluster agent is disconnected",
+		}
+
+		if connected {
+			condition.Status = v1.ConditionTrue
+			condition.Reason = "AgentConnected"
+			condition.Message = "Cluster agent is connected"
+
+			connection := &cluster.Spec.Connection
+			connection.KubernetesAPIEndpoint = ServiceEndpoint(s.options.ProxyServiceName, connection.KubernetesAPIServerPort)
+			connection.CaptainAPIEndpoint = ServiceEndpoint(s.options.ProxyServiceName, connection.CaptainAPIServerPort)
+			connection.KubeConfig, err = buildKubeconfig(connection.KubernetesAPIEndpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		setClusterCondition(cluster, condition)
+		_, err = s.clusterClient.Update(context.TODO(), cluster, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Errorf("Failed to update connection of cluster %s, %v", name, err)
+	}
+}
+
+// buildKubeconfig generates kubeconfig connecting to kube-apiserver through tunnel, callers
+// authenticate with their service account tokens of host cluster
+func buildKubeconfig(server string) ([]byte, error) {
+	config := api.NewConfig()
+	config.Clusters["kubernetes"] = &api.Cluster{Server: server}
+	config.AuthInfos["captain-agent"] = &api.AuthInfo{TokenFile: serviceAccountTokenFile}
+	config.Contexts["captain-agent@kubernetes"] = &api.Context{
+		Cluster:  "kubernetes",
+		AuthInfo: "captain-agent",
+	}
+	config.CurrentContext = "captain-agent@kubernetes"
+	return clientcmd.Write(*config)
+}
+
+// setClusterCondition replaces condition of the same type, LastTransitionTime is kept
+// if status doesn't change
+func setClusterCondition(cluster *clusterv1alpha1.Cluster, condition clusterv1alpha1.ClusterCondition) {
+	conditions := make([]clusterv1alpha1.ClusterCondition, 0, len(cluster.Status.Conditions)+1)
+	for _, cond := range cluster.Status.Conditions {
+		if cond.Type == condition.Type {
+			if cond.Status == condition.Status {
+				condition.LastTransitionTime = cond.LastTransitionTime
+			}
+			continue
+		}
+		conditions = append(conditions, cond)
+	}
+	cluster.Status.Conditions = append(conditions, condition)
+}
+
+// session is an established tunnel together with listeners of the cluster
+type session struct {
+	cluster            string
+	tunnel             httpstream.Connection
+	kubernetesListener net.Listener
+	captainListener    net.Listener
+}
+
+// dial opens a stream to target in tunnel
+func (s *session) dial(target string) (net.Conn, error) {
+	headers := http.Header{}
+	headers.Set(HeaderTarget, target)
+	stream, err := s.tunnel.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s stream to cluster %s, %v", target, s.cluster, err)
+	}
+	return newStreamConn(stream, s.tunnel, tunnelAddr(target), tunnelAddr(s.cluster)), nil
+}
+
+func (s *session) close() {
+	if s.kubernetesListener != nil {
+		s.kubernetesListener.Close()
+	}
+	if s.captainListener != nil {
+		s.captainListener.Close()
+	}
+	if s.tunnel != nil {
+		s.tunnel.Close()
+	}
+}
@@ -0,0 +1,87 @@
+package tunnel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	reviews := 0
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "valid" {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "system:serviceaccount:captain-system:captain-server"},
+			}
+		}
+		return true, review, nil
+	})
+	s := &Server{client: client, tokens: utilcache.NewLRUExpireCache(tokenCacheSize)}
+
+	request := func(authorization string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		if len(authorization) != 0 {
+			req.Header.Set("Authorization", authorization)
+		}
+		return req
+	}
+	for i := 0; i < 2; i++ {
+		user, err := s.authenticate(request("Bearer valid"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.Username != "system:serviceaccount:captain-system:captain-server" {
+			t.Errorf("unexpected user %s", user.Username)
+		}
+	}
+	if reviews != 1 {
+		t.Errorf("expected token reviewed once, got %d", reviews)
+	}
+	for _, authorization := range []string{"", "Basic dXNlcg==", "Bearer invalid"} {
+		if _, err := s.authenticate(request(authorization)); err == nil {
+			t.Errorf("expected authorization %q rejected", authorization)
+		}
+	}
+}
+
+func TestProxyHandlerImpersonation(t *testing.T) {
+	var received http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received = req.Header.Clone()
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+	handler := newProxyHandler(target, http.DefaultTransport, true)
+
+	// requests not impersonating users of host cluster are forbidden
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set(authenticationv1.ImpersonateUserHeader, "system:admin")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || received != nil {
+		t.Errorf("expected forbidden, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set(authenticationv1.ImpersonateUserHeader, ImpersonationPrefix+"system:serviceaccount:captain-system:captain-server")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected ok, got %d", w.Code)
+	}
+	if len(received.Get("Authorization")) != 0 || received.Get(authenticationv1.ImpersonateUserHeader) != req.Header.Get(authenticationv1.ImpersonateUserHeader) {
+		t.Errorf("unexpected headers %v", received)
+	}
+}
@@ -0,0 +1,163 @@
+package tunnel
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// Tunnel is how host cluster reaches member clusters behind NAT. captain-agent running in
+// the member cluster dials cluster proxy in host cluster and upgrades the connection, then
+// cluster proxy opens a multiplexed stream over the connection for every kube-apiserver or
+// captain-server connection made to the ports allocated to the cluster.
+//
+//	dispatcher/controller --> proxy:KubernetesAPIServerPort --\
+//	                                                          |== tunnel ==> agent --> kube-apiserver
+//	dispatcher/controller --> proxy:CaptainAPIServerPort -----/          \--> captain-server
+//
+// Callers of the ports authenticate with service account tokens of host cluster, agents send
+// requests to kube-apiserver impersonating them, agents themselves are only allowed to impersonate.
+
+const (
+	// ConnectPath is the path agents request to open a tunnel
+	ConnectPath = "/connect"
+
+	// UpgradeProtocol is the protocol agents request to upgrade connection to
+	UpgradeProtocol = "captain-tunnel"
+
+	// HeaderCluster carries the name of the cluster agent serves
+	HeaderCluster = "X-Captain-Cluster"
+
+	// HeaderTarget tells agent where the stream should be forwarded to
+	HeaderTarget = "X-Captain-Tunnel-Target"
+
+	TargetKubernetes = "kubernetes"
+	TargetCaptain    = "captain"
+
+	// ProxyNamespace is where cluster proxy service lives
+	ProxyNamespace = "captain-system"
+
+	// ImpersonationPrefix prefixes names of host cluster users impersonated by agents, so they
+	// are told apart from users of member cluster in RBAC bindings
+	ImpersonationPrefix = "captain:"
+
+	// pingPeriod keeps idle tunnels alive through load balancers and NAT gateways
+	pingPeriod = 15 * time.Second
+
+	// tokens of callers are reviewed again once cached results expire
+	tokenCacheSize = 1024
+	tokenCacheTTL  = 10 * time.Second
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// ServiceEndpoint returns the in-cluster endpoint of the tunnel port
+func ServiceEndpoint(serviceName string, port uint16) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", serviceName, ProxyNamespace, port)
+}
+
+// bufferedConn makes sure bytes buffered while reading the upgrade handshake are not lost
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func newBufferedConn(conn net.Conn, reader *bufio.Reader) net.Conn {
+	if reader == nil || reader.Buffered() == 0 {
+		return conn
+	}
+	return &bufferedConn{Conn: conn, reader: reader}
+}
+
+// streamConn adapts a tunnel stream to net.Conn, so it can be served by http.Server
+type streamConn struct {
+	httpstream.Stream
+	conn   httpstream.Connection
+	local  net.Addr
+	remote net.Addr
+	once   sync.Once
+}
+
+func newStreamConn(stream httpstream.Stream, conn httpstream.Connection, local, remote net.Addr) *streamConn {
+	return &streamConn{Stream: stream, conn: conn, local: local, remote: remote}
+}
+
+func (s *streamConn) Close() error {
+	var err error
+	s.once.Do(func() {
+		err = s.Stream.Close()
+		s.conn.RemoveStreams(s.Stream)
+	})
+	return err
+}
+
+func (s *streamConn) LocalAddr() net.Addr  { return s.local }
+func (s *streamConn) RemoteAddr() net.Addr { return s.remote }
+
+// Deadlines are not supported by streams, idle tunnels are detected by pings instead
+func (s *streamConn) SetDeadline(_ time.Time) error      { return nil }
+func (s *streamConn) SetReadDeadline(_ time.Time) error  { return nil }
+func (s *streamConn) SetWriteDeadline(_ time.Time) error { return nil }
+
+// streamListener is a net.Listener whose connections are streams accepted from the tunnel
+type streamListener struct {
+	addr    net.Addr
+	conns   chan net.Conn
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+func newStreamListener(addr net.Addr) *streamListener {
+	return &streamListener{
+		addr:    addr,
+		conns:   make(chan net.Conn),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (l *streamListener) push(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closeCh:
+		_ = conn.Close()
+	}
+}
+
+func (l *streamListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closeCh:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *streamListener) Close() error {
+	l.once.Do(func() {
+		close(l.closeCh)
+	})
+	return nil
+}
+
+func (l *streamListener) Addr() net.Addr {
+	return l.addr
+}
+
+// tunnelAddr is the address of one end of the tunnel
+type tunnelAddr string
+
+func (a tunnelAddr) Network() string { return UpgradeProtocol }
+func (a tunnelAddr) String() string  { return string(a) }
+
+func isTunnelUpgrade(req *http.Request) bool {
+	return httpstream.IsUpgradeRequest(req) && req.Header.Get("Upgrade") == UpgradeProtocol
+}
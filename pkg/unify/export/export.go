@@ -0,0 +1,307 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	captainscheme "captain/pkg/client/clientset/versioned/scheme"
+	"captain/pkg/unify/query"
+)
+
+// Format is the format list results are exported as
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatYAML   Format = "yaml"
+	FormatNDJSON Format = "ndjson"
+)
+
+const (
+	MimeCSV    = "text/csv"
+	MimeYAML   = "application/yaml"
+	MimeNDJSON = "application/x-ndjson"
+)
+
+// flushInterval is the number of items written between flushes, so that clients receive large
+// exports progressively
+const flushInterval = 100
+
+// MaxItems is the max number of items exported at a time, as lists are sorted in memory before
+// written. Larger exports are truncated with a continue token to export the rest.
+const MaxItems = 10000
+
+// formulaPrefixes are leading characters of CSV cells which spreadsheets evaluate as formulas
+const formulaPrefixes = "=+-@\t\r"
+
+var (
+	formats = map[string]Format{
+		string(FormatJSON):   FormatJSON,
+		string(FormatCSV):    FormatCSV,
+		string(FormatYAML):   FormatYAML,
+		string(FormatNDJSON): FormatNDJSON,
+	}
+	mimeFormats = map[string]Format{
+		MimeCSV:                 FormatCSV,
+		MimeYAML:                FormatYAML,
+		"application/x-yaml":    FormatYAML,
+		"text/yaml":             FormatYAML,
+		MimeNDJSON:              FormatNDJSON,
+		"application/ndjson":    FormatNDJSON,
+		"application/jsonlines": FormatNDJSON,
+	}
+	contentTypes = map[Format]string{
+		FormatCSV:    MimeCSV + "; charset=utf-8",
+		FormatYAML:   MimeYAML,
+		FormatNDJSON: MimeNDJSON,
+	}
+
+	// scheme resolves kinds of objects without type meta, e.g. objects from informers
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntime.Must(kubescheme.AddToScheme(scheme))
+	utilruntime.Must(captainscheme.AddToScheme(scheme))
+}
+
+// Produces are media types of list routes supporting exports
+var Produces = []string{restful.MIME_JSON, MimeCSV, MimeYAML, MimeNDJSON}
+
+// Negotiate returns format requested by format parameter, or by the first media type of Accept
+// header which is an export format, json if neither is requested
+func Negotiate(req *http.Request) (Format, error) {
+	if value := req.URL.Query().Get(query.ParameterFormat); len(value) != 0 {
+		format, ok := formats[strings.ToLower(value)]
+		if !ok {
+			return "", fmt.Errorf("unsupported %s %s, supported formats are json, csv, yaml and ndjson", query.ParameterFormat, value)
+		}
+		return format, nil
+	}
+
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
+		}
+		if format, ok := mimeFormats[mediaType]; ok {
+			return format, nil
+		}
+		if mediaType == restful.MIME_JSON {
+			return FormatJSON, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// Query returns query of the first MaxItems items if request exports list without pagination
+// parameters, so that exports are whole lists unless paginated explicitly or too large
+func Query(req *http.Request, format Format, q *query.QueryInfo) *query.QueryInfo {
+	if format == FormatJSON {
+		return q
+	}
+	values := req.URL.Query()
+	for _, key := range []string{query.ParameterPage, query.ParameterPageSize, query.ParameterLimit, query.ParameterContinue} {
+		if _, ok := values[key]; ok {
+			return q
+		}
+	}
+	q = q.WithoutPagination()
+	q.Limit = MaxItems
+	return q
+}
+
+// Columns are columns of CSV, which are usually the same as columns of table output
+type Columns interface {
+	// Names returns names of columns, which is the header of CSV
+	Names() []string
+	// Values returns values of columns of item
+	Values(item interface{}) ([]string, error)
+}
+
+// List is a list result to export
+type List struct {
+	// Name is the base name of the file downloaded, e.g. pods
+	Name  string
+	Items []interface{}
+	// Columns are columns of CSV, unused by other formats
+	Columns Columns
+	// Continue and ResourceVersion are kept in metadata of YAML lists
+	Continue        string
+	ResourceVersion string
+	// Warnings are sent in Warning headers, e.g. clusters failed to list
+	Warnings []string
+}
+
+// Write writes list in format, items are encoded and flushed progressively. Status and headers are
+// written before items, so errors of items can only be reported by aborting the stream.
+func Write(w http.ResponseWriter, format Format, list *List) error {
+	if format == FormatCSV && list.Columns == nil {
+		return fmt.Errorf("no columns to export as csv")
+	}
+
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", list.Name+"."+string(format)))
+	warnings := list.Warnings
+	if len(list.Continue) != 0 {
+		warnings = append(warnings, fmt.Sprintf("more items remain, export them with %s=%s", query.ParameterContinue, list.Continue))
+	}
+	for _, warning := range warnings {
+		w.Header().Add("Warning", fmt.Sprintf("299 - %q", warning))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, list)
+	case FormatYAML:
+		return writeYAML(w, list)
+	case FormatNDJSON:
+		return writeNDJSON(w, list)
+	default:
+		return fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func writeCSV(w http.ResponseWriter, list *List) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(list.Columns.Names()); err != nil {
+		return err
+	}
+	for i, item := range list.Items {
+		values, err := list.Columns.Values(item)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(escapeFormulas(values)); err != nil {
+			return err
+		}
+		if (i+1)%flushInterval == 0 {
+			writer.Flush()
+			flush(w)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeFormulas prefixes values which would be evaluated as formulas by spreadsheets with a quote,
+// values come from resources created by anyone who can create them
+func escapeFormulas(values []string) []string {
+	for i, value := range values {
+		if len(value) != 0 && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+			values[i] = "'" + value
+		}
+	}
+	return values
+}
+
+func writeNDJSON(w http.ResponseWriter, list *List) error {
+	encoder := json.NewEncoder(w)
+	for i, item := range list.Items {
+		if err := encoder.Encode(exportObject(item)); err != nil {
+			return err
+		}
+		if (i+1)%flushInterval == 0 {
+			flush(w)
+		}
+	}
+	return nil
+}
+
+// writeYAML writes items as a List the same as kubectl get -o yaml does
+func writeYAML(w http.ResponseWriter, list *List) error {
+	header := "apiVersion: v1\nkind: List\n"
+	if len(list.Continue)+len(list.ResourceVersion) != 0 {
+		metadata, err := yaml.Marshal(map[string]interface{}{"metadata": map[string]string{
+			"continue":        list.Continue,
+			"resourceVersion": list.ResourceVersion,
+		}})
+		if err != nil {
+			return err
+		}
+		header += string(metadata)
+	}
+	if len(list.Items) == 0 {
+		header += "items: []\n"
+	} else {
+		header += "items:\n"
+	}
+	if _, err := w.Write([]byte(header)); err != nil {
+		return err
+	}
+
+	for i, item := range list.Items {
+		data, err := yaml.Marshal(exportObject(item))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(indentItem(string(data)))); err != nil {
+			return err
+		}
+		if (i+1)%flushInterval == 0 {
+			flush(w)
+		}
+	}
+	return nil
+}
+
+// indentItem turns yaml document into an item of yaml sequence
+func indentItem(document string) string {
+	lines := strings.Split(strings.TrimSuffix(document, "\n"), "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = "- " + lines[i]
+		} else {
+			lines[i] = "  " + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// exportObject returns object of item to export, apiVersion and kind are set if missing, and
+// managedFields are removed as kubectl does. Items shared with informers are never modified.
+func exportObject(item interface{}) interface{} {
+	object, ok := item.(runtime.Object)
+	if !ok {
+		return item
+	}
+
+	var content map[string]interface{}
+	if obj, ok := object.(runtime.Unstructured); ok {
+		content = runtime.DeepCopyJSON(obj.UnstructuredContent())
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(object); err != nil {
+			return item
+		}
+	}
+
+	exported := &unstructured.Unstructured{Object: content}
+	if exported.GetKind() == "" {
+		if gvks, _, err := scheme.ObjectKinds(object); err == nil && len(gvks) != 0 {
+			exported.SetAPIVersion(gvks[0].GroupVersion().String())
+			exported.SetKind(gvks[0].Kind)
+		}
+	}
+	unstructured.RemoveNestedField(content, "metadata", "managedFields")
+	return content
+}
+
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
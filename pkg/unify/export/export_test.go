@@ -0,0 +1,137 @@
+package export
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"captain/pkg/unify/query"
+)
+
+type testColumns struct{}
+
+func (testColumns) Names() []string {
+	return []string{"Name", "Phase"}
+}
+
+func (testColumns) Values(item interface{}) ([]string, error) {
+	pod := item.(*corev1.Pod)
+	return []string{pod.Name, string(pod.Status.Phase)}, nil
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		url, accept string
+		expected    Format
+		err         bool
+	}{
+		{url: "/pods", expected: FormatJSON},
+		{url: "/pods", accept: "text/csv", expected: FormatCSV},
+		{url: "/pods", accept: "application/json, application/yaml", expected: FormatJSON},
+		{url: "/pods", accept: "text/plain, application/x-ndjson;q=0.9", expected: FormatNDJSON},
+		{url: "/pods?format=YAML", accept: "text/csv", expected: FormatYAML},
+		{url: "/pods?format=xml", err: true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		req.Header.Set("Accept", test.accept)
+		format, err := Negotiate(req)
+		if (err != nil) != test.err || format != test.expected {
+			t.Errorf("%s %q: expected %q %v, got %q %v", test.url, test.accept, test.expected, test.err, format, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web, 1", Namespace: "default", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	list := &List{Name: "pods", Items: []interface{}{pod}, Columns: testColumns{}, Warnings: []string{"cluster wx/c1: timeout"}}
+
+	tests := []struct {
+		format       Format
+		contentType  string
+		expected     []string
+		notContained string
+	}{
+		{format: FormatCSV, contentType: "text/csv; charset=utf-8", expected: []string{"Name,Phase\n\"web, 1\",Running\n"}},
+		{
+			format:       FormatNDJSON,
+			contentType:  MimeNDJSON,
+			expected:     []string{`{"apiVersion":"v1","kind":"Pod","metadata":{`, `"phase":"Running"`},
+			notContained: "managedFields",
+		},
+		{
+			format:       FormatYAML,
+			contentType:  MimeYAML,
+			expected:     []string{"apiVersion: v1\nkind: List\nitems:\n- apiVersion: v1\n  kind: Pod\n  metadata:\n", "    name: web, 1\n", "    phase: Running\n"},
+			notContained: "managedFields",
+		},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		if err := Write(recorder, test.format, list); err != nil {
+			t.Errorf("%s: %v", test.format, err)
+			continue
+		}
+		body := recorder.Body.String()
+		if contentType := recorder.Header().Get("Content-Type"); contentType != test.contentType {
+			t.Errorf("%s: expected content type %s, got %s", test.format, test.contentType, contentType)
+		}
+		if disposition := recorder.Header().Get("Content-Disposition"); disposition != `attachment; filename="pods.`+string(test.format)+`"` {
+			t.Errorf("%s: unexpected content disposition %s", test.format, disposition)
+		}
+		if warning := recorder.Header().Get("Warning"); warning != `299 - "cluster wx/c1: timeout"` {
+			t.Errorf("%s: unexpected warning %s", test.format, warning)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(body, expected) {
+				t.Errorf("%s: expected %q in\n%s", test.format, expected, body)
+			}
+		}
+		if len(test.notContained) != 0 && strings.Contains(body, test.notContained) {
+			t.Errorf("%s: unexpected %q in\n%s", test.format, test.notContained, body)
+		}
+	}
+
+	// items of informers are never modified
+	if len(pod.ManagedFields) != 1 || pod.Kind != "" {
+		t.Errorf("item is modified %+v", pod.ObjectMeta)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := Write(recorder, FormatYAML, &List{Name: "pods"}); err != nil || recorder.Body.String() != "apiVersion: v1\nkind: List\nitems: []\n" {
+		t.Errorf("unexpected empty list %q %v", recorder.Body.String(), err)
+	}
+
+	// cells evaluated as formulas by spreadsheets are quoted, the rest of truncated lists is told
+	pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "=HYPERLINK(\"http://example.com\")"}, Status: corev1.PodStatus{Phase: "-1"}}
+	recorder = httptest.NewRecorder()
+	if err := Write(recorder, FormatCSV, &List{Name: "pods", Items: []interface{}{pod}, Columns: testColumns{}, Continue: "token"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Name,Phase\n\"'=HYPERLINK(\"\"http://example.com\"\")\",'-1\n"; recorder.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, recorder.Body.String())
+	}
+	if warning := recorder.Header().Get("Warning"); !strings.Contains(warning, "continue=token") {
+		t.Errorf("unexpected warning %s", warning)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	q := query.New()
+	if got := Query(httptest.NewRequest(http.MethodGet, "/pods?format=csv", nil), FormatCSV, q); got.Limit != MaxItems || got.Pagination != query.NoPagination {
+		t.Errorf("exports without pagination are expected to be capped, got %+v", got)
+	}
+	if got := Query(httptest.NewRequest(http.MethodGet, "/pods?format=csv&limit=10", nil), FormatCSV, q); got != q {
+		t.Errorf("exports with pagination are expected to be unchanged, got %+v", got)
+	}
+	if got := Query(httptest.NewRequest(http.MethodGet, "/pods", nil), FormatJSON, q); got != q {
+		t.Errorf("json lists are expected to be unchanged, got %+v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ContinueTokenTTL is how long a continue token can be used, the same as the default interval etcd
+// compacts revisions at, after which continue tokens of apiservers expire as well
+const ContinueTokenTTL = 5 * time.Minute
+
+// ContinueToken is the position where the next page starts, clients see it as an opaque string
+type ContinueToken struct {
+	// ResourceVersion is the newest resource version of listed objects when the token was issued
+	ResourceVersion string `json:"rv,omitempty"`
+
+	// Issued is when the token was issued in unix seconds
+	Issued int64 `json:"issued"`
+
+	// SortBy and Ascending are the order of the list, the next page must be listed in the same order
+	SortBy    Field `json:"sortBy,omitempty"`
+	Ascending bool  `json:"asc,omitempty"`
+
+	// Values are values of sort keys of the last seen object in JSON, the next page starts after
+	// where they are placed in the list, even if the object is changed or gone. Sort keys computed
+	// by providers aren't kept, the object is located by its identity only then.
+	Values []json.RawMessage `json:"v,omitempty"`
+
+	// Namespace, Name and UID identify the last seen object
+	Namespace string `json:"ns,omitempty"`
+	Name      string `json:"n,omitempty"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// maxContinueTokenSize is the largest size of decompressed continue tokens, tokens carry a few
+// values which are far smaller
+const maxContinueTokenSize = 64 * 1024
+
+// Expired returns true if token was issued more than ContinueTokenTTL ago
+func (t *ContinueToken) Expired() bool {
+	return time.Since(time.Unix(t.Issued, 0)) > ContinueTokenTTL
+}
+
+// Encode returns the opaque string of token, which is compressed as values of sort keys can be long
+func (t *ContinueToken) Encode() string {
+	data, _ := json.Marshal(t)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes())
+}
+
+// DecodeContinueToken decodes token encoded by ContinueToken.Encode
+func DecodeContinueToken(s string) (*ContinueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed continue token")
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("malformed continue token")
+	}
+	// tokens are decompressed from user input, which could be a gzip bomb
+	data, err = ioutil.ReadAll(io.LimitReader(r, maxContinueTokenSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("malformed continue token")
+	}
+	if len(data) > maxContinueTokenSize {
+		return nil, apierrors.NewBadRequest("continue token is too large")
+	}
+	token := &ContinueToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("malformed continue token")
+	}
+	return token, nil
+}
@@ -0,0 +1,284 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FilterExpr is a boolean expression of filters, e.g. (status=Running or status=Pending) and not label=app=web.
+// Each filter is evaluated by match with the same semantics as the filter query parameters.
+type FilterExpr interface {
+	Eval(match func(Filter) bool) bool
+	String() string
+}
+
+type filterTerm Filter
+
+func (t filterTerm) Eval(match func(Filter) bool) bool {
+	return match(Filter(t))
+}
+
+func (t filterTerm) String() string {
+	value := string(t.Value)
+	if strings.ContainsAny(value, " \t()\"") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprintf("%s=%s", t.Field, value)
+}
+
+type andExpr []FilterExpr
+
+func (e andExpr) Eval(match func(Filter) bool) bool {
+	for _, expr := range e {
+		if !expr.Eval(match) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e andExpr) String() string {
+	return joinExprs(e, " and ")
+}
+
+type orExpr []FilterExpr
+
+func (e orExpr) Eval(match func(Filter) bool) bool {
+	for _, expr := range e {
+		if expr.Eval(match) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e orExpr) String() string {
+	return joinExprs(e, " or ")
+}
+
+type notExpr struct {
+	expr FilterExpr
+}
+
+func (e notExpr) Eval(match func(Filter) bool) bool {
+	return !e.expr.Eval(match)
+}
+
+func (e notExpr) String() string {
+	return "not (" + e.expr.String() + ")"
+}
+
+func joinExprs(exprs []FilterExpr, sep string) string {
+	var s []string
+	for _, expr := range exprs {
+		s = append(s, "("+expr.String()+")")
+	}
+	return strings.Join(s, sep)
+}
+
+// And returns expression true if all of exprs are true, nil exprs are ignored
+func And(exprs ...FilterExpr) FilterExpr {
+	var and andExpr
+	for _, expr := range exprs {
+		if expr != nil {
+			and = append(and, expr)
+		}
+	}
+	switch len(and) {
+	case 0:
+		return nil
+	case 1:
+		return and[0]
+	default:
+		return and
+	}
+}
+
+// Term returns expression of a single filter
+func Term(field Field, value Value) FilterExpr {
+	return filterTerm{Field: field, Value: value}
+}
+
+// ParseFilterExpr parses filter expression composed of field=value terms, and, or, not and parentheses.
+// not binds tighter than and, which binds tighter than or. Values containing spaces or parentheses
+// are double quoted, e.g. name="my app".
+func ParseFilterExpr(expr string) (FilterExpr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	p := &filterParser{tokens: tokens}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %s at position %d of filter expression", token, token.pos)
+	}
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind tokenKind
+	// text is value of word with quotes removed
+	text string
+	// quoted is true if any part of word is quoted, quoted words are never keywords
+	quoted bool
+	pos    int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenLeftParen:
+		return `"("`
+	case tokenRightParen:
+		return `")"`
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, pos: i})
+			i++
+		default:
+			word := token{kind: tokenWord, pos: i}
+			var text strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] != '"' {
+					text.WriteRune(runes[i])
+					i++
+					continue
+				}
+				start := i
+				word.quoted = true
+				for i++; i < len(runes) && runes[i] != '"'; i++ {
+					if runes[i] == '\\' && i+1 < len(runes) {
+						i++
+					}
+					text.WriteRune(runes[i])
+				}
+				if i == len(runes) {
+					return nil, fmt.Errorf("unterminated quote at position %d of filter expression", start)
+				}
+				i++
+			}
+			word.text = text.String()
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	next   int
+}
+
+func (p *filterParser) peek() (token, bool) {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next], true
+	}
+	return token{}, false
+}
+
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	var or orExpr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+		if token, ok := p.peek(); !ok || !token.isKeyword("or") {
+			break
+		}
+		p.next++
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	var and andExpr
+	for {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+		if token, ok := p.peek(); !ok || !token.isKeyword("and") {
+			break
+		}
+		p.next++
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *filterParser) parseUnary() (FilterExpr, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of filter expression")
+	}
+	p.next++
+
+	switch {
+	case token.isKeyword("not"):
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	case token.kind == tokenLeftParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != tokenRightParen {
+			return nil, fmt.Errorf("missing \")\" for \"(\" at position %d of filter expression", token.pos)
+		}
+		p.next++
+		return expr, nil
+	case token.kind == tokenWord && !token.isKeyword("and") && !token.isKeyword("or"):
+		kv := strings.SplitN(token.text, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("expected field=value at position %d of filter expression, got %s", token.pos, token)
+		}
+		return Term(Field(kv[0]), Value(kv[1])), nil
+	default:
+		return nil, fmt.Errorf("unexpected %s at position %d of filter expression", token, token.pos)
+	}
+}
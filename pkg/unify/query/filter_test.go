@@ -0,0 +1,117 @@
+package query
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestParseFilterExpr(t *testing.T) {
+	values := map[Field][]Value{
+		"status": {"Running"},
+		"label":  {"app=web", "tier=frontend"},
+		"name":   {"my app"},
+	}
+	match := func(filter Filter) bool {
+		for _, value := range values[filter.Field] {
+			if value == filter.Value {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: "status=Running", expected: true},
+		{expr: "(status=Running or status=Pending) and label=app=web", expected: true},
+		{expr: "status=Pending or status=Failed", expected: false},
+		{expr: "status=Pending or status=Running and label=app=db", expected: false},
+		{expr: "not status=Pending and label=tier=frontend", expected: true},
+		{expr: "NOT (status=Running OR status=Pending)", expected: false},
+		{expr: `name="my app"`, expected: true},
+		{expr: `name="(my app)"`, expected: false},
+	}
+	for _, test := range tests {
+		expr, err := ParseFilterExpr(test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		if got := expr.Eval(match); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.expr, test.expected, got)
+		}
+
+		// expressions are printed in a form parsed into the same expression
+		reparsed, err := ParseFilterExpr(expr.String())
+		if err != nil {
+			t.Errorf("%s: parse %s failed, %v", test.expr, expr.String(), err)
+			continue
+		}
+		if reparsed.String() != expr.String() {
+			t.Errorf("%s: expected %s, got %s", test.expr, expr.String(), reparsed.String())
+		}
+	}
+
+	for _, malformed := range []string{"", "status", "=Running", "status=Running and", "(status=Running", "status=Running)", "and status=Running", `name="my app`, "not"} {
+		if _, err := ParseFilterExpr(malformed); err == nil {
+			t.Errorf("%s: expected error", malformed)
+		}
+	}
+}
+
+func TestParseQueryParameterFilters(t *testing.T) {
+	newRequest := func(query url.Values) *restful.Request {
+		return restful.NewRequest(&http.Request{URL: &url.URL{RawQuery: query.Encode()}})
+	}
+
+	q, err := ParseQueryParameter(newRequest(url.Values{
+		"status": {"Running"},
+		"label":  {"app=web", "tier=frontend"},
+		"filter": {"not name=nginx"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Filters) != 1 || q.Filters["status"] != "Running" {
+		t.Errorf("unexpected filters %v", q.Filters)
+	}
+
+	matched := map[Filter]bool{
+		{Field: "status", Value: "Running"}:      true,
+		{Field: "label", Value: "app=web"}:       true,
+		{Field: "label", Value: "tier=frontend"}: true,
+	}
+	if !q.MatchFilters(func(filter Filter) bool { return matched[filter] }) {
+		t.Errorf("expected all filters matched")
+	}
+	delete(matched, Filter{Field: "label", Value: "tier=frontend"})
+	if q.MatchFilters(func(filter Filter) bool { return matched[filter] }) {
+		t.Errorf("expected repeated label filters ANDed")
+	}
+
+	if _, err := ParseQueryParameter(newRequest(url.Values{"filter": {"(status=Running"}})); err == nil {
+		t.Errorf("expected error of malformed filter")
+	}
+	if _, err := ParseQueryParameter(newRequest(url.Values{ParameterFieldSelector: {"status.phase=Running,spec.nodeName"}})); err == nil {
+		t.Errorf("expected error of malformed field selector")
+	}
+
+	expired := &ContinueToken{SortBy: FieldCreationTimeStamp, Issued: time.Now().Add(-ContinueTokenTTL - time.Minute).Unix()}
+	if _, err := ParseQueryParameter(newRequest(url.Values{ParameterContinue: {expired.Encode()}})); !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected expired continue token, got %v", err)
+	}
+
+	// decompressed tokens are limited, tokens of repeated bytes compress well
+	large := &ContinueToken{SortBy: FieldCreationTimeStamp, Issued: time.Now().Unix(), Name: strings.Repeat("a", maxContinueTokenSize)}
+	if _, err := ParseQueryParameter(newRequest(url.Values{ParameterContinue: {large.Encode()}})); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request of large continue token, got %v", err)
+	}
+}
@@ -4,16 +4,34 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"captain/pkg/utils/base"
 
 	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 type Field string
 type Value string
 
+// IsJSONPath returns true if field is a JSONPath such as .spec.replicas or {.spec.replicas}, lists
+// can be sorted by values of any JSONPath
+func (f Field) IsJSONPath() bool {
+	return strings.HasPrefix(string(f), ".") || strings.HasPrefix(string(f), "{")
+}
+
+// JSONPathTemplate returns JSONPath of field in template form, e.g. {.spec.replicas}
+func (f Field) JSONPathTemplate() string {
+	if strings.HasPrefix(string(f), "{") {
+		return string(f)
+	}
+	return "{" + string(f) + "}"
+}
+
 const (
 	FieldName                = "name"
 	FieldNames               = "names"
@@ -41,31 +59,83 @@ const (
 	ParameterLabelSelector = "labelSelector"
 	ParameterFieldSelector = "fieldSelector"
 	ParameterPage          = "page"
-	// ParameterLimit         = "limit"
-	ParameterPageSize  = "pageSize"
-	ParameterOrderBy   = "sortBy"
-	ParameterAscending = "ascending"
+	ParameterPageSize      = "pageSize"
+	ParameterOrderBy       = "sortBy"
+	ParameterAscending     = "ascending"
+
+	// ParameterClusterSelector selects clusters by labels in fleet requests
+	ParameterClusterSelector = "clusterSelector"
+
+	// ParameterTimeoutSeconds limits duration of watch requests
+	ParameterTimeoutSeconds = "timeoutSeconds"
+
+	// ParameterFilter is a boolean expression of filters, see ParseFilterExpr
+	ParameterFilter = "filter"
+
+	// ParameterLimit and ParameterContinue list in cursor mode instead of page mode
+	ParameterLimit    = "limit"
+	ParameterContinue = "continue"
+
+	// ParameterFields projects listed items to comma separated JSONPath fields, e.g. metadata.name,status.phase
+	ParameterFields = "fields"
+
+	// ParameterGroupBy and ParameterSumRequests are comma separated fields items are aggregated by,
+	// and whether to sum resource requests of aggregated items
+	ParameterGroupBy     = "groupBy"
+	ParameterSumRequests = "sumRequests"
+
+	// ParameterSearch and ParameterKinds are search text and comma separated resource types to search
+	ParameterSearch = "q"
+	ParameterKinds  = "kinds"
+
+	// ParameterFormat exports lists as json, csv, yaml or ndjson, it takes precedence over Accept header
+	ParameterFormat = "format"
 )
 
 // Query represents api search terms
 type QueryInfo struct {
 	Pagination *Pagination
 
+	// Limit is the max number of items returned in cursor mode, list is in cursor mode if
+	// either Limit or Continue is set, Pagination is ignored then
+	Limit int
+
+	// Continue is where the page starts in cursor mode, nil for the first page
+	Continue *ContinueToken
+
 	// sort result in which field, default to FieldCreationTimeStamp
 	SortBy Field
 
 	// sort result in ascending or descending order, default to descending
 	Ascending bool
 
-	//
+	// Filters are ANDed, each key holds a single value
 	Filters map[Field]Value
 
+	// FilterExpr is ANDed with Filters, nil if there is no expression. Repeated filter query
+	// parameters and the filter query parameter are parsed into it.
+	FilterExpr FilterExpr
+
 	LabelSelector string
+
+	// FieldSelector selects objects by fields such as status.phase=Running, supports =, == and !=.
+	// It's applied by captain rather than apiservers, which support only a few fields of each kind
+	FieldSelector string
 }
 
 func (q *QueryInfo) String() string {
-	return fmt.Sprintf("Incoming Query info %T \n Pagination: { Page: %d, PageSize: %d} \n SortBy: %s, Ascending: %v, \n Filters: %+v \n ",
-		q, q.Pagination.Page, q.Pagination.PageSize, q.SortBy, q.Ascending, q.Filters)
+	return fmt.Sprintf("Incoming Query info %T \n Pagination: { Page: %d, PageSize: %d} \n SortBy: %s, Ascending: %v, \n Filters: %+v, FilterExpr: %v \n ",
+		q, q.Pagination.Page, q.Pagination.PageSize, q.SortBy, q.Ascending, q.Filters, q.FilterExpr)
+}
+
+// MatchFilters returns true if all filters and filter expression are matched
+func (q *QueryInfo) MatchFilters(match func(Filter) bool) bool {
+	for k, v := range q.Filters {
+		if !match(Filter{Field: k, Value: v}) {
+			return false
+		}
+	}
+	return q.FilterExpr == nil || q.FilterExpr.Eval(match)
 }
 
 type Pagination struct {
@@ -98,8 +168,30 @@ func (q *QueryInfo) GetSelector() labels.Selector {
 	return selector
 }
 
+// GetFieldSelector returns selector of fields, an error is returned if selector is malformed
+func (q *QueryInfo) GetFieldSelector() (fields.Selector, error) {
+	return fields.ParseSelector(q.FieldSelector)
+}
+
 var DefaultPagination = newPagination(1, 10)
 
+// NoPagination returns all items in one page
+var NoPagination = newPagination(1, math.MaxInt32)
+
+// WithoutPagination returns a copy of query which returns all items in one page
+func (q *QueryInfo) WithoutPagination() *QueryInfo {
+	copied := *q
+	copied.Pagination = NoPagination
+	copied.Limit = 0
+	copied.Continue = nil
+	return &copied
+}
+
+// CursorMode returns true if list is paginated by limit and continue token
+func (q *QueryInfo) CursorMode() bool {
+	return q.Limit > 0 || q.Continue != nil
+}
+
 func newPagination(page, pageSize int) *Pagination {
 	// handling invalid number
 	if page <= 0 {
@@ -136,7 +228,17 @@ func (p *Pagination) GetValidPagination(total int) (startIndex, endIndex int) {
 	return startIndex, endIndex
 }
 
-func ParseQueryParameter(request *restful.Request) *QueryInfo {
+// ParseQueryParameter parses query from request, errors are API statuses, which are bad requests if
+// parameters are malformed, or expired if the continue token is too old to resume from
+func ParseQueryParameter(request *restful.Request) (*QueryInfo, error) {
+	query, err := parseQueryParameter(request)
+	if err != nil && !apierrors.IsResourceExpired(err) {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	return query, err
+}
+
+func parseQueryParameter(request *restful.Request) (*QueryInfo, error) {
 	query := New()
 
 	pageSize, err := strconv.Atoi(request.QueryParameter(ParameterPageSize))
@@ -153,6 +255,11 @@ func ParseQueryParameter(request *restful.Request) *QueryInfo {
 	query.Pagination = newPagination(page, pageSize)
 
 	query.SortBy = Field(defaultString(request.QueryParameter(ParameterOrderBy), FieldCreationTimeStamp))
+	if query.SortBy.IsJSONPath() {
+		if err := jsonpath.New(string(query.SortBy)).Parse(query.SortBy.JSONPathTemplate()); err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %v", ParameterOrderBy, query.SortBy, err)
+		}
+	}
 
 	ascending, err := strconv.ParseBool(defaultString(request.QueryParameter(ParameterAscending), "false"))
 	if err != nil {
@@ -161,18 +268,60 @@ func ParseQueryParameter(request *restful.Request) *QueryInfo {
 		query.Ascending = ascending
 	}
 
+	if value := request.QueryParameter(ParameterLimit); len(value) != 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid %s %s", ParameterLimit, value)
+		}
+		query.Limit = limit
+	}
+
+	if value := request.QueryParameter(ParameterContinue); len(value) != 0 {
+		token, err := DecodeContinueToken(value)
+		if err != nil {
+			return nil, err
+		}
+		// items are located by sort keys, which differ across orders
+		if token.SortBy != query.SortBy || token.Ascending != query.Ascending {
+			return nil, fmt.Errorf("continue token was issued for a list sorted by %s in different order", token.SortBy)
+		}
+		// objects listed later can't be resumed consistently with the first page
+		if token.Expired() {
+			return nil, apierrors.NewResourceExpired("continue token is expired, list from the first page again")
+		}
+		query.Continue = token
+	}
+
 	query.LabelSelector = request.QueryParameter(ParameterLabelSelector)
+	query.FieldSelector = request.QueryParameter(ParameterFieldSelector)
+	if _, err := query.GetFieldSelector(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", ParameterFieldSelector, err)
+	}
 
+	var exprs []FilterExpr
 	for key, values := range request.Request.URL.Query() {
-		if !base.HasString([]string{ParameterPage, ParameterPageSize, ParameterOrderBy, ParameterAscending, ParameterLabelSelector}, key) {
-			// support multiple query condition
+		if !base.HasString([]string{ParameterPage, ParameterPageSize, ParameterOrderBy, ParameterAscending, ParameterLabelSelector, ParameterFieldSelector, ParameterClusterSelector, ParameterTimeoutSeconds, ParameterFilter, ParameterLimit, ParameterContinue, ParameterFields, ParameterGroupBy, ParameterSumRequests, ParameterSearch, ParameterKinds, ParameterFormat}, key) {
+			// support multiple query condition, all values of repeated keys are ANDed
+			if len(values) == 1 {
+				query.Filters[Field(key)] = Value(values[0])
+				continue
+			}
 			for _, value := range values {
-				query.Filters[Field(key)] = Value(value)
+				exprs = append(exprs, Term(Field(key), Value(value)))
 			}
 		}
 	}
 
-	return query
+	if filter := request.QueryParameter(ParameterFilter); len(filter) != 0 {
+		expr, err := ParseFilterExpr(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", ParameterFilter, err)
+		}
+		exprs = append(exprs, expr)
+	}
+	query.FilterExpr = And(exprs...)
+
+	return query, nil
 }
 
 func defaultString(value, defaultValue string) string {
@@ -1,5 +1,10 @@
 package response
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 //ListResult ... data format in listing requst
 type ListResult struct {
 	Items       []interface{} `json:"items"`
@@ -7,4 +12,187 @@ type ListResult struct {
 	PageSize    int           `json:"pageSize"`
 	TotalPages  int           `json:"totalPages"`
 	CurrentPage int           `json:"currentPage"`
+
+	// Continue is set in cursor mode if there are more items, pass it to get the next page
+	Continue string `json:"continue,omitempty"`
+	// ResourceVersion is the newest resource version of listed objects in cursor mode
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// FleetListResult ... data format in listing request across clusters
+type FleetListResult struct {
+	ListResult
+
+	// Errors of clusters failed to list, items of other clusters are still returned
+	Errors []ClusterError `json:"errors,omitempty"`
+}
+
+// ClusterError ... error of a single cluster in fleet requests
+type ClusterError struct {
+	Region  string `json:"region"`
+	Cluster string `json:"cluster"`
+	// Resource is set if the error is of a single resource type, e.g. in search requests
+	Resource string `json:"resource,omitempty"`
+	Error    string `json:"error"`
+	// Reason is reason of the error the same as in Status, e.g. NotFound and ServiceUnavailable
+	Reason metav1.StatusReason `json:"reason,omitempty"`
+}
+
+// TableResult ... data format in listing request rendered as table, compatible with Table of
+// kube-apiserver, pagination fields are the same as ListResult
+type TableResult struct {
+	metav1.TypeMeta `json:",inline"`
+
+	ColumnDefinitions []metav1.TableColumnDefinition `json:"columnDefinitions"`
+	// Rows are in the same order as items of list, object of each row is metadata of the item
+	Rows []metav1.TableRow `json:"rows"`
+
+	Total           int    `json:"totalItems"`
+	PageSize        int    `json:"pageSize"`
+	TotalPages      int    `json:"totalPages"`
+	CurrentPage     int    `json:"currentPage"`
+	Continue        string `json:"continue,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// FleetTableResult ... data format in listing request across clusters rendered as table
+type FleetTableResult struct {
+	TableResult
+
+	Errors []ClusterError `json:"errors,omitempty"`
+}
+
+// AggregateResult ... data format in aggregating request, items are counted by values of groupBy fields
+type AggregateResult struct {
+	GroupBy []string         `json:"groupBy"`
+	Total   int              `json:"totalItems"`
+	Groups  []AggregateGroup `json:"groups"`
+}
+
+// AggregateGroup ... items of the same values of groupBy fields
+type AggregateGroup struct {
+	// Keys are values of groupBy fields, keyed by fields
+	Keys  map[string]string `json:"keys"`
+	Count int               `json:"count"`
+	// Requests are summed cpu and memory requests of pods of items, set only if requested
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+}
+
+// FleetAggregateResult ... data format in aggregating request across clusters
+type FleetAggregateResult struct {
+	AggregateResult
+
+	Errors []ClusterError `json:"errors,omitempty"`
+}
+
+// SearchResult ... data format in search request, hits are sorted by score in descending order
+type SearchResult struct {
+	Items       []SearchHit `json:"items"`
+	Total       int         `json:"totalItems"`
+	PageSize    int         `json:"pageSize"`
+	TotalPages  int         `json:"totalPages"`
+	CurrentPage int         `json:"currentPage"`
+
+	// Errors of clusters or resources failed to search, hits of the others are still returned
+	Errors []ClusterError `json:"errors,omitempty"`
+}
+
+// SearchHit ... an object matching search terms
+type SearchHit struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Resource is the resource type the object is listed as, e.g. pods
+	Resource          string            `json:"resource"`
+	Region            string            `json:"region,omitempty"`
+	Cluster           string            `json:"cluster,omitempty"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Name              string            `json:"name"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	CreationTimestamp metav1.Time       `json:"creationTimestamp"`
+
+	Score int `json:"score"`
+	// Matches are fields matching search terms, e.g. name, label:app
+	Matches []string `json:"matches"`
+}
+
+// RevisionHistory ... revisions of a workload in ascending order of revision numbers
+type RevisionHistory struct {
+	Items []Revision `json:"items"`
+}
+
+// Revision ... a revision of a workload, built from ReplicaSets of Deployments or ControllerRevisions of
+// StatefulSets and DaemonSets
+type Revision struct {
+	Revision int64 `json:"revision"`
+	// Name is name of the ReplicaSet or ControllerRevision
+	Name              string      `json:"name"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	// ChangeCause is annotation kubernetes.io/change-cause of the revision
+	ChangeCause string `json:"changeCause,omitempty"`
+	// Current is true if the workload is updated to the revision
+	Current  bool                   `json:"current"`
+	Template corev1.PodTemplateSpec `json:"template"`
+	// Diff is unified diff of template in yaml from the previous revision, empty for the first revision
+	Diff string `json:"diff,omitempty"`
+}
+
+// NodeDrain ... progress of draining a node, pods on the node are evicted except DaemonSet and mirror pods
+type NodeDrain struct {
+	Region  string `json:"region,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+	Node    string `json:"node"`
+	// Phase is one of Running, Succeeded, Failed and Cancelled
+	Phase          string       `json:"phase"`
+	Message        string       `json:"message,omitempty"`
+	StartTime      metav1.Time  `json:"startTime"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Total is the number of pods to evict, Evicted is the number of pods evicted and deleted
+	Total   int `json:"total"`
+	Evicted int `json:"evicted"`
+	// Pods are pods on the node, including those skipped
+	Pods []DrainPod `json:"pods"`
+}
+
+// DrainPod ... state of a pod in draining a node
+type DrainPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Phase is one of Pending, Evicting, Blocked, Evicted, Skipped and Failed, pods are blocked if
+	// evictions are disallowed by PodDisruptionBudgets
+	Phase   string `json:"phase"`
+	Message string `json:"message,omitempty"`
+}
+
+// JobRunHistory ... jobs of a cron job, newest first
+type JobRunHistory struct {
+	Items []JobRun `json:"items"`
+}
+
+// JobRun ... a run of a cron job
+type JobRun struct {
+	Name string `json:"name"`
+	// Manual is true if the job is triggered manually
+	Manual         bool         `json:"manual"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Duration is from start to completion or failure, or to now if the job is running
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Outcome is one of Running, Succeeded, Failed and Suspended
+	Outcome   string `json:"outcome"`
+	Message   string `json:"message,omitempty"`
+	Active    int32  `json:"active"`
+	Succeeded int32  `json:"succeeded"`
+	Failed    int32  `json:"failed"`
+	// Logs is path of logs of all pods of the job
+	Logs string      `json:"logs,omitempty"`
+	Pods []JobRunPod `json:"pods"`
+}
+
+// JobRunPod ... a pod of a job run
+type JobRunPod struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	// Logs is path of logs of the pod
+	Logs string `json:"logs,omitempty"`
 }
@@ -1,31 +1,70 @@
 package clusterclient
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
 	clusterinformer "captain/pkg/client/informers/externalversions/cluster/v1alpha1"
 	"captain/pkg/simple/client/multicluster"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
 	"k8s.io/klog"
 )
 
 var (
 	ClusterNotExistsFormat = "cluster %s not exists"
+
+	ErrClusterCacheDisabled = errors.New("informer cache of member clusters is disabled")
+)
+
+const (
+	// clusterMaxIdleConnsPerHost is the number of idle connections kept to apiserver of each cluster
+	clusterMaxIdleConnsPerHost = 25
+	// clusterIdleConnTimeout is how long idle connections to apiserver of each cluster are kept
+	clusterIdleConnTimeout = 90 * time.Second
 )
 
+// newClusterNotFound returns NotFound error of cluster, so that it's responded with 404
+func newClusterNotFound(clusterName string) error {
+	err := apierrors.NewNotFound(clusterv1alpha1.Resource(clusterv1alpha1.ResourcesPluralCluster), clusterName)
+	err.ErrStatus.Message = fmt.Sprintf(ClusterNotExistsFormat, clusterName)
+	return err
+}
+
 type innerCluster struct {
 	KubernetesURL *url.URL
 	CaptainURL    *url.URL
 	Transport     http.RoundTripper
+
+	// transport is the connection pool under Transport owned by this cluster only, clients share
+	// Transport, they are rebuilt only when kubeconfig or endpoints change
+	transport     *http.Transport
+	kubeconfig    []byte
+	config        *rest.Config
+	clientSet     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	// restMapper discovers resources served by the cluster on first use, and again when
+	// a resource can't be found
+	restMapper meta.RESTMapper
 }
 
 type ClusterClients interface {
@@ -36,6 +75,13 @@ type ClusterClients interface {
 	GetByClusterName(clustername string) (*clusterv1alpha1.Cluster, error)
 	GetInnerCluster(string) *innerCluster
 	GetClientSet(string, string) (*kubernetes.Clientset, error)
+	GetConfig(string, string) (*rest.Config, error)
+	GetDynamicClient(string, string) (dynamic.Interface, error)
+	GetRESTMapper(string, string) (meta.RESTMapper, error)
+	List(selector labels.Selector) []*clusterv1alpha1.Cluster
+	GetRegionAndName(cluster *clusterv1alpha1.Cluster) (string, string)
+
+	ClusterInformers
 }
 
 type clusterClients struct {
@@ -46,6 +92,9 @@ type clusterClients struct {
 	// build a in memory cluster cache to speed things up
 	innerClusters map[string]*innerCluster
 
+	// informers of member clusters, started on demand
+	informers *clusterInformers
+
 	options *multicluster.Options
 }
 
@@ -71,7 +120,7 @@ func (c *clusterClients) GetClusterKubeconfig(clusterName string) (string, error
 	if c, exists := c.clusterKubeconfig[clusterName]; exists {
 		return c, nil
 	} else {
-		return "", fmt.Errorf(ClusterNotExistsFormat, clusterName)
+		return "", newClusterNotFound(clusterName)
 	}
 }
 
@@ -92,8 +141,37 @@ func (c *clusterClients) GetByClusterName(clusterName string) (*clusterv1alpha1.
 	if cluster, exists := c.clusterMap[clusterName]; exists {
 		return cluster, nil
 	} else {
-		return nil, fmt.Errorf(ClusterNotExistsFormat, clusterName)
+		return nil, newClusterNotFound(clusterName)
+	}
+}
+
+// List returns clusters matching selector, sorted by name
+func (c *clusterClients) List(selector labels.Selector) []*clusterv1alpha1.Cluster {
+	c.RLock()
+	defer c.RUnlock()
+	clusters := make([]*clusterv1alpha1.Cluster, 0, len(c.clusterMap))
+	for _, cluster := range c.clusterMap {
+		if selector.Matches(labels.Set(cluster.Labels)) {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+	return clusters
+}
+
+// GetRegionAndName returns region and name of cluster used in api paths, name of cluster
+// has a prefix of {region}- if it's placed in a region
+func (c *clusterClients) GetRegionAndName(cluster *clusterv1alpha1.Cluster) (string, string) {
+	if c.IsHostCluster(cluster) {
+		return c.options.HostRegionName, c.options.HostClusterName
+	}
+	region := cluster.Labels[clusterv1alpha1.ClusterRegion]
+	if len(region) == 0 {
+		return "", cluster.Name
 	}
+	return region, strings.TrimPrefix(cluster.Name, region+"-")
 }
 
 func (c *clusterClients) GetInnerCluster(name string) *innerCluster {
@@ -106,16 +184,49 @@ func (c *clusterClients) GetInnerCluster(name string) *innerCluster {
 }
 
 func (c *clusterClients) GetClientSet(regionName, clusterName string) (*kubernetes.Clientset, error) {
-	// TODO cache
-	cluster, err := c.Get(regionName, clusterName)
+	inner, err := c.getReadyInnerCluster(regionName, clusterName)
 	if err != nil {
 		return nil, err
 	}
-	r, err := clientcmd.RESTConfigFromKubeConfig([]byte(cluster.Spec.Connection.KubeConfig))
+	return inner.clientSet, nil
+}
+
+// GetConfig returns rest config of cluster, it's used by clients which can't share transport, like exec
+func (c *clusterClients) GetConfig(regionName, clusterName string) (*rest.Config, error) {
+	inner, err := c.getReadyInnerCluster(regionName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return rest.CopyConfig(inner.config), nil
+}
+
+func (c *clusterClients) GetDynamicClient(regionName, clusterName string) (dynamic.Interface, error) {
+	inner, err := c.getReadyInnerCluster(regionName, clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("get cluster kubeconfig restconfig err: %v", err)
+		return nil, err
 	}
-	return kubernetes.NewForConfig(r)
+	return inner.dynamicClient, nil
+}
+
+func (c *clusterClients) GetRESTMapper(regionName, clusterName string) (meta.RESTMapper, error) {
+	inner, err := c.getReadyInnerCluster(regionName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return inner.restMapper, nil
+}
+
+func (c *clusterClients) getReadyInnerCluster(regionName, clusterName string) (*innerCluster, error) {
+	cluster, err := c.Get(regionName, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	inner := c.GetInnerCluster(cluster.Name)
+	if inner == nil {
+		return nil, apierrors.NewServiceUnavailable(fmt.Sprintf("clients of cluster %s are not ready, check kubeconfig of the cluster", cluster.Name))
+	}
+	return inner, nil
 }
 
 var c *clusterClients
@@ -135,6 +246,7 @@ func NewClusterClients(clusterInformer clusterinformer.ClusterInformer, options
 			clusterMap:        map[string]*clusterv1alpha1.Cluster{},
 			clusterKubeconfig: map[string]string{},
 			innerClusters:     make(map[string]*innerCluster),
+			informers:         newClusterInformers(options.ClusterCacheIdleTimeout),
 			options:           options,
 		}
 
@@ -143,7 +255,7 @@ func NewClusterClients(clusterInformer clusterinformer.ClusterInformer, options
 				c.addCluster(obj)
 			},
 			UpdateFunc: func(oldObj, newObj interface{}) {
-				c.removeCluster(oldObj)
+				// clients are kept unless connection changes, status updates happen all the time
 				c.addCluster(newObj)
 			},
 			DeleteFunc: func(obj interface{}) {
@@ -156,15 +268,29 @@ func NewClusterClients(clusterInformer clusterinformer.ClusterInformer, options
 }
 
 func (c *clusterClients) removeCluster(obj interface{}) {
-	cluster := obj.(*clusterv1alpha1.Cluster)
+	cluster, ok := obj.(*clusterv1alpha1.Cluster)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if cluster, ok = tombstone.Obj.(*clusterv1alpha1.Cluster); !ok {
+			return
+		}
+	}
 	klog.V(4).Infof("remove cluster %s", cluster.Name)
 	c.Lock()
+	inner := c.innerClusters[cluster.Name]
 	if _, ok := c.clusterMap[cluster.Name]; ok {
 		delete(c.clusterMap, cluster.Name)
 		delete(c.innerClusters, cluster.Name)
 		delete(c.clusterKubeconfig, cluster.Name)
 	}
 	c.Unlock()
+	c.informers.evict(cluster.Name)
+	if inner != nil {
+		inner.closeIdleConnections()
+	}
 }
 
 func (c *clusterClients) addCluster(obj interface{}) {
@@ -176,15 +302,45 @@ func (c *clusterClients) addCluster(obj interface{}) {
 		return
 	}
 
-	innerCluster := newInnerCluster(cluster)
+	// reuse cached clients if connection of cluster doesn't change
+	inner := c.GetInnerCluster(cluster.Name)
+	if inner == nil || !inner.connectedBy(cluster) {
+		inner = newInnerCluster(cluster, c.options)
+		// informers are watching through the old clients
+		c.informers.evict(cluster.Name)
+	}
+
 	c.Lock()
+	replaced := c.innerClusters[cluster.Name]
 	c.clusterMap[cluster.Name] = cluster
 	c.clusterKubeconfig[cluster.Name] = string(cluster.Spec.Connection.KubeConfig)
-	c.innerClusters[cluster.Name] = innerCluster
+	if inner != nil {
+		c.innerClusters[cluster.Name] = inner
+	} else {
+		delete(c.innerClusters, cluster.Name)
+	}
 	c.Unlock()
+
+	if replaced != nil && replaced != inner {
+		replaced.closeIdleConnections()
+	}
+}
+
+// closeIdleConnections closes idle connections of transport replaced or removed, connections still
+// in use are left to the idle timeout of transport
+func (i *innerCluster) closeIdleConnections() {
+	i.transport.CloseIdleConnections()
 }
 
-func newInnerCluster(cluster *clusterv1alpha1.Cluster) *innerCluster {
+// connectedBy returns true if inner cluster is built from the connection of cluster
+func (i *innerCluster) connectedBy(cluster *clusterv1alpha1.Cluster) bool {
+	connection := cluster.Spec.Connection
+	return bytes.Equal(i.kubeconfig, connection.KubeConfig) &&
+		i.CaptainURL.String() == connection.CaptainAPIEndpoint &&
+		(len(connection.KubernetesAPIEndpoint) == 0 || i.KubernetesURL.String() == connection.KubernetesAPIEndpoint)
+}
+
+func newInnerCluster(cluster *clusterv1alpha1.Cluster, options *multicluster.Options) *innerCluster {
 	kubernetesEndpoint, err := url.Parse(cluster.Spec.Connection.KubernetesAPIEndpoint)
 	if err != nil {
 		klog.Errorf("Parse kubernetes apiserver endpoint %s failed, %v", cluster.Spec.Connection.KubernetesAPIEndpoint, err)
@@ -214,15 +370,81 @@ func newInnerCluster(cluster *clusterv1alpha1.Cluster) *innerCluster {
 		kubernetesEndpoint, _ = url.Parse(clusterConfig.Host)
 	}
 
-	transport, err := rest.TransportFor(clusterConfig)
+	clusterConfig.QPS = options.ClusterClientQPS
+	clusterConfig.Burst = options.ClusterClientBurst
+
+	// transport is shared by proxy, clientset and dynamic client, connections
+	// to the cluster are reused across requests
+	base, transport, err := newClusterTransport(clusterConfig)
 	if err != nil {
 		klog.Errorf("Create transport failed, %v", err)
 		return nil
 	}
+	httpClient := &http.Client{Transport: transport, Timeout: clusterConfig.Timeout}
+
+	clientSet, err := kubernetes.NewForConfigAndClient(clusterConfig, httpClient)
+	if err != nil {
+		klog.Errorf("Create clientset of cluster %s failed, %v", cluster.Name, err)
+		return nil
+	}
+
+	dynamicClient, err := dynamic.NewForConfigAndClient(clusterConfig, httpClient)
+	if err != nil {
+		klog.Errorf("Create dynamic client of cluster %s failed, %v", cluster.Name, err)
+		return nil
+	}
 
 	return &innerCluster{
 		KubernetesURL: kubernetesEndpoint,
 		CaptainURL:    captainEndpoint,
 		Transport:     transport,
+		transport:     base,
+		kubeconfig:    cluster.Spec.Connection.KubeConfig,
+		config:        clusterConfig,
+		clientSet:     clientSet,
+		dynamicClient: dynamicClient,
+		restMapper:    NewRESTMapper(clientSet.Discovery()),
+	}
+}
+
+// newClusterTransport builds transport of cluster from config, unlike rest.TransportFor the connection
+// pool isn't cached by client-go and shared with other clusters of the same TLS config, so it can be
+// closed once the cluster is replaced or removed. The pool is returned with the round tripper wrapping
+// it with authentication of config.
+func newClusterTransport(config *rest.Config) (*http.Transport, http.RoundTripper, error) {
+	transportConfig, err := config.TransportConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig, err := transport.TLSConfigFor(transportConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dial := transportConfig.Dial
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	proxy := http.ProxyFromEnvironment
+	if transportConfig.Proxy != nil {
+		proxy = transportConfig.Proxy
+	}
+
+	base := utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               proxy,
+		DialContext:         dial,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConnsPerHost: clusterMaxIdleConnsPerHost,
+		IdleConnTimeout:     clusterIdleConnTimeout,
+		DisableCompression:  transportConfig.DisableCompression,
+	})
+	rt, err := transport.HTTPWrappersForConfig(transportConfig, base)
+	if err != nil {
+		return nil, nil, err
 	}
+	return base, rt, nil
 }
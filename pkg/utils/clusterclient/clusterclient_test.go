@@ -0,0 +1,110 @@
+package clusterclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	clusterv1alpha1 "captain/apis/cluster/v1alpha1"
+	"captain/pkg/simple/client/multicluster"
+)
+
+func TestAddClusterReload(t *testing.T) {
+	closed := make(chan struct{}, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[]}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	newCluster := func(token string) *clusterv1alpha1.Cluster {
+		config := clientcmdapi.NewConfig()
+		config.Clusters["c1"] = &clientcmdapi.Cluster{Server: server.URL}
+		config.AuthInfos["c1"] = &clientcmdapi.AuthInfo{Token: token}
+		config.Contexts["c1"] = &clientcmdapi.Context{Cluster: "c1", AuthInfo: "c1"}
+		config.CurrentContext = "c1"
+		kubeconfig, err := clientcmd.Write(*config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &clusterv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+			Spec: clusterv1alpha1.ClusterSpec{Connection: clusterv1alpha1.Connection{
+				CaptainAPIEndpoint: "http://captain.c1:9090",
+				KubeConfig:         kubeconfig,
+			}},
+		}
+	}
+	c := &clusterClients{
+		clusterMap:        map[string]*clusterv1alpha1.Cluster{},
+		clusterKubeconfig: map[string]string{},
+		innerClusters:     make(map[string]*innerCluster),
+		informers:         newClusterInformers(0),
+		options:           multicluster.NewOptions(),
+	}
+
+	cluster := newCluster("a")
+	c.addCluster(cluster)
+	inner := c.GetInnerCluster("c1")
+	if inner == nil {
+		t.Fatal("expected clients of cluster built")
+	}
+	if _, err := inner.clientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// connection pools aren't shared with other clusters of the same config, which are left open
+	// when the cluster is replaced or removed
+	other := newCluster("a")
+	other.Name = "c2"
+	c.addCluster(other)
+	if inner.transport == c.GetInnerCluster("c2").transport {
+		t.Fatal("expected transport owned by each cluster")
+	}
+
+	// clients are kept if connection doesn't change
+	updated := cluster.DeepCopy()
+	updated.Status.KubernetesVersion = "v1.24.3"
+	c.addCluster(updated)
+	if c.GetInnerCluster("c1") != inner {
+		t.Fatal("expected clients reused")
+	}
+
+	// clients are rebuilt once credentials change, idle connections of the old transport are closed
+	c.addCluster(newCluster("b"))
+	if c.GetInnerCluster("c1") == inner {
+		t.Fatal("expected clients rebuilt")
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected idle connection closed")
+	}
+
+	// so are connections of removed clusters
+	if _, err := c.GetInnerCluster("c1").clientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c.removeCluster(cluster)
+	if c.GetInnerCluster("c1") != nil {
+		t.Fatal("expected clients removed")
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected idle connection closed")
+	}
+}
@@ -0,0 +1,168 @@
+package clusterclient
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// evictionPeriod is how often idle informer factories are checked
+const evictionPeriod = time.Minute
+
+// ClusterInformers maintains shared informer factories of member clusters, a factory is created
+// on first use of the cluster, informers are started on first use of the resource, and stopped
+// when the cluster is idle for a while or its connection changes.
+type ClusterInformers interface {
+	// InformerFactory returns the shared informer factory of cluster, informers must be registered
+	// before CacheSynced is called
+	InformerFactory(region, cluster string) (informers.SharedInformerFactory, error)
+
+	// CacheSynced starts informer of resource registered in factory of cluster, returns true if the
+	// informer has synced. Callers should read from apiserver of cluster until it returns true.
+	CacheSynced(region, cluster, resource string, informer cache.SharedIndexInformer) bool
+
+	// CacheStatus returns sync status of clusters with informers running
+	CacheStatus() []ClusterCacheStatus
+}
+
+// ClusterCacheStatus is the sync status of informers of a member cluster
+type ClusterCacheStatus struct {
+	Region         string          `json:"region"`
+	Cluster        string          `json:"cluster"`
+	StartTime      metav1.Time     `json:"startTime"`
+	LastAccessTime metav1.Time     `json:"lastAccessTime"`
+	Resources      map[string]bool `json:"resources"`
+}
+
+type clusterInformerFactory struct {
+	region  string
+	cluster string
+	factory informers.SharedInformerFactory
+	stopCh  chan struct{}
+
+	// informers started by factory, keyed by resource name
+	informers  map[string]cache.SharedIndexInformer
+	startTime  time.Time
+	lastAccess time.Time
+}
+
+type clusterInformers struct {
+	sync.Mutex
+	factories map[string]*clusterInformerFactory
+}
+
+func newClusterInformers(idleTimeout time.Duration) *clusterInformers {
+	ci := &clusterInformers{factories: make(map[string]*clusterInformerFactory)}
+	if idleTimeout > 0 {
+		go wait.Forever(func() { ci.evictIdle(idleTimeout) }, evictionPeriod)
+	}
+	return ci
+}
+
+func (c *clusterClients) InformerFactory(region, cluster string) (informers.SharedInformerFactory, error) {
+	if !c.options.ClusterCacheEnabled {
+		return nil, ErrClusterCacheDisabled
+	}
+
+	cls, err := c.Get(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// lookup the cached clients before taking lock of informers, they are evicted together
+	inner, err := c.getReadyInnerCluster(region, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	c.informers.Lock()
+	defer c.informers.Unlock()
+
+	f, ok := c.informers.factories[cls.Name]
+	if !ok {
+		klog.V(4).Infof("create informer factory of cluster %s", cls.Name)
+		f = &clusterInformerFactory{
+			region:    region,
+			cluster:   cluster,
+			factory:   informers.NewSharedInformerFactory(inner.clientSet, 0),
+			stopCh:    make(chan struct{}),
+			informers: make(map[string]cache.SharedIndexInformer),
+			startTime: time.Now(),
+		}
+		c.informers.factories[cls.Name] = f
+	}
+	f.lastAccess = time.Now()
+	return f.factory, nil
+}
+
+func (c *clusterClients) CacheSynced(region, cluster, resource string, informer cache.SharedIndexInformer) bool {
+	cls, err := c.Get(region, cluster)
+	if err != nil {
+		return false
+	}
+
+	c.informers.Lock()
+	defer c.informers.Unlock()
+
+	f, ok := c.informers.factories[cls.Name]
+	if !ok {
+		return false
+	}
+	if _, ok := f.informers[resource]; !ok {
+		// Start only runs informers not started yet
+		f.factory.Start(f.stopCh)
+		f.informers[resource] = informer
+	}
+	return informer.HasSynced()
+}
+
+func (c *clusterClients) CacheStatus() []ClusterCacheStatus {
+	c.informers.Lock()
+	defer c.informers.Unlock()
+
+	status := make([]ClusterCacheStatus, 0, len(c.informers.factories))
+	for _, f := range c.informers.factories {
+		s := ClusterCacheStatus{
+			Region:         f.region,
+			Cluster:        f.cluster,
+			StartTime:      metav1.NewTime(f.startTime),
+			LastAccessTime: metav1.NewTime(f.lastAccess),
+			Resources:      make(map[string]bool, len(f.informers)),
+		}
+		for resource, informer := range f.informers {
+			s.Resources[resource] = informer.HasSynced()
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+// evict stops informers of cluster, they are recreated on next use
+func (ci *clusterInformers) evict(name string) {
+	ci.Lock()
+	defer ci.Unlock()
+
+	if f, ok := ci.factories[name]; ok {
+		klog.V(4).Infof("stop informers of cluster %s", name)
+		close(f.stopCh)
+		delete(ci.factories, name)
+	}
+}
+
+func (ci *clusterInformers) evictIdle(idleTimeout time.Duration) {
+	ci.Lock()
+	defer ci.Unlock()
+
+	for name, f := range ci.factories {
+		if time.Since(f.lastAccess) > idleTimeout {
+			klog.V(4).Infof("stop informers of idle cluster %s", name)
+			close(f.stopCh)
+			delete(ci.factories, name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package clusterclient
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// restMapperResetInterval limits how often resources are discovered again, requests to resources
+// not served don't trigger discovery each time
+const restMapperResetInterval = 30 * time.Second
+
+// resettingRESTMapper discovers resources served by a cluster on first use, and again when a resource
+// can't be found, so resources served later like new CRDs are found. DeferredDiscoveryRESTMapper only
+// discovers again until the cache is populated.
+type resettingRESTMapper struct {
+	*restmapper.DeferredDiscoveryRESTMapper
+
+	interval  time.Duration
+	lock      sync.Mutex
+	lastReset time.Time
+}
+
+// NewRESTMapper returns mapper of resources discovered by client
+func NewRESTMapper(client discovery.DiscoveryInterface) meta.RESTMapper {
+	return &resettingRESTMapper{
+		DeferredDiscoveryRESTMapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client)),
+		interval:                    restMapperResetInterval,
+	}
+}
+
+// reset resets mapper if err tells that a resource can't be found and mapper wasn't reset in the last
+// interval, it returns true if mapper is reset
+func (m *resettingRESTMapper) reset(err error) bool {
+	if !meta.IsNoMatchError(err) {
+		return false
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if time.Since(m.lastReset) < m.interval {
+		return false
+	}
+	m.lastReset = time.Now()
+	m.DeferredDiscoveryRESTMapper.Reset()
+	return true
+}
+
+func (m *resettingRESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, err := m.DeferredDiscoveryRESTMapper.KindFor(resource)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.KindFor(resource)
+	}
+	return gvk, err
+}
+
+func (m *resettingRESTMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	gvks, err := m.DeferredDiscoveryRESTMapper.KindsFor(resource)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.KindsFor(resource)
+	}
+	return gvks, err
+}
+
+func (m *resettingRESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	gvr, err := m.DeferredDiscoveryRESTMapper.ResourceFor(input)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.ResourceFor(input)
+	}
+	return gvr, err
+}
+
+func (m *resettingRESTMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	gvrs, err := m.DeferredDiscoveryRESTMapper.ResourcesFor(input)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.ResourcesFor(input)
+	}
+	return gvrs, err
+}
+
+func (m *resettingRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	mapping, err := m.DeferredDiscoveryRESTMapper.RESTMapping(gk, versions...)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.RESTMapping(gk, versions...)
+	}
+	return mapping, err
+}
+
+func (m *resettingRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	mappings, err := m.DeferredDiscoveryRESTMapper.RESTMappings(gk, versions...)
+	if m.reset(err) {
+		return m.DeferredDiscoveryRESTMapper.RESTMappings(gk, versions...)
+	}
+	return mappings, err
+}
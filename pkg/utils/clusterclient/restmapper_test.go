@@ -0,0 +1,46 @@
+package clusterclient
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRESTMapperReset(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}},
+	}}
+	mapper := NewRESTMapper(discovery).(*resettingRESTMapper)
+	crontabs := schema.GroupVersionResource{Resource: "crontabs"}
+
+	if _, err := mapper.ResourceFor(schema.GroupVersionResource{Resource: "pods"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.ResourceFor(crontabs); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected no match error, got %v", err)
+	}
+
+	// CRD created later is found once mapper is allowed to reset again
+	discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{
+		GroupVersion: "stable.example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "crontabs", Kind: "CronTab", Namespaced: true}},
+	})
+	if _, err := mapper.ResourceFor(crontabs); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected no match error within reset interval, got %v", err)
+	}
+	mapper.lastReset = time.Now().Add(-restMapperResetInterval)
+	gvr, err := mapper.ResourceFor(crontabs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gvr.Group != "stable.example.com" {
+		t.Errorf("unexpected resource %v", gvr)
+	}
+}